@@ -0,0 +1,202 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// A PolicyTarget is the part of an ApplicationConfiguration a policy rule is
+// evaluated against.
+type PolicyTarget string
+
+// Policy targets.
+const (
+	// PolicyTargetApplicationConfiguration evaluates a rule once against the
+	// ApplicationConfiguration itself.
+	PolicyTargetApplicationConfiguration PolicyTarget = "ApplicationConfiguration"
+
+	// PolicyTargetComponent evaluates a rule against each entry of
+	// spec.components of the ApplicationConfiguration.
+	PolicyTargetComponent PolicyTarget = "Component"
+
+	// PolicyTargetWorkload evaluates a rule against the workload of each
+	// component of the ApplicationConfiguration.
+	PolicyTargetWorkload PolicyTarget = "Workload"
+
+	// PolicyTargetTrait evaluates a rule against each trait of the
+	// ApplicationConfiguration.
+	PolicyTargetTrait PolicyTarget = "Trait"
+)
+
+// A PolicyOperator compares the values found at a field path.
+type PolicyOperator string
+
+// Policy operators.
+const (
+	// PolicyOperatorExists holds if the field path has a value.
+	PolicyOperatorExists PolicyOperator = "Exists"
+
+	// PolicyOperatorDoesNotExist holds if the field path has no value.
+	PolicyOperatorDoesNotExist PolicyOperator = "DoesNotExist"
+
+	// PolicyOperatorIn holds if any value of the field path is one of the
+	// supplied values.
+	PolicyOperatorIn PolicyOperator = "In"
+
+	// PolicyOperatorNotIn holds if no value of the field path is one of the
+	// supplied values.
+	PolicyOperatorNotIn PolicyOperator = "NotIn"
+
+	// PolicyOperatorGreaterThan holds if any value of the field path is a
+	// number greater than the supplied value.
+	PolicyOperatorGreaterThan PolicyOperator = "GreaterThan"
+
+	// PolicyOperatorLessThan holds if any value of the field path is a number
+	// less than the supplied value.
+	PolicyOperatorLessThan PolicyOperator = "LessThan"
+)
+
+// A PolicyCondition tests the values found at a field path of a policy
+// target.
+type PolicyCondition struct {
+	// FieldPath of the values to test, for example 'spec.replicaCount'. A
+	// '[*]' index selects every element of an array, for example
+	// 'scopes[*].scopeRef.kind'.
+	FieldPath string `json:"fieldPath"`
+
+	// Operator used to test the values.
+	// +kubebuilder:validation:Enum=Exists;DoesNotExist;In;NotIn;GreaterThan;LessThan
+	Operator PolicyOperator `json:"operator"`
+
+	// Values the operator compares to. In and NotIn accept any number of
+	// values, GreaterThan and LessThan exactly one.
+	// +optional
+	Values []string `json:"values,omitempty"`
+}
+
+// An AppPolicyRule denies ApplicationConfigurations whose targets match all of
+// its match conditions and all of its deny conditions.
+type AppPolicyRule struct {
+	// Name of the rule, reported when it denies an ApplicationConfiguration.
+	Name string `json:"name"`
+
+	// Target the rule is evaluated against.
+	// +kubebuilder:validation:Enum=ApplicationConfiguration;Component;Workload;Trait
+	Target PolicyTarget `json:"target"`
+
+	// Match selects the targets the rule applies to. A rule without match
+	// conditions applies to every target.
+	// +optional
+	Match []PolicyCondition `json:"match,omitempty"`
+
+	// Deny conditions that together violate the rule.
+	// +kubebuilder:validation:MinItems=1
+	Deny []PolicyCondition `json:"deny"`
+
+	// Message explaining the rule to the author of a denied
+	// ApplicationConfiguration.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// An AppPolicySpec defines the rules of an AppPolicy or ClusterAppPolicy.
+type AppPolicySpec struct {
+	// Rules ApplicationConfigurations must not violate.
+	Rules []AppPolicyRule `json:"rules"`
+}
+
+// +kubebuilder:object:root=true
+
+// An AppPolicy declares rules that the ApplicationConfigurations of its
+// namespace must not violate. They are enforced by the admission webhook.
+// +kubebuilder:resource:categories={crossplane,oam}
+type AppPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AppPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AppPolicyList contains a list of AppPolicy.
+type AppPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AppPolicy `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ClusterAppPolicy declares rules that the ApplicationConfigurations of
+// every namespace must not violate. They are enforced by the admission
+// webhook.
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,oam}
+type ClusterAppPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AppPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterAppPolicyList contains a list of ClusterAppPolicy.
+type ClusterAppPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterAppPolicy `json:"items"`
+}
+
+// A TraitPolicySpec defines the traits a TraitPolicy attaches to components.
+type TraitPolicySpec struct {
+	// WorkloadTypes selects the components the policy applies to by the
+	// workload.oam.dev/type label of their workload. A policy without
+	// workload types applies to every component.
+	// +optional
+	WorkloadTypes []string `json:"workloadTypes,omitempty"`
+
+	// Traits attached to the selected components, unless they already have a
+	// trait of the same kind. Like the traits of an ApplicationConfiguration,
+	// a trait may refer to its TraitDefinition by name. Traits that are
+	// invalid, e.g. that refer to a TraitDefinition that doesn't exist, are
+	// skipped.
+	Traits []ComponentTrait `json:"traits"`
+}
+
+// +kubebuilder:object:root=true
+
+// A TraitPolicy attaches default traits to the components of the
+// ApplicationConfigurations of its namespace. Traits are attached by the
+// admission webhook and labeled with the name of the policy.
+// +kubebuilder:resource:categories={crossplane,oam}
+type TraitPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TraitPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TraitPolicyList contains a list of TraitPolicy.
+type TraitPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TraitPolicy `json:"items"`
+}
@@ -18,6 +18,7 @@ package v1alpha2
 
 import (
 	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
@@ -35,6 +36,52 @@ const (
 	StatusUnknown = "UNKNOWN"
 )
 
+// TypeHealthy indicates whether the workloads are healthy according to the
+// HealthScopes they are in.
+const TypeHealthy runtimev1alpha1.ConditionType = "Healthy"
+
+// Reasons of a TypeHealthy condition.
+const (
+	ReasonHealthy       runtimev1alpha1.ConditionReason = "HealthScopesHealthy"
+	ReasonUnhealthy     runtimev1alpha1.ConditionReason = "HealthScopesUnhealthy"
+	ReasonHealthUnknown runtimev1alpha1.ConditionReason = "HealthScopesUnknown"
+)
+
+// Healthy returns a condition indicating that all HealthScopes consider the
+// workloads healthy.
+func Healthy() runtimev1alpha1.Condition {
+	return runtimev1alpha1.Condition{
+		Type:               TypeHealthy,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonHealthy,
+	}
+}
+
+// Unhealthy returns a condition indicating that a HealthScope considers some
+// workloads unhealthy.
+func Unhealthy(msg string) runtimev1alpha1.Condition {
+	return runtimev1alpha1.Condition{
+		Type:               TypeHealthy,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonUnhealthy,
+		Message:            msg,
+	}
+}
+
+// HealthUnknown returns a condition indicating that the health of some
+// workloads is not known by their HealthScopes.
+func HealthUnknown(msg string) runtimev1alpha1.Condition {
+	return runtimev1alpha1.Condition{
+		Type:               TypeHealthy,
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonHealthUnknown,
+		Message:            msg,
+	}
+}
+
 var _ oam.Scope = &HealthScope{}
 
 // A HealthScopeSpec defines the desired state of a HealthScope.
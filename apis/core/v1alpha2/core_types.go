@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha2
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -46,6 +47,27 @@ type ChildResourceKind struct {
 	Selector map[string]string `json:"selector,omitempty"`
 }
 
+// A DeletionPolicy determines what happens to a workload or trait once it is
+// no longer part of its ApplicationConfiguration, because it was removed from
+// the spec or because the ApplicationConfiguration was deleted.
+type DeletionPolicy string
+
+// Deletion policies.
+const (
+	// DeletionPolicyDelete deletes the resource. This is the default.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+
+	// DeletionPolicyOrphan removes the owner reference to the
+	// ApplicationConfiguration from the resource and leaves it in place, for
+	// example to be adopted by another ApplicationConfiguration.
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+
+	// DeletionPolicyRetain keeps the resource as long as it has the
+	// app.oam.dev/retain annotation, and deletes it once the annotation is
+	// removed. The annotation is added when the resource is applied.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
 // A WorkloadDefinitionSpec defines the desired state of a WorkloadDefinition.
 type WorkloadDefinitionSpec struct {
 	// Reference to the CustomResourceDefinition that defines this workload kind.
@@ -64,6 +86,17 @@ type WorkloadDefinitionSpec struct {
 	// +optional
 	PodSpecPath string `json:"podSpecPath,omitempty"`
 
+	// StatusProjection declares the fields of the workload that summarize its
+	// state in the status of an ApplicationConfiguration.
+	// +optional
+	StatusProjection *StatusProjection `json:"statusProjection,omitempty"`
+
+	// DeletionPolicy of workloads of this kind, unless their component
+	// specifies one.
+	// +optional
+	// +kubebuilder:validation:Enum=Delete;Orphan;Retain
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
 	// Extension is used for extension needs by OAM platform builders
 	// +optional
 	// +kubebuilder:pruning:PreserveUnknownFields
@@ -94,6 +127,29 @@ type WorkloadDefinitionList struct {
 	Items           []WorkloadDefinition `json:"items"`
 }
 
+// +kubebuilder:object:root=true
+
+// A NamespacedWorkloadDefinition registers a kind of Kubernetes custom
+// resource as a valid OAM workload kind for the ApplicationConfigurations of its
+// namespace. It shadows the WorkloadDefinition of the same name.
+// +kubebuilder:printcolumn:JSONPath=".spec.definitionRef.name",name=DEFINITION-NAME,type=string
+// +kubebuilder:resource:categories={crossplane,oam}
+type NamespacedWorkloadDefinition struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec WorkloadDefinitionSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NamespacedWorkloadDefinitionList contains a list of NamespacedWorkloadDefinition.
+type NamespacedWorkloadDefinitionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespacedWorkloadDefinition `json:"items"`
+}
+
 // A TraitDefinitionSpec defines the desired state of a TraitDefinition.
 type TraitDefinitionSpec struct {
 	// Reference to the CustomResourceDefinition that defines this trait kind.
@@ -114,12 +170,93 @@ type TraitDefinitionSpec struct {
 	// +optional
 	AppliesToWorkloads []string `json:"appliesToWorkloads,omitempty"`
 
+	// AppliesToWorkloadsWith specifies the characteristics of the workloads
+	// this trait applies to. A trait that specifies both AppliesToWorkloads
+	// and AppliesToWorkloadsWith applies to the workloads matching either.
+	// +optional
+	AppliesToWorkloadsWith *WorkloadCharacteristics `json:"appliesToWorkloadsWith,omitempty"`
+
+	// ConflictsWith specifies the traits this trait cannot be applied to the
+	// same workload with.
+	// +optional
+	ConflictsWith []TraitConflict `json:"conflictsWith,omitempty"`
+
+	// StatusProjection declares the fields of the trait that summarize its
+	// state in the status of an ApplicationConfiguration.
+	// +optional
+	StatusProjection *StatusProjection `json:"statusProjection,omitempty"`
+
+	// DeletionPolicy of traits of this kind, unless a trait specifies one.
+	// +optional
+	// +kubebuilder:validation:Enum=Delete;Orphan;Retain
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
 	// Extension is used for extension needs by OAM platform builders
 	// +optional
 	// +kubebuilder:pruning:PreserveUnknownFields
 	Extension *runtime.RawExtension `json:"extension,omitempty"`
 }
 
+// WorkloadCharacteristics select workloads by what their WorkloadDefinition
+// declares. A workload has the characteristics if it has all of them.
+type WorkloadCharacteristics struct {
+	// PodSpecable workloads have a podSpecPath or the
+	// workload.oam.dev/podspecable: "true" label on their WorkloadDefinition.
+	// +optional
+	PodSpecable bool `json:"podSpecable,omitempty"`
+
+	// ChildResourceKinds the workload must generate child resources of, e.g.
+	// Deployment or Deployment.apps.
+	// +optional
+	ChildResourceKinds []string `json:"childResourceKinds,omitempty"`
+
+	// Selector of the labels of the WorkloadDefinition.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// A StatusProjection declares which fields of a workload or trait are copied
+// into the status of the ApplicationConfiguration that created it.
+type StatusProjection struct {
+	// StatusPath is the field path of a value summarizing the state of the
+	// resource, e.g. status.phase.
+	// +optional
+	StatusPath string `json:"statusPath,omitempty"`
+
+	// ReadyConditionType is the type of the condition in status.conditions
+	// that tells whether the resource is ready, e.g. Ready or Available. The
+	// state is Ready when the condition is True and NotReady otherwise. It's
+	// used when StatusPath is unset or has no value.
+	// +optional
+	ReadyConditionType string `json:"readyConditionType,omitempty"`
+
+	// MessagePath is the field path of a human readable message about the
+	// state of the resource. The message of the ready condition is used when
+	// it's unset.
+	// +optional
+	MessagePath string `json:"messagePath,omitempty"`
+
+	// FieldPaths of additional values to copy by name, e.g. an endpoint URL at
+	// status.url.
+	// +optional
+	FieldPaths map[string]string `json:"fieldPaths,omitempty"`
+}
+
+// A TraitConflict declares traits that cannot be applied to the same workload
+// as the trait that declares it, either by their type or by the field of the
+// workload they manage.
+type TraitConflict struct {
+	// TraitType is the name of the TraitDefinition of a conflicting trait.
+	// +optional
+	TraitType string `json:"traitType,omitempty"`
+
+	// WorkloadFieldPath of a workload field the trait manages, e.g.
+	// spec.replicas. The trait conflicts with every other trait that declares
+	// a conflict on the same field path.
+	// +optional
+	WorkloadFieldPath string `json:"workloadFieldPath,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 
 // A TraitDefinition registers a kind of Kubernetes custom resource as a valid
@@ -144,6 +281,29 @@ type TraitDefinitionList struct {
 	Items           []TraitDefinition `json:"items"`
 }
 
+// +kubebuilder:object:root=true
+
+// A NamespacedTraitDefinition registers a kind of Kubernetes custom
+// resource as a valid OAM trait kind for the ApplicationConfigurations of its
+// namespace. It shadows the TraitDefinition of the same name.
+// +kubebuilder:printcolumn:JSONPath=".spec.definitionRef.name",name=DEFINITION-NAME,type=string
+// +kubebuilder:resource:categories={crossplane,oam}
+type NamespacedTraitDefinition struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TraitDefinitionSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NamespacedTraitDefinitionList contains a list of NamespacedTraitDefinition.
+type NamespacedTraitDefinitionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespacedTraitDefinition `json:"items"`
+}
+
 // A ScopeDefinitionSpec defines the desired state of a ScopeDefinition.
 type ScopeDefinitionSpec struct {
 	// Reference to the CustomResourceDefinition that defines this scope kind.
@@ -186,6 +346,29 @@ type ScopeDefinitionList struct {
 	Items           []ScopeDefinition `json:"items"`
 }
 
+// +kubebuilder:object:root=true
+
+// A NamespacedScopeDefinition registers a kind of Kubernetes custom
+// resource as a valid OAM scope kind for the ApplicationConfigurations of its
+// namespace. It shadows the ScopeDefinition of the same name.
+// +kubebuilder:printcolumn:JSONPath=".spec.definitionRef.name",name=DEFINITION-NAME,type=string
+// +kubebuilder:resource:categories={crossplane,oam}
+type NamespacedScopeDefinition struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ScopeDefinitionSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NamespacedScopeDefinitionList contains a list of NamespacedScopeDefinition.
+type NamespacedScopeDefinitionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespacedScopeDefinition `json:"items"`
+}
+
 // A ComponentParameter defines a configurable parameter of a component.
 type ComponentParameter struct {
 	// Name of this parameter. OAM ApplicationConfigurations will specify
@@ -225,6 +408,29 @@ type ComponentSpec struct {
 	// will in turn be injected into the embedded workload.
 	// +optional
 	Parameters []ComponentParameter `json:"parameters,omitempty"`
+
+	// RevisionRetention specifies which revisions of this component are
+	// kept. Changing it does not result in a new revision.
+	// +optional
+	RevisionRetention *RevisionRetentionPolicy `json:"revisionRetention,omitempty"`
+}
+
+// A RevisionRetentionPolicy specifies which revisions of a component are kept.
+// The latest revision, and revisions that are used by an
+// ApplicationConfiguration or back a running workload, are always kept.
+type RevisionRetentionPolicy struct {
+	// MaxCount of revisions to keep, not counting the ones used by an
+	// ApplicationConfiguration. Defaults to the revision limit of the
+	// controller.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxCount *int32 `json:"maxCount,omitempty"`
+
+	// MaxAge of the revisions to keep, e.g. 720h. The age of a revision the
+	// component was reverted to counts from when it was last reused.
+	// Revisions are kept regardless of their age by default.
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
 }
 
 // A ComponentStatus represents the observed state of a Component.
@@ -246,6 +452,20 @@ type ComponentStatus struct {
 type Revision struct {
 	Name     string `json:"name"`
 	Revision int64  `json:"revision"`
+
+	// ChangeCause of the revision, from the kubernetes.io/change-cause
+	// annotation of the Component.
+	// +optional
+	ChangeCause string `json:"changeCause,omitempty"`
+
+	// Author is the user whose change of the Component resulted in the
+	// revision, as recorded by the Component mutating webhook.
+	// +optional
+	Author string `json:"author,omitempty"`
+
+	// Hash of the content of the revision.
+	// +optional
+	Hash string `json:"hash,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -296,6 +516,12 @@ type ComponentTrait struct {
 	// DataInputs specify the data input sinks into this trait.
 	// +optional
 	DataInputs []DataInput `json:"dataInputs,omitempty"`
+
+	// DeletionPolicy of the trait. Defaults to the deletion policy of its
+	// TraitDefinition, or Delete.
+	// +optional
+	// +kubebuilder:validation:Enum=Delete;Orphan;Retain
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
 }
 
 // A ComponentScope specifies a scope in which a component should exist.
@@ -338,6 +564,40 @@ type ApplicationConfigurationComponent struct {
 	// Scopes in which the specified component should exist.
 	// +optional
 	Scopes []ComponentScope `json:"scopes,omitempty"`
+
+	// DeletionPolicy of the workload of the component. Defaults to the
+	// deletion policy of its WorkloadDefinition, or Delete.
+	// +optional
+	// +kubebuilder:validation:Enum=Delete;Orphan;Retain
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// HistoryWorkloadRetention specifies which workloads of earlier
+	// revisions of the component are kept when it has revision enabled
+	// traits. They are all kept by default, until deleted by someone else.
+	// +optional
+	HistoryWorkloadRetention *HistoryWorkloadRetentionPolicy `json:"historyWorkloadRetention,omitempty"`
+}
+
+// A HistoryWorkloadRetentionPolicy specifies which workloads of earlier
+// revisions of a component are kept. A workload of an earlier revision is
+// deleted as soon as any of the conditions are met.
+type HistoryWorkloadRetentionPolicy struct {
+	// MaxCount of workloads of earlier revisions to keep, the most recent
+	// ones are kept.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxCount *int32 `json:"maxCount,omitempty"`
+
+	// RemoveAfter is how long after the workload of the latest revision was
+	// created the workloads of earlier revisions are deleted, e.g. 30m.
+	// +optional
+	RemoveAfter *metav1.Duration `json:"removeAfter,omitempty"`
+
+	// RemoveWhenHealthy deletes the workloads of earlier revisions once the
+	// workload of the latest revision is healthy or ready, as reported by a
+	// HealthScope or the StatusProjection of its WorkloadDefinition.
+	// +optional
+	RemoveWhenHealthy bool `json:"removeWhenHealthy,omitempty"`
 }
 
 // An ApplicationConfigurationSpec defines the desired state of a
@@ -353,15 +613,24 @@ type TraitStatus string
 
 // A WorkloadTrait represents a trait associated with a workload and its status
 type WorkloadTrait struct {
-	// Status is a place holder for a customized controller to fill
-	// if it needs a single place to summarize the status of the trait
+	// Status summarizes the status of the trait, as declared by the
+	// StatusProjection of its TraitDefinition or, without one, from the
+	// conditions of the trait.
 	Status TraitStatus `json:"status,omitempty"`
 
 	// Reference to a trait created by an ApplicationConfiguration.
 	Reference runtimev1alpha1.TypedReference `json:"traitRef"`
 
-	// Message will allow controller to leave some additional information for this trait
+	// Message will allow controller to leave some additional information for this trait,
+	// such as the conditions of the trait that are not true.
 	Message string `json:"message,omitempty"`
+
+	// Fields projected from the trait, as declared by the StatusProjection
+	// of its TraitDefinition.
+	Fields map[string]string `json:"fields,omitempty"`
+
+	// DeletionPolicy the trait was applied with.
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
 }
 
 // A ScopeStatus represents the state of a scope.
@@ -370,19 +639,32 @@ type ScopeStatus string
 // A WorkloadScope represents a scope associated with a workload and its status
 type WorkloadScope struct {
 	// Status is a place holder for a customized controller to fill
-	// if it needs a single place to summarize the status of the scope
+	// if it needs a single place to summarize the status of the scope. The
+	// health status of the workload is recorded for HealthScopes.
 	Status ScopeStatus `json:"status,omitempty"`
 
 	// Reference to a scope created by an ApplicationConfiguration.
 	Reference runtimev1alpha1.TypedReference `json:"scopeRef"`
+
+	// Diagnosis of the health of the workload, recorded for HealthScopes.
+	Diagnosis string `json:"diagnosis,omitempty"`
 }
 
 // A WorkloadStatus represents the status of a workload.
 type WorkloadStatus struct {
+	runtimev1alpha1.ConditionedStatus `json:",inline"`
+
 	// Status is a place holder for a customized controller to fill
 	// if it needs a single place to summarize the entire status of the workload
 	Status string `json:"status,omitempty"`
 
+	// Message about the state of the workload.
+	Message string `json:"message,omitempty"`
+
+	// Fields projected from the workload, as declared by the StatusProjection
+	// of its WorkloadDefinition.
+	Fields map[string]string `json:"fields,omitempty"`
+
 	// ComponentName that produced this workload.
 	ComponentName string `json:"componentName,omitempty"`
 
@@ -397,6 +679,49 @@ type WorkloadStatus struct {
 
 	// Scopes associated with this workload.
 	Scopes []WorkloadScope `json:"scopes,omitempty"`
+
+	// DeletionPolicy the workload was applied with.
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// TypeTraitsConflict indicates that traits of a workload conflict with each
+// other. Conflicting traits are not applied.
+const TypeTraitsConflict runtimev1alpha1.ConditionType = "TraitsConflict"
+
+// ReasonTraitsConflict is the reason of a TypeTraitsConflict condition.
+const ReasonTraitsConflict runtimev1alpha1.ConditionReason = "ConflictingTraits"
+
+// TraitsConflict returns a condition indicating that traits of a workload
+// conflict with each other, and were therefore not applied.
+func TraitsConflict(msg string) runtimev1alpha1.Condition {
+	return runtimev1alpha1.Condition{
+		Type:               TypeTraitsConflict,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonTraitsConflict,
+		Message:            msg,
+	}
+}
+
+// TypeScopeOverlap indicates that a workload did not join some of its scopes
+// because it already belongs to another scope of the same kind, and the
+// ScopeDefinition of that kind does not allow component overlap.
+const TypeScopeOverlap runtimev1alpha1.ConditionType = "ScopeOverlap"
+
+// ReasonScopeOverlap is the reason of a TypeScopeOverlap condition.
+const ReasonScopeOverlap runtimev1alpha1.ConditionReason = "OverlappingScopes"
+
+// ScopeOverlap returns a condition indicating that a workload did not join
+// some of its scopes because it would overlap with other scopes of the same
+// kind.
+func ScopeOverlap(msg string) runtimev1alpha1.Condition {
+	return runtimev1alpha1.Condition{
+		Type:               TypeScopeOverlap,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonScopeOverlap,
+		Message:            msg,
+	}
 }
 
 // HistoryWorkload contain the old component revision that are still running
@@ -411,13 +736,48 @@ type HistoryWorkload struct {
 // A ApplicationStatus represents the state of the entire application.
 type ApplicationStatus string
 
+// Phases of an ApplicationConfiguration.
+const (
+	// ApplicationRendering means the components can't be rendered yet, e.g.
+	// because a Component or its revision doesn't exist yet.
+	ApplicationRendering ApplicationStatus = "Rendering"
+
+	// ApplicationWaitingForDependencies means some workloads or traits are not
+	// applied yet because the data they depend on is not ready.
+	ApplicationWaitingForDependencies ApplicationStatus = "WaitingForDependencies"
+
+	// ApplicationApplying means the workloads and traits were applied, but
+	// the readiness of some workloads could not be observed yet.
+	ApplicationApplying ApplicationStatus = "Applying"
+
+	// ApplicationProgressing means some workloads are not ready yet after the
+	// ApplicationConfiguration was created or changed.
+	ApplicationProgressing ApplicationStatus = "Progressing"
+
+	// ApplicationRunning means all workloads are ready.
+	ApplicationRunning ApplicationStatus = "Running"
+
+	// ApplicationDegraded means some workloads became unready after the
+	// ApplicationConfiguration was running, or some traits are not applied
+	// because they conflict.
+	ApplicationDegraded ApplicationStatus = "Degraded"
+
+	// ApplicationFailed means the components could not be rendered, applied
+	// or garbage collected.
+	ApplicationFailed ApplicationStatus = "Failed"
+
+	// ApplicationDeleting means the ApplicationConfiguration is being
+	// deleted.
+	ApplicationDeleting ApplicationStatus = "Deleting"
+)
+
 // An ApplicationConfigurationStatus represents the observed state of a
 // ApplicationConfiguration.
 type ApplicationConfigurationStatus struct {
 	runtimev1alpha1.ConditionedStatus `json:",inline"`
 
-	// Status is a place holder for a customized controller to fill
-	// if it needs a single place to summarize the status of the entire application
+	// Status is the phase of the application. It summarizes the status of
+	// the entire application.
 	Status ApplicationStatus `json:"status,omitempty"`
 
 	Dependency DependencyStatus `json:"dependency,omitempty"`
@@ -431,6 +791,63 @@ type ApplicationConfigurationStatus struct {
 
 	// HistoryWorkloads will record history but still working revision workloads.
 	HistoryWorkloads []HistoryWorkload `json:"historyWorkloads,omitempty"`
+
+	// RetainedResources were removed from the spec but are kept until their
+	// app.oam.dev/retain annotation is removed, per their Retain deletion
+	// policy.
+	RetainedResources []runtimev1alpha1.TypedReference `json:"retainedResources,omitempty"`
+
+	// Teardown reports the progress of deleting the workloads and traits of
+	// an ApplicationConfiguration that is being deleted.
+	Teardown *TeardownStatus `json:"teardown,omitempty"`
+
+	// AdoptedResources existed before they were applied, and were adopted
+	// because the ApplicationConfiguration or their Component has the
+	// app.oam.dev/adopt annotation.
+	AdoptedResources []AdoptedResource `json:"adoptedResources,omitempty"`
+
+	// LatestRevision is the latest revision of the history of the
+	// ApplicationConfiguration, recorded once it was successfully applied.
+	LatestRevision *ApplicationConfigurationRevision `json:"latestRevision,omitempty"`
+}
+
+// An ApplicationConfigurationRevision is a revision of the history of an
+// ApplicationConfiguration.
+type ApplicationConfigurationRevision struct {
+	// Name of the ControllerRevision of the revision.
+	Name string `json:"name"`
+
+	// Revision number, which increases with each recorded revision.
+	Revision int64 `json:"revision"`
+}
+
+// An AdoptedResource is a workload or trait that existed before it was
+// applied by an ApplicationConfiguration.
+type AdoptedResource struct {
+	// Reference to the adopted resource.
+	Reference runtimev1alpha1.TypedReference `json:"ref"`
+
+	// ComponentName the resource was adopted for.
+	ComponentName string `json:"componentName"`
+
+	// AdoptedAt is when the resource was adopted.
+	AdoptedAt metav1.Time `json:"adoptedAt"`
+}
+
+// A TeardownStatus reports the progress of deleting the workloads and traits
+// of an ApplicationConfiguration. Traits are deleted before workloads, and
+// the resources of components that others depend on after the resources of
+// the components that depend on them. Each step waits until the resources of
+// the previous step are gone.
+type TeardownStatus struct {
+	// Step that is being deleted, starting at 1.
+	Step int `json:"step"`
+
+	// Steps of the teardown.
+	Steps int `json:"steps"`
+
+	// Pending resources of the step that are not deleted yet.
+	Pending []runtimev1alpha1.TypedReference `json:"pending,omitempty"`
 }
 
 // DependencyStatus represents the observed state of the dependency of
@@ -462,6 +879,9 @@ type DependencyToObject struct {
 // +kubebuilder:object:root=true
 
 // An ApplicationConfiguration represents an OAM application.
+// +kubebuilder:printcolumn:JSONPath=".status.status",name=STATUS,type=string
+// +kubebuilder:printcolumn:JSONPath=".status.conditions[?(@.type=='Ready')].status",name=READY,type=string
+// +kubebuilder:printcolumn:JSONPath=".metadata.creationTimestamp",name=AGE,type=date
 // +kubebuilder:resource:shortName=appconfig,categories={crossplane,oam}
 // +kubebuilder:subresource:status
 type ApplicationConfiguration struct {
@@ -91,3 +91,33 @@ func (hs *HealthScope) GetWorkloadReferences() []runtimev1alpha1.TypedReference
 func (hs *HealthScope) AddWorkloadReference(r runtimev1alpha1.TypedReference) {
 	hs.Spec.WorkloadReferences = append(hs.Spec.WorkloadReferences, r)
 }
+
+// GetDefinitionReference of this WorkloadDefinition.
+func (wd *WorkloadDefinition) GetDefinitionReference() DefinitionReference {
+	return wd.Spec.Reference
+}
+
+// GetDefinitionReference of this NamespacedWorkloadDefinition.
+func (wd *NamespacedWorkloadDefinition) GetDefinitionReference() DefinitionReference {
+	return wd.Spec.Reference
+}
+
+// GetDefinitionReference of this TraitDefinition.
+func (td *TraitDefinition) GetDefinitionReference() DefinitionReference {
+	return td.Spec.Reference
+}
+
+// GetDefinitionReference of this NamespacedTraitDefinition.
+func (td *NamespacedTraitDefinition) GetDefinitionReference() DefinitionReference {
+	return td.Spec.Reference
+}
+
+// GetDefinitionReference of this ScopeDefinition.
+func (sd *ScopeDefinition) GetDefinitionReference() DefinitionReference {
+	return sd.Spec.Reference
+}
+
+// GetDefinitionReference of this NamespacedScopeDefinition.
+func (sd *NamespacedScopeDefinition) GetDefinitionReference() DefinitionReference {
+	return sd.Spec.Reference
+}
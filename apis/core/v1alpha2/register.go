@@ -45,6 +45,14 @@ var (
 	WorkloadDefinitionGroupVersionKind = SchemeGroupVersion.WithKind(WorkloadDefinitionKind)
 )
 
+// NamespacedWorkloadDefinition type metadata.
+var (
+	NamespacedWorkloadDefinitionKind             = reflect.TypeOf(NamespacedWorkloadDefinition{}).Name()
+	NamespacedWorkloadDefinitionGroupKind        = schema.GroupKind{Group: Group, Kind: NamespacedWorkloadDefinitionKind}.String()
+	NamespacedWorkloadDefinitionKindAPIVersion   = NamespacedWorkloadDefinitionKind + "." + SchemeGroupVersion.String()
+	NamespacedWorkloadDefinitionGroupVersionKind = SchemeGroupVersion.WithKind(NamespacedWorkloadDefinitionKind)
+)
+
 // TraitDefinition type metadata.
 var (
 	TraitDefinitionKind             = reflect.TypeOf(TraitDefinition{}).Name()
@@ -53,6 +61,14 @@ var (
 	TraitDefinitionGroupVersionKind = SchemeGroupVersion.WithKind(TraitDefinitionKind)
 )
 
+// NamespacedTraitDefinition type metadata.
+var (
+	NamespacedTraitDefinitionKind             = reflect.TypeOf(NamespacedTraitDefinition{}).Name()
+	NamespacedTraitDefinitionGroupKind        = schema.GroupKind{Group: Group, Kind: NamespacedTraitDefinitionKind}.String()
+	NamespacedTraitDefinitionKindAPIVersion   = NamespacedTraitDefinitionKind + "." + SchemeGroupVersion.String()
+	NamespacedTraitDefinitionGroupVersionKind = SchemeGroupVersion.WithKind(NamespacedTraitDefinitionKind)
+)
+
 // ScopeDefinition type metadata.
 var (
 	ScopeDefinitionKind             = reflect.TypeOf(ScopeDefinition{}).Name()
@@ -61,6 +77,14 @@ var (
 	ScopeDefinitionGroupVersionKind = SchemeGroupVersion.WithKind(ScopeDefinitionKind)
 )
 
+// NamespacedScopeDefinition type metadata.
+var (
+	NamespacedScopeDefinitionKind             = reflect.TypeOf(NamespacedScopeDefinition{}).Name()
+	NamespacedScopeDefinitionGroupKind        = schema.GroupKind{Group: Group, Kind: NamespacedScopeDefinitionKind}.String()
+	NamespacedScopeDefinitionKindAPIVersion   = NamespacedScopeDefinitionKind + "." + SchemeGroupVersion.String()
+	NamespacedScopeDefinitionGroupVersionKind = SchemeGroupVersion.WithKind(NamespacedScopeDefinitionKind)
+)
+
 // Component type metadata.
 var (
 	ComponentKind             = reflect.TypeOf(Component{}).Name()
@@ -101,13 +125,43 @@ var (
 	HealthScopeGroupVersionKind = SchemeGroupVersion.WithKind(HealthScopeKind)
 )
 
+// AppPolicy type metadata.
+var (
+	AppPolicyKind             = reflect.TypeOf(AppPolicy{}).Name()
+	AppPolicyGroupKind        = schema.GroupKind{Group: Group, Kind: AppPolicyKind}.String()
+	AppPolicyKindAPIVersion   = AppPolicyKind + "." + SchemeGroupVersion.String()
+	AppPolicyGroupVersionKind = SchemeGroupVersion.WithKind(AppPolicyKind)
+)
+
+// ClusterAppPolicy type metadata.
+var (
+	ClusterAppPolicyKind             = reflect.TypeOf(ClusterAppPolicy{}).Name()
+	ClusterAppPolicyGroupKind        = schema.GroupKind{Group: Group, Kind: ClusterAppPolicyKind}.String()
+	ClusterAppPolicyKindAPIVersion   = ClusterAppPolicyKind + "." + SchemeGroupVersion.String()
+	ClusterAppPolicyGroupVersionKind = SchemeGroupVersion.WithKind(ClusterAppPolicyKind)
+)
+
+// TraitPolicy type metadata.
+var (
+	TraitPolicyKind             = reflect.TypeOf(TraitPolicy{}).Name()
+	TraitPolicyGroupKind        = schema.GroupKind{Group: Group, Kind: TraitPolicyKind}.String()
+	TraitPolicyKindAPIVersion   = TraitPolicyKind + "." + SchemeGroupVersion.String()
+	TraitPolicyGroupVersionKind = SchemeGroupVersion.WithKind(TraitPolicyKind)
+)
+
 func init() {
 	SchemeBuilder.Register(&WorkloadDefinition{}, &WorkloadDefinitionList{})
+	SchemeBuilder.Register(&NamespacedWorkloadDefinition{}, &NamespacedWorkloadDefinitionList{})
 	SchemeBuilder.Register(&TraitDefinition{}, &TraitDefinitionList{})
+	SchemeBuilder.Register(&NamespacedTraitDefinition{}, &NamespacedTraitDefinitionList{})
 	SchemeBuilder.Register(&ScopeDefinition{}, &ScopeDefinitionList{})
+	SchemeBuilder.Register(&NamespacedScopeDefinition{}, &NamespacedScopeDefinitionList{})
 	SchemeBuilder.Register(&Component{}, &ComponentList{})
 	SchemeBuilder.Register(&ApplicationConfiguration{}, &ApplicationConfigurationList{})
 	SchemeBuilder.Register(&ContainerizedWorkload{}, &ContainerizedWorkloadList{})
 	SchemeBuilder.Register(&ManualScalerTrait{}, &ManualScalerTraitList{})
 	SchemeBuilder.Register(&HealthScope{}, &HealthScopeList{})
+	SchemeBuilder.Register(&AppPolicy{}, &AppPolicyList{})
+	SchemeBuilder.Register(&ClusterAppPolicy{}, &ClusterAppPolicyList{})
+	SchemeBuilder.Register(&TraitPolicy{}, &TraitPolicyList{})
 }
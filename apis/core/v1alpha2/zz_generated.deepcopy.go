@@ -22,9 +22,136 @@ package v1alpha2
 
 import (
 	"github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdoptedResource) DeepCopyInto(out *AdoptedResource) {
+	*out = *in
+	out.Reference = in.Reference
+	in.AdoptedAt.DeepCopyInto(&out.AdoptedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdoptedResource.
+func (in *AdoptedResource) DeepCopy() *AdoptedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(AdoptedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppPolicy) DeepCopyInto(out *AppPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppPolicy.
+func (in *AppPolicy) DeepCopy() *AppPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AppPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AppPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppPolicyList) DeepCopyInto(out *AppPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AppPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppPolicyList.
+func (in *AppPolicyList) DeepCopy() *AppPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(AppPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AppPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppPolicyRule) DeepCopyInto(out *AppPolicyRule) {
+	*out = *in
+	if in.Match != nil {
+		in, out := &in.Match, &out.Match
+		*out = make([]PolicyCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Deny != nil {
+		in, out := &in.Deny, &out.Deny
+		*out = make([]PolicyCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppPolicyRule.
+func (in *AppPolicyRule) DeepCopy() *AppPolicyRule {
+	if in == nil {
+		return nil
+	}
+	out := new(AppPolicyRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppPolicySpec) DeepCopyInto(out *AppPolicySpec) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]AppPolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppPolicySpec.
+func (in *AppPolicySpec) DeepCopy() *AppPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AppPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationConfiguration) DeepCopyInto(out *ApplicationConfiguration) {
 	*out = *in
@@ -86,6 +213,11 @@ func (in *ApplicationConfigurationComponent) DeepCopyInto(out *ApplicationConfig
 		*out = make([]ComponentScope, len(*in))
 		copy(*out, *in)
 	}
+	if in.HistoryWorkloadRetention != nil {
+		in, out := &in.HistoryWorkloadRetention, &out.HistoryWorkloadRetention
+		*out = new(HistoryWorkloadRetentionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationConfigurationComponent.
@@ -130,6 +262,21 @@ func (in *ApplicationConfigurationList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationConfigurationRevision) DeepCopyInto(out *ApplicationConfigurationRevision) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationConfigurationRevision.
+func (in *ApplicationConfigurationRevision) DeepCopy() *ApplicationConfigurationRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationConfigurationRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationConfigurationSpec) DeepCopyInto(out *ApplicationConfigurationSpec) {
 	*out = *in
@@ -169,6 +316,28 @@ func (in *ApplicationConfigurationStatus) DeepCopyInto(out *ApplicationConfigura
 		*out = make([]HistoryWorkload, len(*in))
 		copy(*out, *in)
 	}
+	if in.RetainedResources != nil {
+		in, out := &in.RetainedResources, &out.RetainedResources
+		*out = make([]v1alpha1.TypedReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Teardown != nil {
+		in, out := &in.Teardown, &out.Teardown
+		*out = new(TeardownStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdoptedResources != nil {
+		in, out := &in.AdoptedResources, &out.AdoptedResources
+		*out = make([]AdoptedResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LatestRevision != nil {
+		in, out := &in.LatestRevision, &out.LatestRevision
+		*out = new(ApplicationConfigurationRevision)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationConfigurationStatus.
@@ -219,6 +388,64 @@ func (in *ChildResourceKind) DeepCopy() *ChildResourceKind {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterAppPolicy) DeepCopyInto(out *ClusterAppPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAppPolicy.
+func (in *ClusterAppPolicy) DeepCopy() *ClusterAppPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterAppPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterAppPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterAppPolicyList) DeepCopyInto(out *ClusterAppPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterAppPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAppPolicyList.
+func (in *ClusterAppPolicyList) DeepCopy() *ClusterAppPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterAppPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterAppPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Component) DeepCopyInto(out *Component) {
 	*out = *in
@@ -351,6 +578,11 @@ func (in *ComponentSpec) DeepCopyInto(out *ComponentSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RevisionRetention != nil {
+		in, out := &in.RevisionRetention, &out.RevisionRetention
+		*out = new(RevisionRetentionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentSpec.
@@ -1166,6 +1398,31 @@ func (in *HistoryWorkload) DeepCopy() *HistoryWorkload {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HistoryWorkloadRetentionPolicy) DeepCopyInto(out *HistoryWorkloadRetentionPolicy) {
+	*out = *in
+	if in.MaxCount != nil {
+		in, out := &in.MaxCount, &out.MaxCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RemoveAfter != nil {
+		in, out := &in.RemoveAfter, &out.RemoveAfter
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HistoryWorkloadRetentionPolicy.
+func (in *HistoryWorkloadRetentionPolicy) DeepCopy() *HistoryWorkloadRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HistoryWorkloadRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ManualScalerTrait) DeepCopyInto(out *ManualScalerTrait) {
 	*out = *in
@@ -1273,6 +1530,200 @@ func (in *MemoryResources) DeepCopy() *MemoryResources {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedScopeDefinition) DeepCopyInto(out *NamespacedScopeDefinition) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedScopeDefinition.
+func (in *NamespacedScopeDefinition) DeepCopy() *NamespacedScopeDefinition {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedScopeDefinition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespacedScopeDefinition) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedScopeDefinitionList) DeepCopyInto(out *NamespacedScopeDefinitionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NamespacedScopeDefinition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedScopeDefinitionList.
+func (in *NamespacedScopeDefinitionList) DeepCopy() *NamespacedScopeDefinitionList {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedScopeDefinitionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespacedScopeDefinitionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedTraitDefinition) DeepCopyInto(out *NamespacedTraitDefinition) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedTraitDefinition.
+func (in *NamespacedTraitDefinition) DeepCopy() *NamespacedTraitDefinition {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedTraitDefinition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespacedTraitDefinition) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedTraitDefinitionList) DeepCopyInto(out *NamespacedTraitDefinitionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NamespacedTraitDefinition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedTraitDefinitionList.
+func (in *NamespacedTraitDefinitionList) DeepCopy() *NamespacedTraitDefinitionList {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedTraitDefinitionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespacedTraitDefinitionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedWorkloadDefinition) DeepCopyInto(out *NamespacedWorkloadDefinition) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedWorkloadDefinition.
+func (in *NamespacedWorkloadDefinition) DeepCopy() *NamespacedWorkloadDefinition {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedWorkloadDefinition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespacedWorkloadDefinition) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedWorkloadDefinitionList) DeepCopyInto(out *NamespacedWorkloadDefinitionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NamespacedWorkloadDefinition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedWorkloadDefinitionList.
+func (in *NamespacedWorkloadDefinitionList) DeepCopy() *NamespacedWorkloadDefinitionList {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedWorkloadDefinitionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespacedWorkloadDefinitionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyCondition) DeepCopyInto(out *PolicyCondition) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyCondition.
+func (in *PolicyCondition) DeepCopy() *PolicyCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Revision) DeepCopyInto(out *Revision) {
 	*out = *in
@@ -1288,6 +1739,31 @@ func (in *Revision) DeepCopy() *Revision {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RevisionRetentionPolicy) DeepCopyInto(out *RevisionRetentionPolicy) {
+	*out = *in
+	if in.MaxCount != nil {
+		in, out := &in.MaxCount, &out.MaxCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RevisionRetentionPolicy.
+func (in *RevisionRetentionPolicy) DeepCopy() *RevisionRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RevisionRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScopeDefinition) DeepCopyInto(out *ScopeDefinition) {
 	*out = *in
@@ -1397,6 +1873,28 @@ func (in *SecretKeySelector) DeepCopy() *SecretKeySelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusProjection) DeepCopyInto(out *StatusProjection) {
+	*out = *in
+	if in.FieldPaths != nil {
+		in, out := &in.FieldPaths, &out.FieldPaths
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusProjection.
+func (in *StatusProjection) DeepCopy() *StatusProjection {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusProjection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StoreReference) DeepCopyInto(out *StoreReference) {
 	*out = *in
@@ -1435,6 +1933,41 @@ func (in *TCPSocketProbe) DeepCopy() *TCPSocketProbe {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TeardownStatus) DeepCopyInto(out *TeardownStatus) {
+	*out = *in
+	if in.Pending != nil {
+		in, out := &in.Pending, &out.Pending
+		*out = make([]v1alpha1.TypedReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TeardownStatus.
+func (in *TeardownStatus) DeepCopy() *TeardownStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TeardownStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TraitConflict) DeepCopyInto(out *TraitConflict) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TraitConflict.
+func (in *TraitConflict) DeepCopy() *TraitConflict {
+	if in == nil {
+		return nil
+	}
+	out := new(TraitConflict)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TraitDefinition) DeepCopyInto(out *TraitDefinition) {
 	*out = *in
@@ -1502,6 +2035,21 @@ func (in *TraitDefinitionSpec) DeepCopyInto(out *TraitDefinitionSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.AppliesToWorkloadsWith != nil {
+		in, out := &in.AppliesToWorkloadsWith, &out.AppliesToWorkloadsWith
+		*out = new(WorkloadCharacteristics)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConflictsWith != nil {
+		in, out := &in.ConflictsWith, &out.ConflictsWith
+		*out = make([]TraitConflict, len(*in))
+		copy(*out, *in)
+	}
+	if in.StatusProjection != nil {
+		in, out := &in.StatusProjection, &out.StatusProjection
+		*out = new(StatusProjection)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Extension != nil {
 		in, out := &in.Extension, &out.Extension
 		*out = new(runtime.RawExtension)
@@ -1519,6 +2067,91 @@ func (in *TraitDefinitionSpec) DeepCopy() *TraitDefinitionSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TraitPolicy) DeepCopyInto(out *TraitPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TraitPolicy.
+func (in *TraitPolicy) DeepCopy() *TraitPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(TraitPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TraitPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TraitPolicyList) DeepCopyInto(out *TraitPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TraitPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TraitPolicyList.
+func (in *TraitPolicyList) DeepCopy() *TraitPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(TraitPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TraitPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TraitPolicySpec) DeepCopyInto(out *TraitPolicySpec) {
+	*out = *in
+	if in.WorkloadTypes != nil {
+		in, out := &in.WorkloadTypes, &out.WorkloadTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Traits != nil {
+		in, out := &in.Traits, &out.Traits
+		*out = make([]ComponentTrait, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TraitPolicySpec.
+func (in *TraitPolicySpec) DeepCopy() *TraitPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TraitPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UnstaifiedDependency) DeepCopyInto(out *UnstaifiedDependency) {
 	*out = *in
@@ -1581,6 +2214,31 @@ func (in *VolumeResource) DeepCopy() *VolumeResource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadCharacteristics) DeepCopyInto(out *WorkloadCharacteristics) {
+	*out = *in
+	if in.ChildResourceKinds != nil {
+		in, out := &in.ChildResourceKinds, &out.ChildResourceKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadCharacteristics.
+func (in *WorkloadCharacteristics) DeepCopy() *WorkloadCharacteristics {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadCharacteristics)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadDefinition) DeepCopyInto(out *WorkloadDefinition) {
 	*out = *in
@@ -1650,6 +2308,11 @@ func (in *WorkloadDefinitionSpec) DeepCopyInto(out *WorkloadDefinitionSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.StatusProjection != nil {
+		in, out := &in.StatusProjection, &out.StatusProjection
+		*out = new(StatusProjection)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Extension != nil {
 		in, out := &in.Extension, &out.Extension
 		*out = new(runtime.RawExtension)
@@ -1702,11 +2365,21 @@ func (in *WorkloadScope) DeepCopy() *WorkloadScope {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadStatus) DeepCopyInto(out *WorkloadStatus) {
 	*out = *in
+	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	out.Reference = in.Reference
 	if in.Traits != nil {
 		in, out := &in.Traits, &out.Traits
 		*out = make([]WorkloadTrait, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Scopes != nil {
 		in, out := &in.Scopes, &out.Scopes
@@ -1729,6 +2402,13 @@ func (in *WorkloadStatus) DeepCopy() *WorkloadStatus {
 func (in *WorkloadTrait) DeepCopyInto(out *WorkloadTrait) {
 	*out = *in
 	out.Reference = in.Reference
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadTrait.
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"io"
 	"os"
 	"strconv"
@@ -14,11 +16,14 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/crossplane/oam-kubernetes-runtime/apis/core"
 	"github.com/crossplane/oam-kubernetes-runtime/pkg/controller"
 	appController "github.com/crossplane/oam-kubernetes-runtime/pkg/controller/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/webhook/audit"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/webhook/certificate"
 	webhook "github.com/crossplane/oam-kubernetes-runtime/pkg/webhook/v1alpha2"
 )
 
@@ -38,12 +43,33 @@ func main() {
 	var certDir string
 	var webhookPort int
 	var useWebhook bool
+	var autoCert bool
+	var certOpts certificate.Options
+	var auditSink, auditLogPath string
+	var auditConfigMapLimit int
 	var debugLogs bool
 	var controllerArgs controller.Args
 
 	flag.BoolVar(&useWebhook, "use-webhook", false, "Enable Admission Webhook")
 	flag.StringVar(&certDir, "webhook-cert-dir", "/k8s-webhook-server/serving-certs", "Admission webhook cert/key dir.")
 	flag.IntVar(&webhookPort, "webhook-port", 9443, "admission webhook listen address")
+	flag.BoolVar(&autoCert, "webhook-auto-cert", false,
+		"Generate, rotate and distribute the admission webhook certificates instead of reading them from webhook-cert-dir.")
+	flag.StringVar(&certOpts.SecretName, "webhook-cert-secret-name", "webhook-server-cert",
+		"The secret generated webhook certificates are stored in.")
+	flag.StringVar(&certOpts.SecretNamespace, "webhook-cert-secret-namespace", "oam-system",
+		"The namespace of the webhook certificate secret and webhook service.")
+	flag.StringVar(&certOpts.ServiceName, "webhook-service-name", "oam-kubernetes-runtime-webhook",
+		"The service in front of the admission webhook server, used as the certificate DNS name.")
+	flag.StringVar(&certOpts.WebhookConfigurationName, "webhook-configuration-name", "oam-kubernetes-runtime",
+		"The mutating and validating webhook configurations whose caBundle will be patched.")
+	flag.DurationVar(&certOpts.RotateBefore, "webhook-cert-rotate-before", 30*24*time.Hour,
+		"How long before expiry the webhook certificates will be rotated.")
+	flag.StringVar(&auditSink, "audit-sink", "",
+		"Where to record changes admitted to ApplicationConfigurations and Components: log, event or configmap. Empty disables auditing.")
+	flag.StringVar(&auditLogPath, "audit-log-path", "", "The file the log audit sink writes to, defaults to stdout.")
+	flag.IntVar(&auditConfigMapLimit, "audit-configmap-limit", 100,
+		"The number of audit records the configmap audit sink keeps per namespace.")
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
@@ -59,6 +85,16 @@ func main() {
 		"For the purpose of some production environment that workload or trait should not be affected if no spec change")
 	flag.DurationVar(&controllerArgs.LongWait, "long-wait", 1*time.Minute, "long-wait is controller next reconcile interval time like 30s, 2m etc. The default value is 1m, "+
 		"you can set it to 0 for no reconcile routine after success ")
+	flag.DurationVar(&controllerArgs.TeardownTimeout, "teardown-timeout", 0,
+		"teardown-timeout enables the ordered teardown of ApplicationConfigurations, it is how long their deletion waits "+
+			"for their traits and workloads to be deleted in order before leaving them to Kubernetes garbage collection. "+
+			"The default value is 0, which disables the ordered teardown.")
+	flag.IntVar(&controllerArgs.AppConfigRevisionLimit, "appconfig-revision-limit", 10,
+		"appconfig-revision-limit is the maximum number of revisions of the history of an ApplicationConfiguration that will be maintained. "+
+			"The default value is 10, 0 disables the history.")
+	flag.DurationVar(&controllerArgs.RevisionCleanupInterval, "revision-cleanup-interval", 10*time.Minute,
+		"revision-cleanup-interval is how often the revisions of all Components are cleaned up according to their retention policy. "+
+			"The default value is 10m, 0 disables the periodic cleanup.")
 	flag.Parse()
 
 	// setup logging
@@ -95,7 +131,31 @@ func main() {
 
 	if useWebhook {
 		oamLog.Info("OAM webhook enabled, will serving at :" + strconv.Itoa(webhookPort))
-		if err = webhook.Add(mgr); err != nil {
+		if autoCert {
+			// certificates must be in place before the webhook server starts,
+			// so they are provisioned with a client that bypasses the cache.
+			c, err := client.New(mgr.GetConfig(), client.Options{Scheme: scheme})
+			if err != nil {
+				oamLog.Error(err, "unable to create a client for webhook certificate provisioning")
+				os.Exit(1)
+			}
+			certOpts.CertDir = certDir
+			provisioner := certificate.NewProvisioner(c, certOpts, logging.NewLogrLogger(oamLog.WithName("certificate")))
+			if err = provisioner.Provision(context.Background()); err != nil {
+				oamLog.Error(err, "unable to provision the webhook certificates")
+				os.Exit(1)
+			}
+			if err = mgr.Add(provisioner); err != nil {
+				oamLog.Error(err, "unable to setup webhook certificate rotation")
+				os.Exit(1)
+			}
+		}
+		auditor, err := newAuditor(mgr, auditSink, auditLogPath, auditConfigMapLimit, logRetainDate, logCompress)
+		if err != nil {
+			oamLog.Error(err, "unable to setup the webhook audit sink")
+			os.Exit(1)
+		}
+		if err = webhook.Add(mgr, auditor); err != nil {
 			oamLog.Error(err, "unable to setup the webhook for core controller")
 			os.Exit(1)
 		}
@@ -115,3 +175,39 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// newAuditor returns an auditor writing to the named sink, or nil when no
+// sink is named. Records are written in the background by the manager.
+func newAuditor(mgr ctrl.Manager, sink, logPath string, limit, logRetainDate int, logCompress bool) (*audit.Auditor, error) {
+	var s audit.Sink
+	switch sink {
+	case "":
+		return nil, nil
+	case "log":
+		var w io.Writer = os.Stdout
+		if len(logPath) > 0 {
+			w = &lumberjack.Logger{
+				Filename: logPath,
+				MaxAge:   logRetainDate, // days
+				Compress: logCompress,
+			}
+		}
+		s = audit.NewWriterSink(w)
+	case "event":
+		s = audit.NewEventSink(mgr.GetEventRecorderFor("oam-audit"))
+	case "configmap":
+		// avoid caching every ConfigMap of the cluster
+		c, err := client.New(mgr.GetConfig(), client.Options{Scheme: scheme})
+		if err != nil {
+			return nil, err
+		}
+		s = audit.NewConfigMapSink(c, limit)
+	default:
+		return nil, fmt.Errorf("unknown audit sink %q", sink)
+	}
+	async := audit.NewAsyncSink(s, audit.DefaultBufferSize)
+	if err := mgr.Add(async); err != nil {
+		return nil, err
+	}
+	return audit.NewAuditor(async), nil
+}
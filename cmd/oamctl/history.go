@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/history"
+)
+
+// componentHistory lists the revisions of a Component.
+func componentHistory(ctx context.Context, cfg *rest.Config, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	namespace := fs.String("namespace", "default", "The namespace of the component.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: history [flags] <component>\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("a component is required")
+	}
+
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	revs, err := history.ComponentRevisions(ctx, c, *namespace, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "REVISION\tNAME\tCREATED\tREUSED\tAUTHOR\tCHANGE-CAUSE")
+	for _, rev := range revs {
+		reused := "<none>"
+		if rev.Reused != nil {
+			reused = rev.Reused.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", rev.Revision, rev.Name, rev.Created.Format(time.RFC3339), reused,
+			rev.Author, rev.ChangeCause)
+	}
+	return w.Flush()
+}
+
+// diff shows the changes to the workload and parameters of a Component
+// between two of its revisions.
+func diff(ctx context.Context, cfg *rest.Config, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	namespace := fs.String("namespace", "default", "The namespace of the component.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: diff [flags] <component> <revision> <revision>\n\n"+
+			"Revisions are given by name, e.g. web-v3, or number, e.g. 3.\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 3 {
+		fs.Usage()
+		return errors.New("a component and two of its revisions are required")
+	}
+
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	revs, err := history.ComponentRevisions(ctx, c, *namespace, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	from, err := history.Find(revs, fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	to, err := history.Find(revs, fs.Arg(2))
+	if err != nil {
+		return err
+	}
+	lines, err := history.Diff(from, to)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", from.Name, to.Name)
+	for _, l := range lines {
+		fmt.Fprintln(out, l)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/discoverymapper"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/orphan"
+)
+
+// orphans reports the orphaned OAM resources and dangling scope references,
+// and cleans them up if asked to.
+func orphans(ctx context.Context, cfg *rest.Config, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("orphans", flag.ContinueOnError)
+	namespace := fs.String("namespace", "", "The namespace to scan, all namespaces if empty.")
+	clean := fs.Bool("clean", false, "Delete the orphaned resources and remove the dangling workloadRefs from their scopes.")
+	dryRun := fs.Bool("dry-run", false, "With --clean, only report what would be cleaned up, without persisting any change.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	dm, err := discoverymapper.New(cfg)
+	if err != nil {
+		return err
+	}
+	f := orphan.NewFinder(c, dm)
+	findings, err := f.Find(ctx, *namespace)
+	if err != nil {
+		return err
+	}
+	for _, fd := range findings {
+		fmt.Fprintln(out, fd)
+	}
+	if len(findings) == 0 {
+		fmt.Fprintln(out, "no orphaned resources or dangling workloadRefs found")
+		return nil
+	}
+	if !*clean {
+		return nil
+	}
+	if err := f.Clean(ctx, findings, *dryRun); err != nil {
+		return err
+	}
+	if *dryRun {
+		fmt.Fprintf(out, "%d findings would be cleaned up (dry run)\n", len(findings))
+		return nil
+	}
+	fmt.Fprintf(out, "%d findings cleaned up\n", len(findings))
+	return nil
+}
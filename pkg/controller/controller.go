@@ -27,4 +27,21 @@ type Args struct {
 
 	// LongWait is controller next reconcile interval time
 	LongWait time.Duration
+
+	// TeardownTimeout is how long the deletion of an ApplicationConfiguration
+	// waits for its traits and workloads to be deleted in order, before it
+	// leaves the remaining ones to Kubernetes garbage collection. The ordered
+	// teardown is disabled when it is 0.
+	TeardownTimeout time.Duration
+
+	// AppConfigRevisionLimit is the maximum number of revisions of the history
+	// of an ApplicationConfiguration that will be maintained. 0 disables the
+	// history.
+	AppConfigRevisionLimit int
+
+	// RevisionCleanupInterval is how often the revisions of all Components
+	// are cleaned up according to their retention policy. 0 disables the
+	// periodic cleanup, revisions are then only cleaned up when a new one is
+	// created.
+	RevisionCleanupInterval time.Duration
 }
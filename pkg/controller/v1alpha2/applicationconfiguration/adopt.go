@@ -0,0 +1,122 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationconfiguration
+
+import (
+	"context"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
+)
+
+const (
+	errFmtNotAdopted    = "%s %q already exists and is not controlled by an ApplicationConfiguration: annotate the ApplicationConfiguration or its Component with " + oam.AnnotationAdopt + "=true to adopt it"
+	errFmtControlledBy  = "%s %q already exists and is controlled by %s %q"
+	errFmtInvalidObject = "invalid object being applied: %q"
+)
+
+// adopt returns an ApplyOption that adopts an existing resource that is not
+// controlled by anyone into the ApplicationConfiguration that controls the
+// desired resource, if the workload adopts resources. The owner references of
+// the existing resource are kept. Adopted resources are recorded in the
+// workload. Resources that are controlled by someone else are never adopted.
+// Resources that are labelled as the same component of the same
+// ApplicationConfiguration, e.g. because they were released with the Orphan
+// deletion policy before the component was added back, are taken back without
+// being recorded, whether the workload adopts resources or not.
+func adopt(w *Workload) resource.ApplyOption {
+	return func(_ context.Context, current, desired runtime.Object) error {
+		c, _ := current.(metav1.Object)
+		d, _ := desired.(metav1.Object)
+		if c == nil || d == nil {
+			return errors.Errorf(errFmtInvalidObject, desired.GetObjectKind().GroupVersionKind())
+		}
+		kind := desired.GetObjectKind().GroupVersionKind().Kind
+		owner := metav1.GetControllerOf(d)
+		controller := metav1.GetControllerOf(c)
+		switch {
+		case owner == nil:
+			return nil
+		case controller != nil && controller.UID == owner.UID:
+			return nil
+		case controller != nil:
+			return errors.Errorf(errFmtControlledBy, kind, d.GetName(), controller.Kind, controller.Name)
+		case !w.Adopt && !sameComponent(c, d):
+			return errors.Errorf(errFmtNotAdopted, kind, d.GetName())
+		}
+
+		owners := d.GetOwnerReferences()
+		for _, ref := range c.GetOwnerReferences() {
+			if ref.UID != owner.UID {
+				owners = append(owners, ref)
+			}
+		}
+		d.SetOwnerReferences(owners)
+		if sameComponent(c, d) {
+			return nil
+		}
+		w.Adopted = append(w.Adopted, runtimev1alpha1.TypedReference{
+			APIVersion: desired.GetObjectKind().GroupVersionKind().GroupVersion().String(),
+			Kind:       kind,
+			Name:       d.GetName(),
+		})
+		return nil
+	}
+}
+
+// sameComponent tells whether the existing resource is labelled as the same
+// component of the same ApplicationConfiguration as the desired one.
+func sameComponent(current, desired metav1.Object) bool {
+	c, d := current.GetLabels(), desired.GetLabels()
+	return d[oam.LabelAppName] != "" && d[oam.LabelAppComponent] != "" &&
+		c[oam.LabelAppName] == d[oam.LabelAppName] && c[oam.LabelAppComponent] == d[oam.LabelAppComponent]
+}
+
+// adopts tells whether the workload of a component adopts existing resources.
+func adopts(ac *v1alpha2.ApplicationConfiguration, c *v1alpha2.Component) bool {
+	return ac.GetAnnotations()[oam.AnnotationAdopt] == "true" || c.GetAnnotations()[oam.AnnotationAdopt] == "true"
+}
+
+// recordAdoptions records the resources the workloads adopted in the status of
+// the ApplicationConfiguration.
+func recordAdoptions(ac *v1alpha2.ApplicationConfiguration, workloads []Workload) []v1alpha2.AdoptedResource {
+	var adopted []v1alpha2.AdoptedResource
+	for _, w := range workloads {
+		for _, ref := range w.Adopted {
+			ar := v1alpha2.AdoptedResource{Reference: ref, ComponentName: w.ComponentName, AdoptedAt: metav1.Now()}
+			known := false
+			for i, prev := range ac.Status.AdoptedResources {
+				if util.SameResource(prev.Reference, ref) {
+					ac.Status.AdoptedResources[i] = ar
+					known = true
+				}
+			}
+			if !known {
+				ac.Status.AdoptedResources = append(ac.Status.AdoptedResources, ar)
+			}
+			adopted = append(adopted, ar)
+		}
+	}
+	return adopted
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationconfiguration
+
+import (
+	"context"
+	"testing"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+)
+
+func TestAdopt(t *testing.T) {
+	controller := true
+	acRef := metav1.OwnerReference{APIVersion: "core.oam.dev/v1alpha2", Kind: "ApplicationConfiguration", Name: "app",
+		UID: "ac", Controller: &controller}
+	otherRef := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "other", UID: "other",
+		Controller: &controller}
+	plainRef := metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: "config", UID: "config"}
+	object := func(owners ...metav1.OwnerReference) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("apps/v1")
+		u.SetKind("Deployment")
+		u.SetName("web")
+		u.SetOwnerReferences(owners)
+		return u
+	}
+	labelled := func(component string, owners ...metav1.OwnerReference) *unstructured.Unstructured {
+		u := object(owners...)
+		u.SetLabels(map[string]string{oam.LabelAppName: "app", oam.LabelAppComponent: component})
+		return u
+	}
+
+	cases := map[string]struct {
+		adopt       bool
+		current     *unstructured.Unstructured
+		wantErr     error
+		wantOwners  []metav1.OwnerReference
+		wantAdopted []runtimev1alpha1.TypedReference
+	}{
+		"AlreadyControlled": {
+			current:    object(acRef),
+			wantOwners: []metav1.OwnerReference{acRef},
+		},
+		"ControlledByOther": {
+			adopt:      true,
+			current:    object(otherRef),
+			wantErr:    errors.Errorf(errFmtControlledBy, "Deployment", "web", "ReplicaSet", "other"),
+			wantOwners: []metav1.OwnerReference{acRef},
+		},
+		"NotAdopted": {
+			current:    object(plainRef),
+			wantErr:    errors.Errorf(errFmtNotAdopted, "Deployment", "web"),
+			wantOwners: []metav1.OwnerReference{acRef},
+		},
+		"Adopted": {
+			adopt:       true,
+			current:     object(plainRef),
+			wantOwners:  []metav1.OwnerReference{acRef, plainRef},
+			wantAdopted: []runtimev1alpha1.TypedReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"}},
+		},
+		"SameComponent": {
+			current:    labelled("web", plainRef),
+			wantOwners: []metav1.OwnerReference{acRef, plainRef},
+		},
+		"OtherComponent": {
+			current:    labelled("db"),
+			wantErr:    errors.Errorf(errFmtNotAdopted, "Deployment", "web"),
+			wantOwners: []metav1.OwnerReference{acRef},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := &Workload{Adopt: tc.adopt}
+			desired := labelled("web", acRef)
+			err := adopt(w)(context.Background(), tc.current, desired)
+			if diff := cmp.Diff(tc.wantErr, err, test.EquateErrors()); diff != "" {
+				t.Errorf("adopt(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantOwners, desired.GetOwnerReferences()); diff != "" {
+				t.Errorf("adopt(...): -want owners, +got owners:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantAdopted, w.Adopted); diff != "" {
+				t.Errorf("adopt(...): -want adopted, +got adopted:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAdopts(t *testing.T) {
+	annotated := metav1.ObjectMeta{Annotations: map[string]string{oam.AnnotationAdopt: "true"}}
+	cases := map[string]struct {
+		ac   *v1alpha2.ApplicationConfiguration
+		c    *v1alpha2.Component
+		want bool
+	}{
+		"None":                     {ac: &v1alpha2.ApplicationConfiguration{}, c: &v1alpha2.Component{}},
+		"ApplicationConfiguration": {ac: &v1alpha2.ApplicationConfiguration{ObjectMeta: annotated}, c: &v1alpha2.Component{}, want: true},
+		"Component":                {ac: &v1alpha2.ApplicationConfiguration{}, c: &v1alpha2.Component{ObjectMeta: annotated}, want: true},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := adopts(tc.ac, tc.c); got != tc.want {
+				t.Errorf("adopts(...): want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRecordAdoptions(t *testing.T) {
+	ref := func(name string) runtimev1alpha1.TypedReference {
+		return runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: name}
+	}
+	ac := &v1alpha2.ApplicationConfiguration{}
+	ac.Status.AdoptedResources = []v1alpha2.AdoptedResource{{Reference: ref("web"), ComponentName: "web"}}
+	workloads := []Workload{
+		{ComponentName: "web", Adopted: []runtimev1alpha1.TypedReference{ref("web")}},
+		{ComponentName: "db", Adopted: []runtimev1alpha1.TypedReference{ref("db")}},
+		{ComponentName: "cache"},
+	}
+
+	adopted := recordAdoptions(ac, workloads)
+	names := func(as []v1alpha2.AdoptedResource) []string {
+		var n []string
+		for _, a := range as {
+			n = append(n, a.ComponentName+"/"+a.Reference.Name)
+			if a.AdoptedAt.IsZero() {
+				t.Errorf("recordAdoptions(...): %s has no adoption time", a.Reference.Name)
+			}
+		}
+		return n
+	}
+	if diff := cmp.Diff([]string{"web/web", "db/db"}, names(adopted)); diff != "" {
+		t.Errorf("recordAdoptions(...): -want adopted, +got adopted:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"web/web", "db/db"}, names(ac.Status.AdoptedResources)); diff != "" {
+		t.Errorf("recordAdoptions(...): -want status, +got status:\n%s", diff)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -50,6 +51,7 @@ const (
 	reconcileTimeout = 1 * time.Minute
 	dependCheckWait  = 10 * time.Second
 	shortWait        = 30 * time.Second
+	teardownWait     = 5 * time.Second
 )
 
 // Reconcile error strings.
@@ -62,6 +64,7 @@ const (
 	errApplyComponents       = "cannot apply components"
 	errGCComponent           = "cannot garbage collect components"
 	errFinalizeWorkloads     = "failed to finalize workloads"
+	errTeardown              = "failed to tear down workloads and traits"
 )
 
 // Reconcile event reasons.
@@ -77,6 +80,8 @@ const (
 	reasonCannotApplyComponents   = "CannotApplyComponents"
 	reasonCannotGGComponents      = "CannotGarbageCollectComponents"
 	reasonCannotFinalizeWorkloads = "CannotFinalizeWorkloads"
+	reasonCannotTeardown          = "CannotTeardown"
+	reasonAdoptedResource         = "AdoptedResource"
 )
 
 // Setup adds a controller that reconciles ApplicationConfigurations.
@@ -86,20 +91,28 @@ func Setup(mgr ctrl.Manager, args controller.Args, l logging.Logger) error {
 		return fmt.Errorf("create discovery dm fail %v", err)
 	}
 	name := "oam/" + strings.ToLower(v1alpha2.ApplicationConfigurationGroupKind)
+	handler := &ComponentHandler{
+		Client:        mgr.GetClient(),
+		Logger:        l,
+		RevisionLimit: args.RevisionLimit,
+	}
+	if args.RevisionCleanupInterval > 0 {
+		if err := mgr.Add(NewRevisionCollector(handler, args.RevisionCleanupInterval)); err != nil {
+			return fmt.Errorf("add revision collector fail %v", err)
+		}
+	}
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		For(&v1alpha2.ApplicationConfiguration{}).
-		Watches(&source.Kind{Type: &v1alpha2.Component{}}, &ComponentHandler{
-			Client:        mgr.GetClient(),
-			Logger:        l,
-			RevisionLimit: args.RevisionLimit,
-		}).
+		Watches(&source.Kind{Type: &v1alpha2.Component{}}, handler).
 		Complete(NewReconciler(mgr, dm,
 			WithLogger(l.WithValues("controller", name)),
 			WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
 			WithApplyOnceOnly(args.ApplyOnceOnly),
-			WithLogWaitTime(args.LongWait)))
+			WithLogWaitTime(args.LongWait),
+			WithTeardownTimeout(args.TeardownTimeout),
+			WithAppConfigRevisionLimit(args.AppConfigRevisionLimit)))
 }
 
 // An OAMApplicationReconciler reconciles OAM ApplicationConfigurations by rendering and
@@ -116,6 +129,9 @@ type OAMApplicationReconciler struct {
 	postHooks     map[string]ControllerHooks
 	applyOnceOnly bool
 	longWait      time.Duration
+
+	teardownTimeout time.Duration
+	revisionLimit   int
 }
 
 // A ReconcilerOption configures a Reconciler.
@@ -187,6 +203,24 @@ func WithLogWaitTime(longWait time.Duration) ReconcilerOption {
 	}
 }
 
+// WithTeardownTimeout specifies how long the deletion of an
+// ApplicationConfiguration waits for its traits and workloads to be deleted in
+// order. A timeout of 0, the default, leaves their deletion to Kubernetes
+// garbage collection.
+func WithTeardownTimeout(timeout time.Duration) ReconcilerOption {
+	return func(r *OAMApplicationReconciler) {
+		r.teardownTimeout = timeout
+	}
+}
+
+// WithAppConfigRevisionLimit specifies how many revisions of the history of an
+// ApplicationConfiguration are maintained. A limit of 0 disables the history.
+func WithAppConfigRevisionLimit(limit int) ReconcilerOption {
+	return func(r *OAMApplicationReconciler) {
+		r.revisionLimit = limit
+	}
+}
+
 // NewReconciler returns an OAMApplicationReconciler that reconciles ApplicationConfigurations
 // by rendering and instantiating their Components and Traits.
 func NewReconciler(m ctrl.Manager, dm discoverymapper.DiscoveryMapper, o ...ReconcilerOption) *OAMApplicationReconciler {
@@ -241,17 +275,45 @@ func (r *OAMApplicationReconciler) Reconcile(req reconcile.Request) (result reco
 	acPatch := ac.DeepCopy()
 
 	if ac.ObjectMeta.DeletionTimestamp.IsZero() {
-		if registerFinalizers(ac) {
+		if r.registerFinalizers(ac) {
 			log.Debug("Register new finalizers", "finalizers", ac.ObjectMeta.Finalizers)
 			return reconcile.Result{}, errors.Wrap(r.client.Update(ctx, ac), errUpdateAppConfigStatus)
 		}
+		if _, ok := ac.GetAnnotations()[oam.AnnotationRollbackTo]; ok {
+			revision, pinned, err := r.rollback(ctx, ac)
+			if err != nil {
+				log.Debug("Cannot roll back", "error", err)
+				r.record.Event(ac, event.Warning(reasonCannotRollback, errors.Wrap(err, errRollback)))
+			} else {
+				log.Debug("Rolled back", "revision", revision, "pinned", pinned)
+				r.record.Event(ac, event.Normal(reasonRolledBack, "Successfully rolled back", "revision", revision,
+					"pinned", strings.Join(pinned, ",")))
+			}
+			return reconcile.Result{}, errors.Wrap(r.client.Update(ctx, ac), errUpdateAppConfigStatus)
+		}
 	} else {
+		ac.Status.Status = v1alpha2.ApplicationDeleting
+		ac.SetConditions(v1alpha1.Deleting())
 		if err := r.workloads.Finalize(ctx, ac); err != nil {
 			log.Debug("Failed to finalize workloads", "workloads status", ac.Status.Workloads,
 				"error", err, "requeue-after", result.RequeueAfter)
 			r.record.Event(ac, event.Warning(reasonCannotFinalizeWorkloads, err))
 			ac.SetConditions(v1alpha1.ReconcileError(errors.Wrap(err, errFinalizeWorkloads)))
-			return reconcile.Result{}, errors.Wrap(r.client.Status().Update(ctx, ac), errUpdateAppConfigStatus)
+			return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, ac), errUpdateAppConfigStatus)
+		}
+		if meta.FinalizerExists(&ac.ObjectMeta, teardownFinalizer) {
+			done, err := r.teardown(ctx, ac)
+			if err != nil {
+				log.Debug("Failed to tear down workloads and traits", "error", err, "requeue-after", time.Now().Add(shortWait))
+				r.record.Event(ac, event.Warning(reasonCannotTeardown, err))
+				ac.SetConditions(v1alpha1.ReconcileError(errors.Wrap(err, errTeardown)))
+				return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, ac), errUpdateAppConfigStatus)
+			}
+			if !done {
+				log.Debug("Waiting for the teardown", "teardown", ac.Status.Teardown)
+				return reconcile.Result{RequeueAfter: teardownWait}, errors.Wrap(r.client.Status().Update(ctx, ac), errUpdateAppConfigStatus)
+			}
+			meta.RemoveFinalizer(&ac.ObjectMeta, teardownFinalizer)
 		}
 		return reconcile.Result{}, errors.Wrap(r.client.Update(ctx, ac), errUpdateAppConfigStatus)
 	}
@@ -281,6 +343,7 @@ func (r *OAMApplicationReconciler) Reconcile(req reconcile.Request) (result reco
 			log.Debug("Failed to execute pre-hooks", "hook name", name, "error", err, "requeue-after", result.RequeueAfter)
 			r.record.Event(ac, event.Warning(reasonCannotExecutePrehooks, err))
 			ac.SetConditions(v1alpha1.ReconcileError(errors.Wrap(err, errExecutePrehooks)))
+			ac.Status.Status = v1alpha2.ApplicationFailed
 			return result, errors.Wrap(r.client.Status().Update(ctx, ac), errUpdateAppConfigStatus)
 		}
 		r.record.Event(ac, event.Normal(reasonExecutePrehook, "Successfully executed a prehook", "prehook name ", name))
@@ -294,6 +357,10 @@ func (r *OAMApplicationReconciler) Reconcile(req reconcile.Request) (result reco
 		log.Info("Cannot render components", "error", err, "requeue-after", time.Now().Add(shortWait))
 		r.record.Event(ac, event.Warning(reasonCannotRenderComponents, err))
 		ac.SetConditions(v1alpha1.ReconcileError(errors.Wrap(err, errRenderComponents)))
+		ac.Status.Status = v1alpha2.ApplicationFailed
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			ac.Status.Status = v1alpha2.ApplicationRendering
+		}
 		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, ac), errUpdateAppConfigStatus)
 	}
 	waitTime := r.longWait
@@ -312,26 +379,42 @@ func (r *OAMApplicationReconciler) Reconcile(req reconcile.Request) (result reco
 		log.Debug("Cannot apply components", "error", err, "requeue-after", time.Now().Add(shortWait))
 		r.record.Event(ac, event.Warning(reasonCannotApplyComponents, err))
 		ac.SetConditions(v1alpha1.ReconcileError(errors.Wrap(err, errApplyComponents)))
+		ac.Status.Status = v1alpha2.ApplicationFailed
 		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, ac), errUpdateAppConfigStatus)
 	}
 	log.Debug("Successfully applied components", "workloads", len(workloads))
 	r.record.Event(ac, event.Normal(reasonApplyComponents, "Successfully applied components", "workloads", strconv.Itoa(len(workloads))))
+	for _, a := range recordAdoptions(ac, workloads) {
+		log.Debug("Adopted existing resource", "component", a.ComponentName, "kind", a.Reference.Kind, "name", a.Reference.Name)
+		r.record.WithAnnotations("kind", a.Reference.Kind, "name", a.Reference.Name).
+			Event(ac, event.Normal(reasonAdoptedResource, "Adopted existing resource", "component", a.ComponentName))
+	}
 
 	// Kubernetes garbage collection will (by default) reap workloads and traits
 	// when the appconfig that controls them (in the controller reference sense)
 	// is deleted. Here we cover the case in which a component or one of its
-	// traits is removed from an extant appconfig.
+	// traits is removed from an extant appconfig, according to its deletion
+	// policy.
+	if err := r.collectRetained(ctx, ac, workloads); err != nil {
+		log.Debug("Cannot garbage collect retained resources", "error", err, "requeue-after", time.Now().Add(shortWait))
+		r.record.Event(ac, event.Warning(reasonCannotGGComponents, err))
+		ac.SetConditions(v1alpha1.ReconcileError(errors.Wrap(err, errGCComponent)))
+		ac.Status.Status = v1alpha2.ApplicationFailed
+		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, ac), errUpdateAppConfigStatus)
+	}
 	for _, e := range r.gc.Eligible(ac.GetNamespace(), ac.Status.Workloads, workloads) {
 		// https://github.com/golang/go/wiki/CommonMistakes#using-reference-to-loop-iterator-variable
 		e := e
 
-		log := log.WithValues("kind", e.GetKind(), "name", e.GetName())
-		record := r.record.WithAnnotations("kind", e.GetKind(), "name", e.GetName())
+		policy := deletionPolicyOf(ac.Status.Workloads, util.ReferenceTo(&e))
+		log := log.WithValues("kind", e.GetKind(), "name", e.GetName(), "deletion-policy", policy)
+		record := r.record.WithAnnotations("kind", e.GetKind(), "name", e.GetName(), "deletion-policy", string(policy))
 
-		if err := r.client.Delete(ctx, &e); resource.IgnoreNotFound(err) != nil {
+		if err := r.release(ctx, ac, &e, policy); err != nil {
 			log.Debug("Cannot garbage collect component", "error", err, "requeue-after", time.Now().Add(shortWait))
 			record.Event(ac, event.Warning(reasonCannotGGComponents, err))
 			ac.SetConditions(v1alpha1.ReconcileError(errors.Wrap(err, errGCComponent)))
+			ac.Status.Status = v1alpha2.ApplicationFailed
 			return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, ac), errUpdateAppConfigStatus)
 		}
 		log.Debug("Garbage collected resource")
@@ -341,6 +424,21 @@ func (r *OAMApplicationReconciler) Reconcile(req reconcile.Request) (result reco
 	// patch the final status on the client side, k8s sever can't merge them
 	r.updateStatus(ctx, ac, acPatch, workloads)
 
+	r.cleanupHistoryWorkloads(ctx, ac, workloads)
+
+	// record the applied spec once all of the components could be applied
+	if r.revisionLimit > 0 && len(depStatus.Unsatisfied) == 0 {
+		recorded, err := r.recordRevision(ctx, ac, workloads)
+		if err != nil {
+			log.Debug("Cannot record revision", "error", err)
+			r.record.Event(ac, event.Warning(reasonCannotRecordRevision, errors.Wrap(err, errRecordRevision)))
+		} else if recorded {
+			log.Debug("Recorded revision", "revision", ac.Status.LatestRevision.Name)
+			r.record.Event(ac, event.Normal(reasonRecordedRevision, "Successfully recorded revision",
+				"revision", ac.Status.LatestRevision.Name))
+		}
+	}
+
 	// the posthook function will do the final status update
 	return reconcile.Result{RequeueAfter: waitTime}, nil
 }
@@ -353,16 +451,11 @@ func (r *OAMApplicationReconciler) updateStatus(ctx context.Context, ac, acPatch
 		if !w.RevisionEnabled {
 			continue
 		}
-		var ul unstructured.UnstructuredList
-		ul.SetKind(w.Workload.GetKind())
-		ul.SetAPIVersion(w.Workload.GetAPIVersion())
-		if err := r.client.List(ctx, &ul, client.MatchingLabels{oam.LabelAppName: ac.Name, oam.LabelAppComponent: w.ComponentName, oam.LabelOAMResourceType: oam.ResourceTypeWorkload}); err != nil {
+		_, history, err := r.revisionWorkloads(ctx, ac, w)
+		if err != nil {
 			continue
 		}
-		for _, v := range ul.Items {
-			if v.GetName() == w.ComponentRevisionName {
-				continue
-			}
+		for _, v := range history {
 			// These workload exists means the component is under progress of rollout
 			// Trait will not work for these remaining workload
 			historyWorkloads = append(historyWorkloads, v1alpha2.HistoryWorkload{
@@ -379,6 +472,9 @@ func (r *OAMApplicationReconciler) updateStatus(ctx context.Context, ac, acPatch
 	ac.Status.HistoryWorkloads = historyWorkloads
 	// patch the extra fields in the status that is wiped by the Status() function
 	patchExtraStatusField(&ac.Status, acPatch.Status)
+	r.projectStatus(ctx, ac, workloads)
+	aggregateHealth(ac, workloads)
+	updatePhase(ac, acPatch.Status.Status, workloads)
 	ac.SetConditions(v1alpha1.ReconcileSuccess())
 }
 
@@ -397,6 +493,21 @@ func patchExtraStatusField(acStatus *v1alpha2.ApplicationConfigurationStatus, ac
 				if len(w.Status) > 0 {
 					acStatus.Workloads[i].Status = w.Status
 				}
+				acStatus.Workloads[i].Message = w.Message
+				acStatus.Workloads[i].Fields = w.Fields
+				// keep the readiness and health of the workload until they are
+				// observed again
+				for _, c := range w.Conditions {
+					if c.Type == v1alpha1.TypeReady || c.Type == v1alpha2.TypeHealthy {
+						acStatus.Workloads[i].SetConditions(c)
+					}
+				}
+				// keep the transition time of conditions that did not change
+				for j, c := range acStatus.Workloads[i].Conditions {
+					if prev := w.GetCondition(c.Type); prev.Equal(c) {
+						acStatus.Workloads[i].Conditions[j] = prev
+					}
+				}
 				// find the trait
 				for j := range acStatus.Workloads[i].Traits {
 					for _, t := range w.Traits {
@@ -405,6 +516,8 @@ func patchExtraStatusField(acStatus *v1alpha2.ApplicationConfigurationStatus, ac
 							if len(t.Status) > 0 {
 								acStatus.Workloads[i].Traits[j].Status = t.Status
 							}
+							acStatus.Workloads[i].Traits[j].Message = t.Message
+							acStatus.Workloads[i].Traits[j].Fields = t.Fields
 						}
 					}
 				}
@@ -414,12 +527,21 @@ func patchExtraStatusField(acStatus *v1alpha2.ApplicationConfigurationStatus, ac
 }
 
 // if any finalizers newly registered, return true
-func registerFinalizers(ac *v1alpha2.ApplicationConfiguration) bool {
+func (r *OAMApplicationReconciler) registerFinalizers(ac *v1alpha2.ApplicationConfiguration) bool {
 	newFinalizer := false
 	if !meta.FinalizerExists(&ac.ObjectMeta, workloadScopeFinalizer) && hasScope(ac) {
 		meta.AddFinalizer(&ac.ObjectMeta, workloadScopeFinalizer)
 		newFinalizer = true
 	}
+	if !meta.FinalizerExists(&ac.ObjectMeta, deletionPolicyFinalizer) && hasDeletionPolicy(ac) {
+		meta.AddFinalizer(&ac.ObjectMeta, deletionPolicyFinalizer)
+		newFinalizer = true
+	}
+	// the workloads and traits to tear down are known once they were applied
+	if !meta.FinalizerExists(&ac.ObjectMeta, teardownFinalizer) && r.teardownTimeout > 0 && len(ac.Status.Workloads) > 0 {
+		meta.AddFinalizer(&ac.ObjectMeta, teardownFinalizer)
+		newFinalizer = true
+	}
 	return newFinalizer
 }
 
@@ -452,6 +574,10 @@ type Workload struct {
 	// RevisionEnabled means multiple workloads of same component will possibly be alive.
 	RevisionEnabled bool
 
+	// HistoryRetention specifies which workloads of earlier revisions of the
+	// component are kept when revision is enabled.
+	HistoryRetention *v1alpha2.HistoryWorkloadRetentionPolicy
+
 	// Scopes associated with this workload.
 	Scopes []unstructured.Unstructured
 
@@ -460,6 +586,23 @@ type Workload struct {
 
 	// Record the DataInputs of this workload.
 	DataInputs []v1alpha2.DataInput
+
+	// Conditions of this workload, e.g. whether some of its traits were not
+	// applied because they conflict.
+	Conditions []runtimev1alpha1.Condition
+
+	// StatusProjection of the WorkloadDefinition of this workload.
+	StatusProjection *v1alpha2.StatusProjection
+
+	// DeletionPolicy of this workload.
+	DeletionPolicy v1alpha2.DeletionPolicy
+
+	// Adopt existing resources that are not controlled by anyone when this
+	// workload and its traits are applied.
+	Adopt bool
+
+	// Adopted resources of this workload and its traits.
+	Adopted []runtimev1alpha1.TypedReference
 }
 
 // A Trait produced by an OAM ApplicationConfiguration.
@@ -477,6 +620,9 @@ type Trait struct {
 
 	// Record the DataInputs of this trait.
 	DataInputs []v1alpha2.DataInput
+
+	// DeletionPolicy of this trait.
+	DeletionPolicy v1alpha2.DeletionPolicy
 }
 
 // Status produces the status of this workload and its traits, suitable for use
@@ -490,9 +636,11 @@ func (w Workload) Status() v1alpha2.WorkloadStatus {
 			Kind:       w.Workload.GetKind(),
 			Name:       w.Workload.GetName(),
 		},
-		Traits: make([]v1alpha2.WorkloadTrait, len(w.Traits)),
-		Scopes: make([]v1alpha2.WorkloadScope, len(w.Scopes)),
+		Traits:         make([]v1alpha2.WorkloadTrait, len(w.Traits)),
+		Scopes:         make([]v1alpha2.WorkloadScope, len(w.Scopes)),
+		DeletionPolicy: w.DeletionPolicy,
 	}
+	acw.SetConditions(w.Conditions...)
 	for i, tr := range w.Traits {
 		if tr.Definition.Name == util.Dummy && tr.Definition.Spec.Reference.Name == util.Dummy {
 			acw.Traits[i].Message = util.DummyTraitMessage
@@ -502,6 +650,7 @@ func (w Workload) Status() v1alpha2.WorkloadStatus {
 			Kind:       w.Traits[i].Object.GetKind(),
 			Name:       w.Traits[i].Object.GetName(),
 		}
+		acw.Traits[i].DeletionPolicy = tr.DeletionPolicy
 	}
 	for i, s := range w.Scopes {
 		acw.Scopes[i].Reference = runtimev1alpha1.TypedReference{
@@ -62,6 +62,12 @@ func withWorkloadStatuses(ws ...v1alpha2.WorkloadStatus) acParam {
 	}
 }
 
+func withPhase(s v1alpha2.ApplicationStatus) acParam {
+	return func(ac *v1alpha2.ApplicationConfiguration) {
+		ac.Status.Status = s
+	}
+}
+
 func withDependencyStatus(s v1alpha2.DependencyStatus) acParam {
 	return func(ac *v1alpha2.ApplicationConfiguration) {
 		ac.Status.Dependency = s
@@ -168,7 +174,7 @@ func TestReconciler(t *testing.T) {
 						MockGet: mockGetAppConfigFn,
 						MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o runtime.Object) error {
 
-							want := ac(withConditions(runtimev1alpha1.ReconcileError(errors.Wrap(errBoom, errRenderComponents))))
+							want := ac(withConditions(runtimev1alpha1.ReconcileError(errors.Wrap(errBoom, errRenderComponents))), withPhase(v1alpha2.ApplicationFailed))
 							if diff := cmp.Diff(want, o.(*v1alpha2.ApplicationConfiguration)); diff != "" {
 								t.Errorf("\nclient.Status().Update(): -want, +got:\n%s", diff)
 								return errUnexpectedStatus
@@ -195,7 +201,7 @@ func TestReconciler(t *testing.T) {
 					Client: &test.MockClient{
 						MockGet: mockGetAppConfigFn,
 						MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o runtime.Object) error {
-							want := ac(withConditions(runtimev1alpha1.ReconcileError(errors.Wrap(errBoom, errApplyComponents))))
+							want := ac(withConditions(runtimev1alpha1.ReconcileError(errors.Wrap(errBoom, errApplyComponents))), withPhase(v1alpha2.ApplicationFailed))
 							if diff := cmp.Diff(want, o.(*v1alpha2.ApplicationConfiguration)); diff != "" {
 								t.Errorf("\nclient.Status().Update(): -want, +got:\n%s", diff)
 								return errUnexpectedStatus
@@ -225,7 +231,7 @@ func TestReconciler(t *testing.T) {
 						MockGet:    mockGetAppConfigFn,
 						MockDelete: test.NewMockDeleteFn(errBoom),
 						MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o runtime.Object) error {
-							want := ac(withConditions(runtimev1alpha1.ReconcileError(errors.Wrap(errBoom, errGCComponent))))
+							want := ac(withConditions(runtimev1alpha1.ReconcileError(errors.Wrap(errBoom, errGCComponent))), withPhase(v1alpha2.ApplicationFailed))
 							if diff := cmp.Diff(want, o.(*v1alpha2.ApplicationConfiguration)); diff != "" {
 								t.Errorf("\nclient.Status().Update(): -want, +got:\n%s", diff)
 								return errUnexpectedStatus
@@ -259,7 +265,7 @@ func TestReconciler(t *testing.T) {
 						MockDelete: test.NewMockDeleteFn(nil),
 						MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o runtime.Object) error {
 							want := ac(
-								withConditions(runtimev1alpha1.ReconcileSuccess()),
+								withConditions(runtimev1alpha1.Available(), runtimev1alpha1.ReconcileSuccess()),
 								withWorkloadStatuses(v1alpha2.WorkloadStatus{
 									ComponentName: componentName,
 									Reference: runtimev1alpha1.TypedReference{
@@ -269,6 +275,7 @@ func TestReconciler(t *testing.T) {
 									},
 								}),
 								withDependencyStatus(depStatus),
+								withPhase(v1alpha2.ApplicationWaitingForDependencies),
 							)
 							if diff := cmp.Diff(want, o.(*v1alpha2.ApplicationConfiguration), cmpopts.EquateEmpty()); diff != "" {
 								t.Errorf("\nclient.Status().Update(): -want, +got:\n%s", diff)
@@ -278,7 +285,7 @@ func TestReconciler(t *testing.T) {
 						}),
 						MockStatusPatch: test.NewMockStatusPatchFn(nil, func(o runtime.Object) error {
 							want := ac(
-								withConditions(runtimev1alpha1.ReconcileSuccess()),
+								withConditions(runtimev1alpha1.Available(), runtimev1alpha1.ReconcileSuccess()),
 								withWorkloadStatuses(v1alpha2.WorkloadStatus{
 									ComponentName: componentName,
 									Reference: runtimev1alpha1.TypedReference{
@@ -288,6 +295,7 @@ func TestReconciler(t *testing.T) {
 									},
 								}),
 								withDependencyStatus(depStatus),
+								withPhase(v1alpha2.ApplicationWaitingForDependencies),
 							)
 							if diff := cmp.Diff(want, o.(*v1alpha2.ApplicationConfiguration), cmpopts.EquateEmpty()); diff != "" {
 								t.Errorf("\nclient.Status().Update(): -want, +got:\n%s", diff)
@@ -323,6 +331,7 @@ func TestReconciler(t *testing.T) {
 						MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o runtime.Object) error {
 							want := ac(
 								withConditions(runtimev1alpha1.ReconcileError(errors.Wrap(errBoom, errExecutePrehooks))),
+								withPhase(v1alpha2.ApplicationFailed),
 							)
 							if diff := cmp.Diff(want, o.(*v1alpha2.ApplicationConfiguration), cmpopts.EquateEmpty()); diff != "" {
 								t.Errorf("\nclient.Status().Update(): -want, +got:\n%s", diff)
@@ -375,7 +384,8 @@ func TestReconciler(t *testing.T) {
 									},
 								}),
 							)
-							want.SetConditions(runtimev1alpha1.ReconcileSuccess())
+							want.SetConditions(runtimev1alpha1.Available(), runtimev1alpha1.ReconcileSuccess())
+							want.Status.Status = v1alpha2.ApplicationRunning
 							diff := cmp.Diff(want, o.(*v1alpha2.ApplicationConfiguration), cmpopts.EquateEmpty())
 							want.SetConditions(runtimev1alpha1.ReconcileError(errors.Wrap(errBoom, errExecutePosthooks)))
 							diffPost := cmp.Diff(want, o.(*v1alpha2.ApplicationConfiguration), cmpopts.EquateEmpty())
@@ -396,7 +406,8 @@ func TestReconciler(t *testing.T) {
 									},
 								}),
 							)
-							want.SetConditions(runtimev1alpha1.ReconcileSuccess())
+							want.SetConditions(runtimev1alpha1.Available(), runtimev1alpha1.ReconcileSuccess())
+							want.Status.Status = v1alpha2.ApplicationRunning
 							if diff := cmp.Diff(want, o.(*v1alpha2.ApplicationConfiguration), cmpopts.EquateEmpty()); diff != "" {
 								t.Errorf("\nclient.Status().Update(): -want, +got:\n%s", diff)
 								return errUnexpectedStatus
@@ -437,6 +448,7 @@ func TestReconciler(t *testing.T) {
 						MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o runtime.Object) error {
 							want := ac(
 								withConditions(runtimev1alpha1.ReconcileError(errors.Wrap(errBoom, errExecutePrehooks))),
+								withPhase(v1alpha2.ApplicationFailed),
 							)
 							diff := cmp.Diff(want, o.(*v1alpha2.ApplicationConfiguration), cmpopts.EquateEmpty())
 							want.SetConditions(runtimev1alpha1.ReconcileError(errors.Wrap(errBoom, errExecutePosthooks)))
@@ -486,7 +498,8 @@ func TestReconciler(t *testing.T) {
 						MockDelete: test.NewMockDeleteFn(nil),
 						MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o runtime.Object) error {
 							want := ac(
-								withConditions(runtimev1alpha1.ReconcileSuccess()),
+								withConditions(runtimev1alpha1.Available(), runtimev1alpha1.ReconcileSuccess()),
+								withPhase(v1alpha2.ApplicationRunning),
 								withWorkloadStatuses(v1alpha2.WorkloadStatus{
 									ComponentName: componentName,
 									Reference: runtimev1alpha1.TypedReference{
@@ -504,7 +517,8 @@ func TestReconciler(t *testing.T) {
 						}),
 						MockStatusPatch: test.NewMockStatusPatchFn(nil, func(o runtime.Object) error {
 							want := ac(
-								withConditions(runtimev1alpha1.ReconcileSuccess()),
+								withConditions(runtimev1alpha1.Available(), runtimev1alpha1.ReconcileSuccess()),
+								withPhase(v1alpha2.ApplicationRunning),
 								withWorkloadStatuses(v1alpha2.WorkloadStatus{
 									ComponentName: componentName,
 									Reference: runtimev1alpha1.TypedReference{
@@ -606,6 +620,8 @@ func TestReconciler(t *testing.T) {
 								DeletionTimestamp: &now,
 								Finalizers:        []string{},
 							}}
+							want.SetConditions(runtimev1alpha1.Deleting())
+							want.Status.Status = v1alpha2.ApplicationDeleting
 							if diff := cmp.Diff(want, o.(*v1alpha2.ApplicationConfiguration), cmpopts.EquateEmpty()); diff != "" {
 								t.Errorf("\nclient.Update(): -want, +got:\n%s", diff)
 								return errUnexpectedStatus
@@ -654,7 +670,7 @@ func TestReconciler(t *testing.T) {
 				},
 			},
 			want: want{
-				result: reconcile.Result{},
+				result: reconcile.Result{RequeueAfter: shortWait},
 			},
 		},
 	}
@@ -51,11 +51,13 @@ const (
 	errFmtGetScopeWorkloadRefsPath = "cannot get workloadRefsPath for scope to be dereferenced %q %q %q"
 	errFmtApplyTrait               = "cannot apply trait %q %q %q"
 	errFmtApplyScope               = "cannot apply scope %q %q %q"
+	errFmtListScopes               = "cannot list scopes %q %q to check component overlap"
 
-	workloadScopeFinalizer      = "scope.finalizer.core.oam.dev"
-	dot                    byte = '.'
-	slash                  byte = '/'
-	dQuotes                byte = '"'
+	workloadScopeFinalizer       = "scope.finalizer.core.oam.dev"
+	deletionPolicyFinalizer      = "deletionpolicy.finalizer.core.oam.dev"
+	dot                     byte = '.'
+	slash                   byte = '/'
+	dQuotes                 byte = '"'
 )
 
 var (
@@ -105,13 +107,21 @@ func (a *workloads) Apply(ctx context.Context, status []v1alpha2.WorkloadStatus,
 	}
 	// they are all in the same namespace
 	var namespace = w[0].Workload.GetNamespace()
-	for _, wl := range w {
+	// dereference the scopes workloads are leaving before joining new ones, so
+	// that moving a workload between two scopes of a kind that does not allow
+	// component overlap is not mistaken for an overlap.
+	if err := a.dereferenceScope(ctx, namespace, status, w); err != nil {
+		return err
+	}
+	for i := range w {
+		wl := &w[i]
+		wao := append(ao[:len(ao):len(ao)], adopt(wl))
 		if !wl.HasDep {
 			// Apply the DataInputs to this workload
 			if err := a.ApplyInputRef(ctx, wl.Workload, wl.DataInputs, namespace, ao...); err != nil {
 				return err
 			}
-			err := a.patchingClient.Apply(ctx, wl.Workload, ao...)
+			err := a.patchingClient.Apply(ctx, wl.Workload, wao...)
 			if err != nil {
 				if _, ok := err.(*GenerationUnchanged); !ok {
 					// GenerationUnchanged only aborts applying current workload
@@ -130,7 +140,7 @@ func (a *workloads) Apply(ctx context.Context, status []v1alpha2.WorkloadStatus,
 					return err
 				}
 				t := trait.Object
-				if err := a.updatingClient.Apply(ctx, &trait.Object, ao...); err != nil {
+				if err := a.updatingClient.Apply(ctx, &trait.Object, wao...); err != nil {
 					if _, ok := err.(*GenerationUnchanged); !ok {
 						// GenerationUnchanged only aborts applying current trait
 						// but not blocks the whole reconciliation through returning an error
@@ -147,14 +157,26 @@ func (a *workloads) Apply(ctx context.Context, status []v1alpha2.WorkloadStatus,
 			Kind:       wl.Workload.GetKind(),
 			Name:       wl.Workload.GetName(),
 		}
+		joined := make([]unstructured.Unstructured, 0, len(wl.Scopes))
+		var overlaps []string
 		for _, s := range wl.Scopes {
-			if err := a.applyScope(ctx, wl, s, workloadRef); err != nil {
+			overlap, err := a.applyScope(ctx, *wl, s, workloadRef)
+			if err != nil {
 				return err
 			}
+			if overlap != "" {
+				overlaps = append(overlaps, overlap)
+				continue
+			}
+			joined = append(joined, s)
+		}
+		wl.Scopes = joined
+		if len(overlaps) > 0 {
+			wl.Conditions = append(wl.Conditions, v1alpha2.ScopeOverlap(strings.Join(overlaps, "; ")))
 		}
 	}
 
-	return a.dereferenceScope(ctx, namespace, status, w)
+	return nil
 }
 func (a *workloads) ApplyOutputRef(ctx context.Context, w *unstructured.Unstructured, outputs map[string]v1alpha2.DataOutput, namespace string, ao ...resource.ApplyOption) error {
 	for _, output := range outputs {
@@ -280,6 +302,13 @@ func (a *workloads) Finalize(ctx context.Context, ac *v1alpha2.ApplicationConfig
 		meta.RemoveFinalizer(&ac.ObjectMeta, workloadScopeFinalizer)
 	}
 
+	if meta.FinalizerExists(&ac.ObjectMeta, deletionPolicyFinalizer) {
+		if err := releaseAll(ctx, a.rawClient, ac); err != nil {
+			return err
+		}
+		meta.RemoveFinalizer(&ac.ObjectMeta, deletionPolicyFinalizer)
+	}
+
 	// add finalizer logic here
 	return nil
 }
@@ -339,17 +368,19 @@ func findDereferencedScopes(statusScopes []v1alpha2.WorkloadScope, scopes []unst
 	return toBeDeferenced
 }
 
-func (a *workloads) applyScope(ctx context.Context, wl Workload, s unstructured.Unstructured, workloadRef runtimev1alpha1.TypedReference) error {
+// applyScope adds the workload to the scope. It returns why the workload did
+// not join the scope if it would overlap with another scope of the same kind.
+func (a *workloads) applyScope(ctx context.Context, wl Workload, s unstructured.Unstructured, workloadRef runtimev1alpha1.TypedReference) (string, error) {
 	// get ScopeDefinition
-	scopeDefinition, err := util.FetchScopeDefinition(ctx, a.rawClient, a.dm, &s)
+	scopeDefinition, err := util.FetchScopeDefinition(ctx, a.rawClient, a.dm, wl.Workload.GetNamespace(), &s)
 	if err != nil {
-		return errors.Wrapf(err, errFmtGetScopeDefinition, s.GetAPIVersion(), s.GetKind(), s.GetName())
+		return "", errors.Wrapf(err, errFmtGetScopeDefinition, s.GetAPIVersion(), s.GetKind(), s.GetName())
 	}
 	// checkout whether scope asks for workloadRef
 	workloadRefsPath := scopeDefinition.Spec.WorkloadRefsPath
 	if len(workloadRefsPath) == 0 {
 		// this scope does not ask for workloadRefs
-		return nil
+		return "", nil
 	}
 
 	var refs []interface{}
@@ -362,23 +393,67 @@ func (a *workloads) applyScope(ctx context.Context, wl Workload, s unstructured.
 				(workloadRef.Kind == ref["kind"]) &&
 				(workloadRef.Name == ref["name"]) {
 				// workloadRef is already present, so no need to add it.
-				return nil
+				return "", nil
 			}
 		}
 	} else {
-		return errors.Wrapf(err, errFmtGetScopeWorkloadRef, s.GetAPIVersion(), s.GetKind(), s.GetName(), workloadRefsPath)
+		return "", errors.Wrapf(err, errFmtGetScopeWorkloadRef, s.GetAPIVersion(), s.GetKind(), s.GetName(), workloadRefsPath)
+	}
+
+	if !scopeDefinition.Spec.AllowComponentOverlap {
+		other, err := a.overlappingScope(ctx, s, scopeDefinition, workloadRef)
+		if err != nil {
+			return "", err
+		}
+		if other != "" {
+			return fmt.Sprintf(msgFmtScopeOverlap, s.GetName(), other, scopeDefinition.GetName()), nil
+		}
 	}
 
 	refs = append(refs, workloadRef)
 	if err := fieldpath.Pave(s.UnstructuredContent()).SetValue(workloadRefsPath, refs); err != nil {
-		return errors.Wrapf(err, errFmtSetScopeWorkloadRef, s.GetName(), wl.Workload.GetName())
+		return "", errors.Wrapf(err, errFmtSetScopeWorkloadRef, s.GetName(), wl.Workload.GetName())
 	}
 
 	if err := a.rawClient.Update(ctx, &s); err != nil {
-		return errors.Wrapf(err, errFmtApplyScope, s.GetAPIVersion(), s.GetKind(), s.GetName())
+		return "", errors.Wrapf(err, errFmtApplyScope, s.GetAPIVersion(), s.GetKind(), s.GetName())
 	}
 
-	return nil
+	return "", nil
+}
+
+// overlappingScope returns the name of another scope of the same kind in the
+// namespace that already references the workload, if any. Scopes record their
+// member workloads, so this covers workloads joined by any
+// ApplicationConfiguration.
+func (a *workloads) overlappingScope(ctx context.Context, s unstructured.Unstructured, scopeDefinition *v1alpha2.ScopeDefinition,
+	workloadRef runtimev1alpha1.TypedReference) (string, error) {
+	scopes := &unstructured.UnstructuredList{}
+	scopes.SetAPIVersion(s.GetAPIVersion())
+	scopes.SetKind(s.GetKind())
+	if err := a.rawClient.List(ctx, scopes, client.InNamespace(s.GetNamespace())); err != nil {
+		return "", errors.Wrapf(err, errFmtListScopes, s.GetAPIVersion(), s.GetKind())
+	}
+	for _, other := range scopes.Items {
+		if other.GetName() == s.GetName() {
+			continue
+		}
+		value, err := fieldpath.Pave(other.UnstructuredContent()).GetValue(scopeDefinition.Spec.WorkloadRefsPath)
+		if err != nil {
+			// the other scope has no workloadRefs yet
+			continue
+		}
+		refs, _ := value.([]interface{})
+		for _, item := range refs {
+			ref, _ := item.(map[string]interface{})
+			if (workloadRef.APIVersion == ref["apiVersion"]) &&
+				(workloadRef.Kind == ref["kind"]) &&
+				(workloadRef.Name == ref["name"]) {
+				return other.GetName(), nil
+			}
+		}
+	}
+	return "", nil
 }
 
 func (a *workloads) applyScopeRemoval(ctx context.Context, namespace string, wr runtimev1alpha1.TypedReference, s v1alpha2.WorkloadScope) error {
@@ -395,7 +470,7 @@ func (a *workloads) applyScopeRemoval(ctx context.Context, namespace string, wr
 		return errors.Wrapf(err, errFmtApplyScope, s.Reference.APIVersion, s.Reference.Kind, s.Reference.Name)
 	}
 
-	scopeDefinition, err := util.FetchScopeDefinition(ctx, a.rawClient, a.dm, &scopeObject)
+	scopeDefinition, err := util.FetchScopeDefinition(ctx, a.rawClient, a.dm, namespace, &scopeObject)
 	if err != nil {
 		return errors.Wrapf(err, errFmtGetScopeDefinition, scopeObject.GetAPIVersion(), scopeObject.GetKind(), scopeObject.GetName())
 	}
@@ -28,7 +28,9 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -131,6 +133,7 @@ func TestApplyWorkloads(t *testing.T) {
 		rawClient      client.Client
 		args           args
 		want           error
+		wantConditions [][]runtimev1alpha1.Condition
 	}{
 		"ApplyWorkloadError": {
 			reason: "Errors applying a workload should be reflected as a status condition",
@@ -187,6 +190,9 @@ func TestApplyWorkloads(t *testing.T) {
 			updatingClient: resource.ApplyFn(func(_ context.Context, o runtime.Object, _ ...resource.ApplyOption) error { return nil }),
 			rawClient: &test.MockClient{
 				MockGet: func(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+					if _, ok := obj.(*v1alpha2.NamespacedScopeDefinition); ok {
+						return kerrors.NewNotFound(v1alpha2.SchemeGroupVersion.WithResource("namespacedscopedefinitions").GroupResource(), key.Name)
+					}
 					if scopeDef, ok := obj.(*v1alpha2.ScopeDefinition); ok {
 						*scopeDef = scopeDefinition
 						return nil
@@ -196,6 +202,7 @@ func TestApplyWorkloads(t *testing.T) {
 				MockUpdate: func(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
 					return nil
 				},
+				MockList: test.NewMockListFn(nil),
 			},
 			args: args{
 				w: []Workload{{
@@ -223,12 +230,53 @@ func TestApplyWorkloads(t *testing.T) {
 				},
 			},
 		},
+		"ScopeOverlap": {
+			reason:         "A workload does not join two scopes of a kind that does not allow component overlap.",
+			client:         resource.ApplyFn(func(_ context.Context, o runtime.Object, _ ...resource.ApplyOption) error { return nil }),
+			updatingClient: resource.ApplyFn(func(_ context.Context, o runtime.Object, _ ...resource.ApplyOption) error { return nil }),
+			rawClient: &test.MockClient{
+				MockGet: func(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+					if _, ok := obj.(*v1alpha2.NamespacedScopeDefinition); ok {
+						return kerrors.NewNotFound(v1alpha2.SchemeGroupVersion.WithResource("namespacedscopedefinitions").GroupResource(), key.Name)
+					}
+					if scopeDef, ok := obj.(*v1alpha2.ScopeDefinition); ok {
+						*scopeDef = scopeDefinition
+						return nil
+					}
+					return nil
+				},
+				MockUpdate: func(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+					return fmt.Errorf("update is not expected in this test")
+				},
+				MockList: func(_ context.Context, obj runtime.Object, _ ...client.ListOption) error {
+					other := scopeWithRef.DeepCopy()
+					other.SetName("other-scope")
+					l := obj.(*unstructured.UnstructuredList)
+					l.Items = []unstructured.Unstructured{*scope.DeepCopy(), *other}
+					return nil
+				},
+			},
+			args: args{
+				w: []Workload{{
+					Workload: workload,
+					Traits:   []*Trait{{Object: *trait.DeepCopy()}},
+					Scopes:   []unstructured.Unstructured{*scope.DeepCopy()},
+				}},
+				ws: []v1alpha2.WorkloadStatus{},
+			},
+			wantConditions: [][]runtimev1alpha1.Condition{{
+				v1alpha2.ScopeOverlap(fmt.Sprintf(msgFmtScopeOverlap, scope.GetName(), "other-scope", scopeDefinition.GetName())),
+			}},
+		},
 		"SuccessWithScopeNoOp": {
 			reason:         "Scope already has workloadRef.",
 			client:         resource.ApplyFn(func(_ context.Context, o runtime.Object, _ ...resource.ApplyOption) error { return nil }),
 			updatingClient: resource.ApplyFn(func(_ context.Context, o runtime.Object, _ ...resource.ApplyOption) error { return nil }),
 			rawClient: &test.MockClient{
 				MockGet: func(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+					if _, ok := obj.(*v1alpha2.NamespacedScopeDefinition); ok {
+						return kerrors.NewNotFound(v1alpha2.SchemeGroupVersion.WithResource("namespacedscopedefinitions").GroupResource(), key.Name)
+					}
 					if scopeDef, ok := obj.(*v1alpha2.ScopeDefinition); ok {
 						*scopeDef = scopeDefinition
 						return nil
@@ -288,6 +336,9 @@ func TestApplyWorkloads(t *testing.T) {
 
 						return nil
 					}
+					if _, ok := obj.(*v1alpha2.NamespacedScopeDefinition); ok {
+						return kerrors.NewNotFound(v1alpha2.SchemeGroupVersion.WithResource("namespacedscopedefinitions").GroupResource(), key.Name)
+					}
 					if scopeDef, ok := obj.(*v1alpha2.ScopeDefinition); ok {
 						*scopeDef = scopeDefinition
 						return nil
@@ -336,6 +387,14 @@ func TestApplyWorkloads(t *testing.T) {
 			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nw.Apply(...): -want error, +got error:\n%s", tc.reason, diff)
 			}
+			for i, want := range tc.wantConditions {
+				if diff := cmp.Diff(want, tc.args.w[i].Conditions, cmpopts.IgnoreFields(runtimev1alpha1.Condition{}, "LastTransitionTime")); diff != "" {
+					t.Errorf("\n%s\nw.Apply(...): -want conditions, +got conditions:\n%s", tc.reason, diff)
+				}
+				if len(tc.args.w[i].Scopes) != 0 {
+					t.Errorf("\n%s\nw.Apply(...): want no scopes joined, got %d", tc.reason, len(tc.args.w[i].Scopes))
+				}
+			}
 		})
 	}
 }
@@ -443,6 +502,9 @@ func TestFinalizeWorkloadScopes(t *testing.T) {
 
 						return nil
 					}
+					if _, ok := obj.(*v1alpha2.NamespacedScopeDefinition); ok {
+						return kerrors.NewNotFound(v1alpha2.SchemeGroupVersion.WithResource("namespacedscopedefinitions").GroupResource(), key.Name)
+					}
 					if scopeDef, ok := obj.(*v1alpha2.ScopeDefinition); ok {
 						*scopeDef = scopeDefinition
 						return nil
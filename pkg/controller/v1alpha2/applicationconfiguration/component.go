@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
-	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -17,15 +17,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
 	util "github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
 )
 
-// ControllerRevisionComponentLabel indicate which component the revision belong to
-// This label is to filter revision by client api
-const ControllerRevisionComponentLabel = "controller.oam.dev/component"
-
 // ComponentHandler will watch component change and generate Revision automatically.
 type ComponentHandler struct {
 	Client        client.Client
@@ -121,7 +120,7 @@ func (c *ComponentHandler) IsRevisionDiff(mt metav1.Object, curComp *v1alpha2.Co
 		return true, oldRev.Revision
 	}
 
-	if reflect.DeepEqual(curComp.Spec, oldComp.Spec) {
+	if reflect.DeepEqual(util.RevisionSpec(curComp.Spec), util.RevisionSpec(oldComp.Spec)) {
 		return false, oldRev.Revision
 	}
 	return true, oldRev.Revision
@@ -140,16 +139,29 @@ func (c *ComponentHandler) createControllerRevision(mt metav1.Object, obj runtim
 		// No difference, no need to create new revision.
 		return false
 	}
-	nextRevision := curRevision + 1
-	revisionName := ConstructRevisionName(mt.GetName(), nextRevision)
 
 	if comp.Status.ObservedGeneration != comp.Generation {
 		comp.Status.ObservedGeneration = comp.Generation
 	}
 
+	hash := util.ComputeComponentHash(comp)
+	existing, highest := c.matchingControllerRevision(comp, hash)
+	if existing != nil {
+		return c.reuseControllerRevision(comp, existing)
+	}
+	// the latest revision isn't the highest one if it was reused
+	if highest > curRevision {
+		curRevision = highest
+	}
+	nextRevision := curRevision + 1
+	revisionName := util.ConstructRevisionName(mt.GetName(), nextRevision)
+
 	comp.Status.LatestRevision = &v1alpha2.Revision{
-		Name:     revisionName,
-		Revision: nextRevision,
+		Name:        revisionName,
+		Revision:    nextRevision,
+		ChangeCause: comp.GetAnnotations()[oam.AnnotationChangeCause],
+		Author:      comp.GetAnnotations()[oam.AnnotationAuthor],
+		Hash:        hash,
 	}
 	// set annotation to component
 	revision := appsv1.ControllerRevision{
@@ -166,8 +178,10 @@ func (c *ComponentHandler) createControllerRevision(mt metav1.Object, obj runtim
 				},
 			},
 			Labels: map[string]string{
-				ControllerRevisionComponentLabel: comp.Name,
+				oam.ControllerRevisionComponentLabel: comp.Name,
+				oam.ControllerRevisionHashLabel:      comp.Status.LatestRevision.Hash,
 			},
+			Annotations: revisionAnnotations(comp.Status.LatestRevision),
 		},
 		Revision: nextRevision,
 		Data:     runtime.RawExtension{Object: comp},
@@ -185,14 +199,90 @@ func (c *ComponentHandler) createControllerRevision(mt metav1.Object, obj runtim
 		return false
 	}
 	c.Logger.Info(fmt.Sprintf("ControllerRevision %s created", revisionName))
-	if int64(c.RevisionLimit) < nextRevision {
-		if err := c.cleanupControllerRevision(comp); err != nil {
-			c.Logger.Info(fmt.Sprintf("failed to clean up revisions of Component %v.", err))
+	if err := c.cleanupControllerRevision(comp); err != nil {
+		c.Logger.Info(fmt.Sprintf("failed to clean up revisions of Component %v.", err))
+	}
+	return true
+}
+
+// matchingControllerRevision returns the revision of a component with the same
+// content as the component, if any, and the highest revision number of the
+// component. Revisions are matched by the hash of their content and then
+// compared, so that a hash collision is never mistaken for a match.
+func (c *ComponentHandler) matchingControllerRevision(comp *v1alpha2.Component, hash string) (*appsv1.ControllerRevision, int64) {
+	revisions := &appsv1.ControllerRevisionList{}
+	if err := c.Client.List(context.TODO(), revisions, client.InNamespace(comp.Namespace), client.MatchingLabels{
+		oam.ControllerRevisionComponentLabel: comp.Name,
+	}); err != nil {
+		c.Logger.Info(fmt.Sprintf("error list controllerRevisions %v, will create new revision", err), "componentName", comp.Name)
+		return nil, 0
+	}
+	var match *appsv1.ControllerRevision
+	var highest int64
+	for i := range revisions.Items {
+		revision := &revisions.Items[i]
+		if revision.Revision > highest {
+			highest = revision.Revision
+		}
+		if match != nil || revision.Labels[oam.ControllerRevisionHashLabel] != hash {
+			continue
 		}
+		oldComp, err := util.UnpackRevisionData(revision)
+		if err != nil {
+			continue
+		}
+		if reflect.DeepEqual(util.RevisionSpec(comp.Spec), util.RevisionSpec(oldComp.Spec)) {
+			match = revision
+		}
+	}
+	return match, highest
+}
+
+// reuseControllerRevision makes an existing revision with the same content as
+// the component its latest revision again, e.g. when a component is reverted
+// to an earlier spec. The revision keeps its name, number and creation time,
+// and records when it was reused, so that it counts as recent when revisions
+// are cleaned up.
+func (c *ComponentHandler) reuseControllerRevision(comp *v1alpha2.Component, revision *appsv1.ControllerRevision) bool {
+	comp.Status.LatestRevision = &v1alpha2.Revision{
+		Name:        revision.Name,
+		Revision:    revision.Revision,
+		ChangeCause: comp.GetAnnotations()[oam.AnnotationChangeCause],
+		Author:      comp.GetAnnotations()[oam.AnnotationAuthor],
+		Hash:        revision.Labels[oam.ControllerRevisionHashLabel],
 	}
+	meta.RemoveAnnotations(revision, oam.AnnotationChangeCause, oam.AnnotationAuthor)
+	meta.AddAnnotations(revision, revisionAnnotations(comp.Status.LatestRevision))
+	meta.AddAnnotations(revision, map[string]string{oam.AnnotationReusedAt: time.Now().UTC().Format(time.RFC3339)})
+
+	if err := c.Client.Update(context.TODO(), revision); err != nil {
+		c.Logger.Info(fmt.Sprintf("error update controllerRevision %s %v", revision.Name, err), "componentName", comp.Name)
+		return false
+	}
+	if err := c.Client.Status().Update(context.Background(), comp); err != nil {
+		c.Logger.Info(fmt.Sprintf("update component status latestRevision %s err %v", revision.Name, err), "componentName", comp.Name)
+		return false
+	}
+	c.Logger.Info(fmt.Sprintf("ControllerRevision %s reused", revision.Name))
 	return true
 }
 
+// revisionAnnotations returns the annotations recording the change-cause and
+// author of a component revision.
+func revisionAnnotations(rev *v1alpha2.Revision) map[string]string {
+	annotations := make(map[string]string)
+	if rev.ChangeCause != "" {
+		annotations[oam.AnnotationChangeCause] = rev.ChangeCause
+	}
+	if rev.Author != "" {
+		annotations[oam.AnnotationAuthor] = rev.Author
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+	return annotations
+}
+
 // get sorted controllerRevisions, prepare to delete controllerRevisions
 func sortedControllerRevision(appConfigs []v1alpha2.ApplicationConfiguration, revisions []appsv1.ControllerRevision,
 	revisionLimit int) (sortedRevisions []appsv1.ControllerRevision, toKill int, liveHashes map[string]bool) {
@@ -206,25 +296,41 @@ func sortedControllerRevision(appConfigs []v1alpha2.ApplicationConfiguration, re
 				liveHashes[component.RevisionName] = true
 			}
 		}
+		// revisions backing running workloads, including the ones of
+		// revision enabled workloads that are still being rolled out
+		for _, w := range appConfig.Status.Workloads {
+			if w.ComponentRevisionName != "" {
+				liveHashes[w.ComponentRevisionName] = true
+			}
+		}
+		for _, w := range appConfig.Status.HistoryWorkloads {
+			liveHashes[w.Revision] = true
+		}
 	}
 
-	toKeep := revisionLimit + len(liveHashes)
-	toKill = len(sortedRevisions) - toKeep
-	if toKill <= 0 {
+	live := 0
+	for _, revision := range sortedRevisions {
+		if liveHashes[revision.GetName()] {
+			live++
+		}
+	}
+	toKill = len(sortedRevisions) - revisionLimit - live
+	if toKill < 0 {
 		toKill = 0
-		return
 	}
-	// Clean up old revisions from smallest to highest revision (from oldest to newest)
-	sort.Sort(historiesByRevision(sortedRevisions))
+	// Clean up old revisions from least to most recently used
+	sort.Sort(historiesByRecency(sortedRevisions))
 
 	return
 }
 
-// clean revisions when over limits
+// clean revisions when over limits or not used within the max age of the
+// revision retention policy of the component, least recently used first. The
+// latest revision and the revisions in use are always kept.
 func (c *ComponentHandler) cleanupControllerRevision(curComp *v1alpha2.Component) error {
 	labels := &metav1.LabelSelector{
 		MatchLabels: map[string]string{
-			ControllerRevisionComponentLabel: curComp.Name,
+			oam.ControllerRevisionComponentLabel: curComp.Name,
 		},
 	}
 	selector, err := metav1.LabelSelectorAsSelector(labels)
@@ -235,28 +341,44 @@ func (c *ComponentHandler) cleanupControllerRevision(curComp *v1alpha2.Component
 	// List and Get Object, controller-runtime will create Informer cache
 	// and will get objects from cache
 	revisions := &appsv1.ControllerRevisionList{}
-	if err := c.Client.List(context.TODO(), revisions, &client.ListOptions{LabelSelector: selector}); err != nil {
+	if err := c.Client.List(context.TODO(), revisions, client.InNamespace(curComp.Namespace),
+		&client.ListOptions{LabelSelector: selector}); err != nil {
 		return err
 	}
 
 	// Get appConfigs and workloads filter controllerRevision used
 	appConfigs := &v1alpha2.ApplicationConfigurationList{}
-	if err := c.Client.List(context.Background(), appConfigs); err != nil {
+	if err := c.Client.List(context.Background(), appConfigs, client.InNamespace(curComp.Namespace)); err != nil {
 		return err
 	}
 
+	revisionLimit := c.RevisionLimit
+	var expiry time.Time
+	if policy := curComp.Spec.RevisionRetention; policy != nil {
+		if policy.MaxCount != nil {
+			revisionLimit = int(*policy.MaxCount)
+		}
+		if policy.MaxAge != nil {
+			expiry = time.Now().Add(-policy.MaxAge.Duration)
+		}
+	}
+
 	// get sorted revisions
-	controllerRevisions, toKill, liveHashes := sortedControllerRevision(appConfigs.Items, revisions.Items, c.RevisionLimit)
+	controllerRevisions, toKill, liveHashes := sortedControllerRevision(appConfigs.Items, revisions.Items, revisionLimit)
+	if curComp.Status.LatestRevision != nil {
+		liveHashes[curComp.Status.LatestRevision.Name] = true
+	}
 	for _, revision := range controllerRevisions {
-		if toKill <= 0 {
-			break
-		}
 		if hash := revision.GetName(); liveHashes[hash] {
 			continue
 		}
+		expired := !expiry.IsZero() && lastUsed(revision).Before(expiry)
+		if toKill <= 0 && !expired {
+			continue
+		}
 		// Clean up
 		revisionToClean := revision
-		if err := c.Client.Delete(context.TODO(), &revisionToClean); err != nil {
+		if err := c.Client.Delete(context.TODO(), &revisionToClean); resource.IgnoreNotFound(err) != nil {
 			return err
 		}
 		c.Logger.Info(fmt.Sprintf("ControllerRevision %s deleted", revision.Name))
@@ -265,18 +387,6 @@ func (c *ComponentHandler) cleanupControllerRevision(curComp *v1alpha2.Component
 	return nil
 }
 
-// ConstructRevisionName will generate revisionName from componentName
-// will be <componentName>-v<RevisionNumber>, for example: comp-v1
-func ConstructRevisionName(componentName string, revision int64) string {
-	return strings.Join([]string{componentName, fmt.Sprintf("v%d", revision)}, "-")
-}
-
-// ExtractComponentName will extract componentName from revisionName
-func ExtractComponentName(revisionName string) string {
-	splits := strings.Split(revisionName, "-")
-	return strings.Join(splits[0:len(splits)-1], "-")
-}
-
 // historiesByRevision sort controllerRevision by revision
 type historiesByRevision []appsv1.ControllerRevision
 
@@ -285,3 +395,26 @@ func (h historiesByRevision) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
 func (h historiesByRevision) Less(i, j int) bool {
 	return h[i].Revision < h[j].Revision
 }
+
+// historiesByRecency sorts controllerRevisions from least to most recently
+// used, and by revision if they were used at the same time
+type historiesByRecency []appsv1.ControllerRevision
+
+func (h historiesByRecency) Len() int      { return len(h) }
+func (h historiesByRecency) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h historiesByRecency) Less(i, j int) bool {
+	ti, tj := lastUsed(h[i]), lastUsed(h[j])
+	if !ti.Equal(tj) {
+		return ti.Before(tj)
+	}
+	return h[i].Revision < h[j].Revision
+}
+
+// lastUsed returns when a revision of a component was created or, if it was
+// reused since, when it was last reused.
+func lastUsed(revision appsv1.ControllerRevision) time.Time {
+	if t, err := time.Parse(time.RFC3339, revision.GetAnnotations()[oam.AnnotationReusedAt]); err == nil {
+		return t
+	}
+	return revision.GetCreationTimestamp().Time
+}
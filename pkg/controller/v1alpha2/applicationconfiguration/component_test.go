@@ -2,26 +2,35 @@ package applicationconfiguration
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/apps/v1"
 	v12 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
 )
 
 func TestComponentHandler(t *testing.T) {
@@ -92,6 +101,16 @@ func TestComponentHandler(t *testing.T) {
 				}
 				return nil
 			}),
+			MockUpdate: test.NewMockUpdateFn(nil, func(obj runtime.Object) error {
+				if robj, ok := obj.(*appsv1.ControllerRevision); ok {
+					for i := range createdRevisions {
+						if createdRevisions[i].Name == robj.Name {
+							robj.DeepCopyInto(&createdRevisions[i])
+						}
+					}
+				}
+				return nil
+			}),
 			MockDelete: test.NewMockDeleteFn(nil, func(obj runtime.Object) error {
 				if robj, ok := obj.(*appsv1.ControllerRevision); ok {
 					newRevisions := []appsv1.ControllerRevision{}
@@ -146,7 +165,8 @@ func TestComponentHandler(t *testing.T) {
 
 	// ============ Test Update Event Start===================
 	comp2 := &v1alpha2.Component{
-		ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "comp1"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "comp1", Annotations: map[string]string{
+			oam.AnnotationChangeCause: "bump nginx", oam.AnnotationAuthor: "alice"}},
 		// change image
 		Spec: v1alpha2.ComponentSpec{Workload: runtime.RawExtension{Object: &v1.Deployment{Spec: v1.DeploymentSpec{Template: v12.PodTemplateSpec{Spec: v12.PodSpec{Containers: []v12.Container{{Image: "nginx:v2"}}}}}}}},
 	}
@@ -179,6 +199,12 @@ func TestComponentHandler(t *testing.T) {
 			// check component's status saved in corresponding controllerRevision
 			assert.Equal(t, gotComp.Status.LatestRevision.Name, v.Name)
 			assert.Equal(t, gotComp.Status.LatestRevision.Revision, v.Revision)
+			// check change-cause, author and hash recorded in the revision
+			assert.Equal(t, "bump nginx", gotComp.Status.LatestRevision.ChangeCause)
+			assert.Equal(t, "alice", gotComp.Status.LatestRevision.Author)
+			assert.Equal(t, util.ComputeComponentHash(comp2), gotComp.Status.LatestRevision.Hash)
+			assert.Equal(t, map[string]string{oam.AnnotationChangeCause: "bump nginx", oam.AnnotationAuthor: "alice"}, v.Annotations)
+			assert.Equal(t, gotComp.Status.LatestRevision.Hash, v.Labels[oam.ControllerRevisionHashLabel])
 		}
 	}
 	q.Done(item)
@@ -218,22 +244,151 @@ func TestComponentHandler(t *testing.T) {
 	err = instance.Client.List(context.TODO(), revisions)
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(revisions.Items), "Expected has two revisions")
-	assert.Equal(t, "comp1", revisions.Items[0].Labels[ControllerRevisionComponentLabel],
-		fmt.Sprintf("Expected revision has label %s: comp1", ControllerRevisionComponentLabel))
+	assert.Equal(t, "comp1", revisions.Items[0].Labels[oam.ControllerRevisionComponentLabel],
+		fmt.Sprintf("Expected revision has label %s: comp1", oam.ControllerRevisionComponentLabel))
+
+	// test revert to the spec of an existing revision
+	comp5 := &v1alpha2.Component{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "comp1", Annotations: map[string]string{oam.AnnotationChangeCause: "revert"}},
+		Spec:       comp2.Spec,
+	}
+	curComp.Status.DeepCopyInto(&comp5.Status)
+	updateEvt = event.UpdateEvent{
+		ObjectOld: comp4,
+		MetaOld:   comp4.GetObjectMeta(),
+		ObjectNew: comp5,
+		MetaNew:   comp5.GetObjectMeta(),
+	}
+	instance.Update(updateEvt, q)
+	revisions = &appsv1.ControllerRevisionList{}
+	err = instance.Client.List(context.TODO(), revisions)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(revisions.Items), "Expected the existing revision to be reused")
+	assert.Equal(t, "comp1-v2", curComp.Status.LatestRevision.Name)
+	assert.Equal(t, int64(2), curComp.Status.LatestRevision.Revision)
+	assert.Equal(t, "revert", curComp.Status.LatestRevision.ChangeCause)
+	for _, v := range revisions.Items {
+		if v.Name == "comp1-v2" {
+			assert.Equal(t, int64(2), v.Revision, "Expected the reused revision to keep its number")
+			assert.Equal(t, "revert", v.Annotations[oam.AnnotationChangeCause])
+			assert.NotEmpty(t, v.Annotations[oam.AnnotationReusedAt], "Expected the reused revision to record when it was reused")
+		}
+	}
 	// ============ Test Revisions End ===================
 }
 
-func TestConstructExtract(t *testing.T) {
-	tests := []string{"tam1", "test-comp", "xx", "tt-x-x-c"}
-	revisionNum := []int64{1, 5, 10, 100000}
-	for idx, componentName := range tests {
-		t.Run(fmt.Sprintf("tests %d for component[%s]", idx, componentName), func(t *testing.T) {
-			revisionName := ConstructRevisionName(componentName, revisionNum[idx])
-			got := ExtractComponentName(revisionName)
-			if got != componentName {
-				t.Errorf("want to get %s from %s but got %s", componentName, revisionName, got)
-			}
-		})
+func TestReuseControllerRevision(t *testing.T) {
+	revisions := make(map[string]appsv1.ControllerRevision)
+	var comp v1alpha2.Component
+	h := &ComponentHandler{
+		Client: &test.MockClient{
+			MockGet: func(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+				rev, ok := revisions[key.Name]
+				if !ok {
+					return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
+				}
+				rev.DeepCopyInto(obj.(*appsv1.ControllerRevision))
+				return nil
+			},
+			MockList: func(_ context.Context, obj runtime.Object, _ ...client.ListOption) error {
+				if l, ok := obj.(*appsv1.ControllerRevisionList); ok {
+					for _, rev := range revisions {
+						l.Items = append(l.Items, *rev.DeepCopy())
+					}
+				}
+				return nil
+			},
+			MockCreate: func(_ context.Context, obj runtime.Object, _ ...client.CreateOption) error {
+				rev := obj.(*appsv1.ControllerRevision)
+				if _, ok := revisions[rev.Name]; ok {
+					return kerrors.NewAlreadyExists(schema.GroupResource{}, rev.Name)
+				}
+				// revisions are read back as they are stored
+				raw, _ := json.Marshal(rev.Data.Object)
+				rev = rev.DeepCopy()
+				rev.Data = runtime.RawExtension{Raw: raw}
+				revisions[rev.Name] = *rev
+				return nil
+			},
+			MockUpdate: func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+				rev := obj.(*appsv1.ControllerRevision)
+				revisions[rev.Name] = *rev.DeepCopy()
+				return nil
+			},
+			MockStatusUpdate: func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+				obj.(*v1alpha2.Component).DeepCopyInto(&comp)
+				return nil
+			},
+			MockDelete: test.NewMockDeleteFn(nil),
+		},
+		Logger:        logging.NewNopLogger(),
+		RevisionLimit: 10,
+	}
+	update := func(image string) {
+		c := &v1alpha2.Component{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "comp"},
+			Spec: v1alpha2.ComponentSpec{Workload: runtime.RawExtension{Raw: []byte(
+				`{"apiVersion":"apps/v1","kind":"Deployment","spec":{"image":"` + image + `"}}`)}},
+		}
+		comp.Status.DeepCopyInto(&c.Status)
+		h.createControllerRevision(c, c)
+	}
+
+	type revision struct {
+		Name     string
+		Revision int64
+		Reused   bool
+	}
+	steps := []struct {
+		image         string
+		wantLatest    revision
+		wantRevisions []revision
+	}{
+		{
+			image:         "nginx:1.18",
+			wantLatest:    revision{"comp-v1", 1, false},
+			wantRevisions: []revision{{"comp-v1", 1, false}},
+		},
+		{
+			image:         "nginx:1.19",
+			wantLatest:    revision{"comp-v2", 2, false},
+			wantRevisions: []revision{{"comp-v1", 1, false}, {"comp-v2", 2, false}},
+		},
+		{
+			// revert
+			image:         "nginx:1.18",
+			wantLatest:    revision{"comp-v1", 1, true},
+			wantRevisions: []revision{{"comp-v1", 1, true}, {"comp-v2", 2, false}},
+		},
+		{
+			// change
+			image:         "nginx:1.20",
+			wantLatest:    revision{"comp-v3", 3, false},
+			wantRevisions: []revision{{"comp-v1", 1, true}, {"comp-v2", 2, false}, {"comp-v3", 3, false}},
+		},
+		{
+			// revert again
+			image:         "nginx:1.19",
+			wantLatest:    revision{"comp-v2", 2, true},
+			wantRevisions: []revision{{"comp-v1", 1, true}, {"comp-v2", 2, true}, {"comp-v3", 3, false}},
+		},
+	}
+	for i, step := range steps {
+		update(step.image)
+		got := revision{Name: comp.Status.LatestRevision.Name, Revision: comp.Status.LatestRevision.Revision}
+		_, got.Reused = revisions[got.Name].Annotations[oam.AnnotationReusedAt]
+		if diff := cmp.Diff(step.wantLatest, got); diff != "" {
+			t.Errorf("step %d: -want latest revision, +got latest revision:\n%s", i, diff)
+		}
+		gotRevisions := make([]revision, 0, len(revisions))
+		for name, rev := range revisions {
+			_, reused := rev.GetAnnotations()[oam.AnnotationReusedAt]
+			gotRevisions = append(gotRevisions, revision{Name: name, Revision: rev.Revision, Reused: reused})
+		}
+		sort.Slice(gotRevisions, func(i, j int) bool { return gotRevisions[i].Revision < gotRevisions[j].Revision })
+		if diff := cmp.Diff(step.wantRevisions, gotRevisions); diff != "" {
+			t.Errorf("step %d: -want revisions, +got revisions:\n%s", i, diff)
+		}
 	}
 }
 
@@ -310,3 +465,147 @@ func TestSortedControllerRevision(t *testing.T) {
 	assert.Equal(t, 0, toKill, "Needn't to delete")
 	assert.Equal(t, 1, len(liveHashes), "LiveHashes worked")
 }
+
+func TestCleanupControllerRevision(t *testing.T) {
+	now := time.Now()
+	componentRevision := func(revision int64, age time.Duration) appsv1.ControllerRevision {
+		return appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              util.ConstructRevisionName("comp", revision),
+				Namespace:         "ns",
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			},
+			Revision: revision,
+		}
+	}
+	revisions := []appsv1.ControllerRevision{
+		componentRevision(5, time.Hour),
+		componentRevision(1, 5*time.Hour),
+		componentRevision(3, 3*time.Hour),
+		componentRevision(2, 4*time.Hour),
+		componentRevision(4, 2*time.Hour),
+	}
+	running := v1alpha2.ApplicationConfiguration{
+		Status: v1alpha2.ApplicationConfigurationStatus{
+			Workloads:        []v1alpha2.WorkloadStatus{{ComponentRevisionName: "comp-v2"}},
+			HistoryWorkloads: []v1alpha2.HistoryWorkload{{Revision: "comp-v1"}},
+		},
+	}
+	count := func(n int32) *int32 { return &n }
+
+	reused := componentRevision(1, 5*time.Hour)
+	reused.SetAnnotations(map[string]string{oam.AnnotationReusedAt: now.Add(-30 * time.Minute).UTC().Format(time.RFC3339)})
+
+	cases := map[string]struct {
+		appConfigs  []v1alpha2.ApplicationConfiguration
+		revisions   []appsv1.ControllerRevision
+		retention   *v1alpha2.RevisionRetentionPolicy
+		wantDeleted []string
+	}{
+		"RevisionLimit": {
+			wantDeleted: []string{"comp-v1", "comp-v2"},
+		},
+		"MaxCount": {
+			retention:   &v1alpha2.RevisionRetentionPolicy{MaxCount: count(1)},
+			wantDeleted: []string{"comp-v1", "comp-v2", "comp-v3", "comp-v4"},
+		},
+		"MaxCountKeepsLatest": {
+			retention:   &v1alpha2.RevisionRetentionPolicy{MaxCount: count(0)},
+			wantDeleted: []string{"comp-v1", "comp-v2", "comp-v3", "comp-v4"},
+		},
+		"MaxAge": {
+			retention:   &v1alpha2.RevisionRetentionPolicy{MaxCount: count(10), MaxAge: &metav1.Duration{Duration: 150 * time.Minute}},
+			wantDeleted: []string{"comp-v1", "comp-v2", "comp-v3"},
+		},
+		"KeepsRunning": {
+			appConfigs:  []v1alpha2.ApplicationConfiguration{running},
+			retention:   &v1alpha2.RevisionRetentionPolicy{MaxCount: count(0), MaxAge: &metav1.Duration{Duration: time.Minute}},
+			wantDeleted: []string{"comp-v3", "comp-v4"},
+		},
+		"KeepsReused": {
+			revisions:   append([]appsv1.ControllerRevision{revisions[0], reused}, revisions[2:]...),
+			wantDeleted: []string{"comp-v2", "comp-v3"},
+		},
+		"MaxAgeKeepsReused": {
+			revisions:   append([]appsv1.ControllerRevision{revisions[0], reused}, revisions[2:]...),
+			retention:   &v1alpha2.RevisionRetentionPolicy{MaxCount: count(10), MaxAge: &metav1.Duration{Duration: 150 * time.Minute}},
+			wantDeleted: []string{"comp-v2", "comp-v3"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var deleted []string
+			h := &ComponentHandler{
+				Client: &test.MockClient{
+					MockList: test.NewMockListFn(nil, func(obj runtime.Object) error {
+						switch l := obj.(type) {
+						case *v1alpha2.ApplicationConfigurationList:
+							l.Items = tc.appConfigs
+						case *appsv1.ControllerRevisionList:
+							l.Items = append([]appsv1.ControllerRevision{}, revisions...)
+							if tc.revisions != nil {
+								l.Items = append([]appsv1.ControllerRevision{}, tc.revisions...)
+							}
+						}
+						return nil
+					}),
+					MockDelete: test.NewMockDeleteFn(nil, func(obj runtime.Object) error {
+						deleted = append(deleted, obj.(*appsv1.ControllerRevision).GetName())
+						return nil
+					}),
+				},
+				Logger:        logging.NewNopLogger(),
+				RevisionLimit: 3,
+			}
+			comp := &v1alpha2.Component{
+				ObjectMeta: metav1.ObjectMeta{Name: "comp", Namespace: "ns"},
+				Spec:       v1alpha2.ComponentSpec{RevisionRetention: tc.retention},
+				Status:     v1alpha2.ComponentStatus{LatestRevision: &v1alpha2.Revision{Name: "comp-v5", Revision: 5}},
+			}
+			if err := h.cleanupControllerRevision(comp); err != nil {
+				t.Fatalf("cleanupControllerRevision(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.wantDeleted, deleted); diff != "" {
+				t.Errorf("cleanupControllerRevision(...): -want deleted, +got deleted:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRevisionCollector(t *testing.T) {
+	var deleted []string
+	h := &ComponentHandler{
+		Client: &test.MockClient{
+			MockList: test.NewMockListFn(nil, func(obj runtime.Object) error {
+				switch l := obj.(type) {
+				case *v1alpha2.ComponentList:
+					l.Items = []v1alpha2.Component{{
+						ObjectMeta: metav1.ObjectMeta{Name: "comp", Namespace: "ns"},
+						Spec: v1alpha2.ComponentSpec{RevisionRetention: &v1alpha2.RevisionRetentionPolicy{
+							MaxAge: &metav1.Duration{Duration: time.Hour},
+						}},
+						Status: v1alpha2.ComponentStatus{LatestRevision: &v1alpha2.Revision{Name: "comp-v2", Revision: 2}},
+					}}
+				case *appsv1.ControllerRevisionList:
+					l.Items = []appsv1.ControllerRevision{
+						{ObjectMeta: metav1.ObjectMeta{Name: "comp-v1", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))}, Revision: 1},
+						{ObjectMeta: metav1.ObjectMeta{Name: "comp-v2", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))}, Revision: 2},
+					}
+				}
+				return nil
+			}),
+			MockDelete: test.NewMockDeleteFn(nil, func(obj runtime.Object) error {
+				deleted = append(deleted, obj.(*appsv1.ControllerRevision).GetName())
+				return nil
+			}),
+		},
+		Logger:        logging.NewNopLogger(),
+		RevisionLimit: 50,
+	}
+	if err := NewRevisionCollector(h, time.Minute).Collect(context.Background()); err != nil {
+		t.Fatalf("Collect(...): %s", err)
+	}
+	if diff := cmp.Diff([]string{"comp-v1"}, deleted); diff != "" {
+		t.Errorf("Collect(...): -want deleted, +got deleted:\n%s", diff)
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationconfiguration
+
+import (
+	"context"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
+)
+
+const (
+	errFmtOrphan      = "cannot orphan %s %q"
+	errFmtGetRetained = "cannot get retained %s %q"
+	errFmtDelRetained = "cannot delete retained %s %q"
+	errFmtRetained    = "%s %q is retained until its " + oam.AnnotationRetain + " annotation is removed"
+)
+
+// deletionPolicy returns the deletion policy specified for a workload or
+// trait, or else the one of its definition. Resources are deleted by default.
+func deletionPolicy(specified, def v1alpha2.DeletionPolicy) v1alpha2.DeletionPolicy {
+	if specified != "" {
+		return specified
+	}
+	if def != "" {
+		return def
+	}
+	return v1alpha2.DeletionPolicyDelete
+}
+
+// deletionPolicyOf returns the deletion policy the referenced workload or trait
+// was applied with according to the supplied workload statuses.
+func deletionPolicyOf(ws []v1alpha2.WorkloadStatus, ref runtimev1alpha1.TypedReference) v1alpha2.DeletionPolicy {
+	for _, s := range ws {
+		if util.SameResource(s.Reference, ref) {
+			return deletionPolicy(s.DeletionPolicy, "")
+		}
+		for _, ts := range s.Traits {
+			if util.SameResource(ts.Reference, ref) {
+				return deletionPolicy(ts.DeletionPolicy, "")
+			}
+		}
+	}
+	return v1alpha2.DeletionPolicyDelete
+}
+
+// retained tells whether a resource with the Retain deletion policy must still
+// be kept.
+func retained(u *unstructured.Unstructured) bool {
+	_, ok := u.GetAnnotations()[oam.AnnotationRetain]
+	return ok
+}
+
+// release a workload or trait that was removed from the ApplicationConfiguration
+// according to its deletion policy. Resources that are retained are recorded
+// in the status of the ApplicationConfiguration, so they can be deleted once
+// their retain annotation is removed.
+func (r *OAMApplicationReconciler) release(ctx context.Context, ac *v1alpha2.ApplicationConfiguration,
+	u *unstructured.Unstructured, policy v1alpha2.DeletionPolicy) error {
+	switch policy {
+	case v1alpha2.DeletionPolicyOrphan:
+		return orphan(ctx, r.client, u, ac.GetUID())
+	case v1alpha2.DeletionPolicyRetain:
+		if err := r.client.Get(ctx, types.NamespacedName{Namespace: u.GetNamespace(), Name: u.GetName()}, u); err != nil {
+			return resource.IgnoreNotFound(err)
+		}
+		if retained(u) {
+			ref := util.ReferenceTo(u)
+			for _, rr := range ac.Status.RetainedResources {
+				if util.SameResource(rr, ref) {
+					return nil
+				}
+			}
+			ac.Status.RetainedResources = append(ac.Status.RetainedResources, ref)
+			return nil
+		}
+	}
+	return resource.IgnoreNotFound(r.client.Delete(ctx, u))
+}
+
+// collectRetained deletes the retained resources whose retain annotation was
+// removed. Retained resources that are gone, or that are part of the
+// ApplicationConfiguration again, are no longer tracked.
+func (r *OAMApplicationReconciler) collectRetained(ctx context.Context, ac *v1alpha2.ApplicationConfiguration, workloads []Workload) error {
+	if len(ac.Status.RetainedResources) == 0 {
+		return nil
+	}
+	applied := make(map[runtimev1alpha1.TypedReference]bool)
+	for _, w := range workloads {
+		applied[util.ReferenceTo(w.Workload)] = true
+		for _, t := range w.Traits {
+			applied[util.ReferenceTo(&t.Object)] = true
+		}
+	}
+	kept := make([]runtimev1alpha1.TypedReference, 0, len(ac.Status.RetainedResources))
+	for _, ref := range ac.Status.RetainedResources {
+		if applied[ref] {
+			continue
+		}
+		u := util.ObjectOf(ac.GetNamespace(), ref)
+		err := r.client.Get(ctx, types.NamespacedName{Namespace: u.GetNamespace(), Name: u.GetName()}, u)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, errFmtGetRetained, ref.Kind, ref.Name)
+		}
+		if retained(u) {
+			kept = append(kept, ref)
+			continue
+		}
+		if err := r.client.Delete(ctx, u); resource.IgnoreNotFound(err) != nil {
+			return errors.Wrapf(err, errFmtDelRetained, ref.Kind, ref.Name)
+		}
+	}
+	ac.Status.RetainedResources = kept
+	return nil
+}
+
+// orphan removes the owner reference to the owner from a resource, and marks
+// it as orphaned so that it isn't mistaken for a leftover of its owner.
+func orphan(ctx context.Context, c client.Client, u *unstructured.Unstructured, owner types.UID) error {
+	if err := c.Get(ctx, types.NamespacedName{Namespace: u.GetNamespace(), Name: u.GetName()}, u); err != nil {
+		return errors.Wrapf(resource.IgnoreNotFound(err), errFmtOrphan, u.GetKind(), u.GetName())
+	}
+	refs := u.GetOwnerReferences()
+	kept := make([]metav1.OwnerReference, 0, len(refs))
+	for _, ref := range refs {
+		if ref.UID != owner {
+			kept = append(kept, ref)
+		}
+	}
+	if len(kept) == len(refs) {
+		return nil
+	}
+	u.SetOwnerReferences(kept)
+	meta.AddAnnotations(u, map[string]string{oam.AnnotationOrphaned: "true"})
+	return errors.Wrapf(c.Update(ctx, u), errFmtOrphan, u.GetKind(), u.GetName())
+}
+
+// releaseAll releases the workloads and traits of an ApplicationConfiguration
+// that is being deleted according to their deletion policies. Resources to be
+// deleted are left to Kubernetes garbage collection. An error is returned as
+// long as a retained resource still has its retain annotation.
+func releaseAll(ctx context.Context, c client.Client, ac *v1alpha2.ApplicationConfiguration) error {
+	type policyRef struct {
+		ref    runtimev1alpha1.TypedReference
+		policy v1alpha2.DeletionPolicy
+	}
+	refs := make([]policyRef, 0, len(ac.Status.Workloads)+len(ac.Status.RetainedResources))
+	for _, s := range ac.Status.Workloads {
+		refs = append(refs, policyRef{s.Reference, deletionPolicy(s.DeletionPolicy, "")})
+		for _, ts := range s.Traits {
+			refs = append(refs, policyRef{ts.Reference, deletionPolicy(ts.DeletionPolicy, "")})
+		}
+	}
+	for _, ref := range ac.Status.RetainedResources {
+		refs = append(refs, policyRef{ref, v1alpha2.DeletionPolicyRetain})
+	}
+
+	for _, pr := range refs {
+		u := util.ObjectOf(ac.GetNamespace(), pr.ref)
+		switch pr.policy {
+		case v1alpha2.DeletionPolicyOrphan:
+			if err := orphan(ctx, c, u, ac.GetUID()); err != nil {
+				return err
+			}
+		case v1alpha2.DeletionPolicyRetain:
+			err := c.Get(ctx, types.NamespacedName{Namespace: u.GetNamespace(), Name: u.GetName()}, u)
+			if resource.IgnoreNotFound(err) != nil {
+				return errors.Wrapf(err, errFmtGetRetained, pr.ref.Kind, pr.ref.Name)
+			}
+			if err == nil && retained(u) {
+				return errors.Errorf(errFmtRetained, pr.ref.Kind, pr.ref.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// hasDeletionPolicy tells whether a workload or trait of the
+// ApplicationConfiguration is not simply deleted with it.
+func hasDeletionPolicy(ac *v1alpha2.ApplicationConfiguration) bool {
+	keep := func(p v1alpha2.DeletionPolicy) bool {
+		return deletionPolicy(p, "") != v1alpha2.DeletionPolicyDelete
+	}
+	for _, acc := range ac.Spec.Components {
+		if keep(acc.DeletionPolicy) {
+			return true
+		}
+	}
+	// policies of traits and policies defaulted from definitions are only
+	// known once rendered
+	for _, s := range ac.Status.Workloads {
+		if keep(s.DeletionPolicy) {
+			return true
+		}
+		for _, ts := range s.Traits {
+			if keep(ts.DeletionPolicy) {
+				return true
+			}
+		}
+	}
+	return len(ac.Status.RetainedResources) > 0
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationconfiguration
+
+import (
+	"context"
+	"testing"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
+)
+
+func TestDeletionPolicy(t *testing.T) {
+	cases := map[string]struct {
+		specified v1alpha2.DeletionPolicy
+		def       v1alpha2.DeletionPolicy
+		want      v1alpha2.DeletionPolicy
+	}{
+		"Default":    {want: v1alpha2.DeletionPolicyDelete},
+		"Definition": {def: v1alpha2.DeletionPolicyRetain, want: v1alpha2.DeletionPolicyRetain},
+		"Specified": {specified: v1alpha2.DeletionPolicyOrphan, def: v1alpha2.DeletionPolicyRetain,
+			want: v1alpha2.DeletionPolicyOrphan},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := deletionPolicy(tc.specified, tc.def); got != tc.want {
+				t.Errorf("deletionPolicy(...): want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+// liveObjects is a fake of the resources of an ApplicationConfiguration that
+// records which of them were updated and deleted.
+type liveObjects struct {
+	objects map[string]*unstructured.Unstructured
+	updated []*unstructured.Unstructured
+	deleted []string
+}
+
+func (l *liveObjects) client() *test.MockClient {
+	return &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+			o, ok := l.objects[key.Name]
+			if !ok {
+				return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
+			}
+			o.DeepCopyInto(obj.(*unstructured.Unstructured))
+			return nil
+		},
+		MockUpdate: func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+			l.updated = append(l.updated, obj.(*unstructured.Unstructured))
+			return nil
+		},
+		MockDelete: func(_ context.Context, obj runtime.Object, _ ...client.DeleteOption) error {
+			l.deleted = append(l.deleted, obj.(*unstructured.Unstructured).GetName())
+			return nil
+		},
+	}
+}
+
+func liveObject(name string, annotations map[string]string, owners ...types.UID) *unstructured.Unstructured {
+	u := util.ObjectOf("ns", runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: name})
+	u.SetAnnotations(annotations)
+	refs := make([]metav1.OwnerReference, 0, len(owners))
+	for _, o := range owners {
+		refs = append(refs, metav1.OwnerReference{APIVersion: "v1", Kind: "Owner", Name: string(o), UID: o})
+	}
+	u.SetOwnerReferences(refs)
+	return u
+}
+
+func TestRelease(t *testing.T) {
+	retain := map[string]string{oam.AnnotationRetain: "true"}
+	ac := &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", UID: "ac"}}
+
+	cases := map[string]struct {
+		live         *unstructured.Unstructured
+		policy       v1alpha2.DeletionPolicy
+		wantOwners   []types.UID
+		wantDeleted  []string
+		wantRetained []runtimev1alpha1.TypedReference
+	}{
+		"Delete": {
+			live:        liveObject("web", nil, "ac"),
+			policy:      v1alpha2.DeletionPolicyDelete,
+			wantDeleted: []string{"web"},
+		},
+		"Orphan": {
+			live:       liveObject("web", nil, "other", "ac"),
+			policy:     v1alpha2.DeletionPolicyOrphan,
+			wantOwners: []types.UID{"other"},
+		},
+		"OrphanGone": {
+			policy: v1alpha2.DeletionPolicyOrphan,
+		},
+		"Retain": {
+			live:         liveObject("web", retain, "ac"),
+			policy:       v1alpha2.DeletionPolicyRetain,
+			wantRetained: []runtimev1alpha1.TypedReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"}},
+		},
+		"RetainAnnotationRemoved": {
+			live:        liveObject("web", nil, "ac"),
+			policy:      v1alpha2.DeletionPolicyRetain,
+			wantDeleted: []string{"web"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := &liveObjects{objects: map[string]*unstructured.Unstructured{}}
+			if tc.live != nil {
+				l.objects[tc.live.GetName()] = tc.live
+			}
+			r := &OAMApplicationReconciler{client: l.client()}
+			ac := ac.DeepCopy()
+			u := util.ObjectOf("ns", runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"})
+			if err := r.release(context.Background(), ac, u, tc.policy); err != nil {
+				t.Fatalf("r.release(...): %s", err)
+			}
+			if tc.wantOwners != nil {
+				if len(l.updated) != 1 {
+					t.Fatalf("r.release(...): want 1 update, got %d", len(l.updated))
+				}
+				var got []types.UID
+				for _, ref := range l.updated[0].GetOwnerReferences() {
+					got = append(got, ref.UID)
+				}
+				if diff := cmp.Diff(tc.wantOwners, got); diff != "" {
+					t.Errorf("r.release(...): -want owners, +got owners:\n%s", diff)
+				}
+				if _, ok := l.updated[0].GetAnnotations()[oam.AnnotationOrphaned]; !ok {
+					t.Errorf("r.release(...): want the %s annotation on the orphaned resource", oam.AnnotationOrphaned)
+				}
+			} else if len(l.updated) != 0 {
+				t.Errorf("r.release(...): want no update, got %d", len(l.updated))
+			}
+			if diff := cmp.Diff(tc.wantDeleted, l.deleted); diff != "" {
+				t.Errorf("r.release(...): -want deleted, +got deleted:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantRetained, ac.Status.RetainedResources); diff != "" {
+				t.Errorf("r.release(...): -want retained, +got retained:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCollectRetained(t *testing.T) {
+	ref := func(name string) runtimev1alpha1.TypedReference {
+		return runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: name}
+	}
+	l := &liveObjects{objects: map[string]*unstructured.Unstructured{
+		"kept":     liveObject("kept", map[string]string{oam.AnnotationRetain: "true"}, "ac"),
+		"released": liveObject("released", nil, "ac"),
+		"readded":  liveObject("readded", map[string]string{oam.AnnotationRetain: "true"}, "ac"),
+	}}
+	ac := &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", UID: "ac"}}
+	ac.Status.RetainedResources = []runtimev1alpha1.TypedReference{ref("kept"), ref("released"), ref("readded"), ref("gone")}
+	workloads := []Workload{{Workload: util.ObjectOf("ns", ref("readded"))}}
+
+	r := &OAMApplicationReconciler{client: l.client()}
+	if err := r.collectRetained(context.Background(), ac, workloads); err != nil {
+		t.Fatalf("r.collectRetained(...): %s", err)
+	}
+	if diff := cmp.Diff([]runtimev1alpha1.TypedReference{ref("kept")}, ac.Status.RetainedResources); diff != "" {
+		t.Errorf("r.collectRetained(...): -want retained, +got retained:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"released"}, l.deleted); diff != "" {
+		t.Errorf("r.collectRetained(...): -want deleted, +got deleted:\n%s", diff)
+	}
+}
+
+func TestReleaseAll(t *testing.T) {
+	retain := map[string]string{oam.AnnotationRetain: "true"}
+	status := v1alpha2.ApplicationConfigurationStatus{Workloads: []v1alpha2.WorkloadStatus{{
+		Reference:      runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+		DeletionPolicy: v1alpha2.DeletionPolicyOrphan,
+		Traits: []v1alpha2.WorkloadTrait{
+			{
+				Reference:      runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "volume"},
+				DeletionPolicy: v1alpha2.DeletionPolicyRetain,
+			},
+			{
+				Reference: runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "route"},
+			},
+		},
+	}}}
+
+	cases := map[string]struct {
+		objects     map[string]*unstructured.Unstructured
+		wantErr     error
+		wantUpdated []string
+	}{
+		"Released": {
+			objects: map[string]*unstructured.Unstructured{
+				"web":    liveObject("web", nil, "ac"),
+				"volume": liveObject("volume", nil, "ac"),
+				"route":  liveObject("route", nil, "ac"),
+			},
+			wantUpdated: []string{"web"},
+		},
+		"StillRetained": {
+			objects: map[string]*unstructured.Unstructured{
+				"web":    liveObject("web", nil, "ac"),
+				"volume": liveObject("volume", retain, "ac"),
+			},
+			wantErr:     errors.Errorf(errFmtRetained, "Deployment", "volume"),
+			wantUpdated: []string{"web"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := &liveObjects{objects: tc.objects}
+			ac := &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", UID: "ac"}, Status: status}
+			err := releaseAll(context.Background(), l.client(), ac)
+			if diff := cmp.Diff(tc.wantErr, err, test.EquateErrors()); diff != "" {
+				t.Errorf("releaseAll(...): -want error, +got error:\n%s", diff)
+			}
+			var updated []string
+			for _, u := range l.updated {
+				updated = append(updated, u.GetName())
+				if len(u.GetOwnerReferences()) != 0 {
+					t.Errorf("releaseAll(...): %s is still owned", u.GetName())
+				}
+			}
+			if diff := cmp.Diff(tc.wantUpdated, updated); diff != "" {
+				t.Errorf("releaseAll(...): -want updated, +got updated:\n%s", diff)
+			}
+			if diff := cmp.Diff([]string(nil), l.deleted, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("releaseAll(...): -want deleted, +got deleted:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationconfiguration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
+)
+
+const (
+	errListRevisions          = "cannot list the revisions of the application configuration"
+	errFmtUnpackRevision      = "cannot unpack revision %q"
+	errFmtCreateRevision      = "cannot create revision %q"
+	errFmtDeleteRevision      = "cannot delete revision %q"
+	errFmtNoRevision          = "application configuration has no revision %q"
+	errFmtGetComponentRevison = "cannot roll back to revision %q: cannot get component revision %q"
+	errRecordRevision         = "cannot record the revision of the application configuration"
+	errRollback               = "cannot roll back the application configuration"
+)
+
+// History event reasons.
+const (
+	reasonRecordedRevision     = "RecordedRevision"
+	reasonCannotRecordRevision = "CannotRecordRevision"
+	reasonRolledBack           = "RolledBack"
+	reasonCannotRollback       = "CannotRollback"
+)
+
+// ConstructAppConfigRevisionName will generate the name of a revision of the
+// history of an ApplicationConfiguration. It will be
+// <appConfigName>-appconfig-r<RevisionNumber>, for example: app-appconfig-r1.
+// Unlike the <componentName>-v<RevisionNumber> revisions of a Component it
+// never ends in -v<RevisionNumber>, so the two can't collide, e.g. for a
+// Component named app-appconfig.
+func ConstructAppConfigRevisionName(appConfigName string, revision int64) string {
+	return fmt.Sprintf("%s-appconfig-r%d", appConfigName, revision)
+}
+
+// appliedSpec returns the spec of an ApplicationConfiguration with each of its
+// components bound to the component revision it was applied with, so that
+// rolling back to it restores the components too.
+func appliedSpec(ac *v1alpha2.ApplicationConfiguration, workloads []Workload) v1alpha2.ApplicationConfigurationSpec {
+	spec := *ac.Spec.DeepCopy()
+	for i := range spec.Components {
+		acc := &spec.Components[i]
+		if acc.RevisionName != "" {
+			continue
+		}
+		for _, w := range workloads {
+			if w.ComponentName == acc.ComponentName && w.ComponentRevisionName != "" {
+				acc.RevisionName = w.ComponentRevisionName
+				acc.ComponentName = ""
+				break
+			}
+		}
+	}
+	return spec
+}
+
+// sameSpec tells whether two specs are the same once serialized, so that the
+// raw workloads and traits they embed are compared regardless of formatting.
+func sameSpec(a, b v1alpha2.ApplicationConfigurationSpec) bool {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ja, jb)
+}
+
+// revisions returns the revisions of the history of an
+// ApplicationConfiguration from oldest to newest.
+func revisions(ctx context.Context, c client.Reader, ac *v1alpha2.ApplicationConfiguration) ([]appsv1.ControllerRevision, error) {
+	l := &appsv1.ControllerRevisionList{}
+	if err := c.List(ctx, l, client.InNamespace(ac.GetNamespace()),
+		client.MatchingLabels{oam.ControllerRevisionAppConfigLabel: ac.GetName()}); err != nil {
+		return nil, errors.Wrap(err, errListRevisions)
+	}
+	revs := make([]appsv1.ControllerRevision, 0, len(l.Items))
+	for _, rev := range l.Items {
+		// revisions of an ApplicationConfiguration of the same name that was
+		// deleted may not be garbage collected yet
+		if metav1.IsControlledBy(&rev, ac) {
+			revs = append(revs, rev)
+		}
+	}
+	sort.Sort(historiesByRevision(revs))
+	return revs, nil
+}
+
+// recordRevision records the applied spec of an ApplicationConfiguration as a
+// new revision of its history, unless it is the latest revision already. The
+// oldest revisions beyond the revision limit are deleted.
+func (r *OAMApplicationReconciler) recordRevision(ctx context.Context, ac *v1alpha2.ApplicationConfiguration,
+	workloads []Workload) (bool, error) {
+	revs, err := revisions(ctx, r.client, ac)
+	if err != nil {
+		return false, err
+	}
+	spec := appliedSpec(ac, workloads)
+
+	var next int64 = 1
+	if len(revs) > 0 {
+		latest := revs[len(revs)-1]
+		applied, err := util.UnpackAppConfigRevisionData(&latest)
+		if err != nil {
+			return false, errors.Wrapf(err, errFmtUnpackRevision, latest.GetName())
+		}
+		if sameSpec(applied.Spec, spec) {
+			ac.Status.LatestRevision = &v1alpha2.ApplicationConfigurationRevision{Name: latest.GetName(), Revision: latest.Revision}
+			return false, nil
+		}
+		next = latest.Revision + 1
+	}
+
+	name := ConstructAppConfigRevisionName(ac.GetName(), next)
+	rev := appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       ac.GetNamespace(),
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(ac, v1alpha2.ApplicationConfigurationGroupVersionKind)},
+			Labels:          map[string]string{oam.ControllerRevisionAppConfigLabel: ac.GetName()},
+			Annotations:     map[string]string{oam.AnnotationAppGeneration: strconv.FormatInt(ac.GetGeneration(), 10)},
+		},
+		Revision: next,
+		Data: runtime.RawExtension{Object: &v1alpha2.ApplicationConfiguration{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: v1alpha2.SchemeGroupVersion.String(),
+				Kind:       v1alpha2.ApplicationConfigurationKind,
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: ac.GetName(), Namespace: ac.GetNamespace(), Generation: ac.GetGeneration()},
+			Spec:       spec,
+		}},
+	}
+	if err := r.client.Create(ctx, &rev); err != nil {
+		return false, errors.Wrapf(err, errFmtCreateRevision, name)
+	}
+	ac.Status.LatestRevision = &v1alpha2.ApplicationConfigurationRevision{Name: name, Revision: next}
+
+	revs = append(revs, rev)
+	for i := 0; i < len(revs)-r.revisionLimit; i++ {
+		if err := r.client.Delete(ctx, &revs[i]); resource.IgnoreNotFound(err) != nil {
+			return true, errors.Wrapf(err, errFmtDeleteRevision, revs[i].GetName())
+		}
+	}
+	return true, nil
+}
+
+// rollback restores the spec of an ApplicationConfiguration from the revision
+// of its history named by its rollback annotation. The annotation is removed
+// whether or not the rollback succeeds, so that it's attempted only once.
+//
+// A revision binds each component to the component revision it was applied
+// with, so the restored spec pins components by revisionName that were
+// referenced by componentName before. rollback returns the names of these
+// component revisions, which stay pinned until the revisionName of their
+// component is replaced by its componentName again.
+func (r *OAMApplicationReconciler) rollback(ctx context.Context, ac *v1alpha2.ApplicationConfiguration) (string, []string, error) {
+	to := ac.GetAnnotations()[oam.AnnotationRollbackTo]
+	meta.RemoveAnnotations(ac, oam.AnnotationRollbackTo)
+
+	revs, err := revisions(ctx, r.client, ac)
+	if err != nil {
+		return "", nil, err
+	}
+	var rev *appsv1.ControllerRevision
+	for i := range revs {
+		if revs[i].GetName() == to || strconv.FormatInt(revs[i].Revision, 10) == to {
+			rev = &revs[i]
+			break
+		}
+	}
+	if rev == nil {
+		return "", nil, errors.Errorf(errFmtNoRevision, to)
+	}
+	applied, err := util.UnpackAppConfigRevisionData(rev)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, errFmtUnpackRevision, rev.GetName())
+	}
+	pinned := make(map[string]bool, len(ac.Spec.Components))
+	for _, acc := range ac.Spec.Components {
+		if acc.RevisionName != "" {
+			pinned[acc.RevisionName] = true
+		}
+	}
+	var pins []string
+	// component revisions may have been cleaned up since
+	for _, acc := range applied.Spec.Components {
+		if acc.RevisionName == "" {
+			continue
+		}
+		cr := &appsv1.ControllerRevision{}
+		if err := r.client.Get(ctx, types.NamespacedName{Namespace: ac.GetNamespace(), Name: acc.RevisionName}, cr); err != nil {
+			return "", nil, errors.Wrapf(err, errFmtGetComponentRevison, rev.GetName(), acc.RevisionName)
+		}
+		if !pinned[acc.RevisionName] {
+			pins = append(pins, acc.RevisionName)
+		}
+	}
+	ac.Spec = applied.Spec
+	return rev.GetName(), pins, nil
+}
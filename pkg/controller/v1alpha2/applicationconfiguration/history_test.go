@@ -0,0 +1,231 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationconfiguration
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+)
+
+func historyAppConfig() *v1alpha2.ApplicationConfiguration {
+	return &v1alpha2.ApplicationConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "app", UID: "ac", Generation: 3},
+		Spec: v1alpha2.ApplicationConfigurationSpec{Components: []v1alpha2.ApplicationConfigurationComponent{
+			{ComponentName: "web"},
+			{RevisionName: "db-v1"},
+		}},
+	}
+}
+
+func pinnedSpec(webRevision string) v1alpha2.ApplicationConfigurationSpec {
+	return v1alpha2.ApplicationConfigurationSpec{Components: []v1alpha2.ApplicationConfigurationComponent{
+		{RevisionName: webRevision},
+		{RevisionName: "db-v1"},
+	}}
+}
+
+// appConfigRevision returns a revision of the history of the supplied
+// ApplicationConfiguration as it is read from the API server.
+func appConfigRevision(ac *v1alpha2.ApplicationConfiguration, revision int64, spec v1alpha2.ApplicationConfigurationSpec) appsv1.ControllerRevision {
+	raw, _ := json.Marshal(&v1alpha2.ApplicationConfiguration{Spec: spec})
+	return appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            ConstructAppConfigRevisionName(ac.GetName(), revision),
+			Namespace:       ac.GetNamespace(),
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(ac, v1alpha2.ApplicationConfigurationGroupVersionKind)},
+		},
+		Revision: revision,
+		Data:     runtime.RawExtension{Raw: raw},
+	}
+}
+
+func TestAppliedSpec(t *testing.T) {
+	workloads := []Workload{
+		{ComponentName: "web", ComponentRevisionName: "web-v2"},
+		{ComponentName: "db", ComponentRevisionName: "db-v1"},
+	}
+	if diff := cmp.Diff(pinnedSpec("web-v2"), appliedSpec(historyAppConfig(), workloads)); diff != "" {
+		t.Errorf("appliedSpec(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestRecordRevision(t *testing.T) {
+	ac := historyAppConfig()
+	stale := appConfigRevision(ac, 7, pinnedSpec("web-v0"))
+	stale.OwnerReferences[0].UID = "previous"
+
+	cases := map[string]struct {
+		revs         []appsv1.ControllerRevision
+		limit        int
+		wantRecorded bool
+		wantLatest   *v1alpha2.ApplicationConfigurationRevision
+		wantCreated  []string
+		wantDeleted  []string
+	}{
+		"First": {
+			limit:        2,
+			wantRecorded: true,
+			wantLatest:   &v1alpha2.ApplicationConfigurationRevision{Name: "app-appconfig-r1", Revision: 1},
+			wantCreated:  []string{"app-appconfig-r1"},
+		},
+		"Unchanged": {
+			revs:       []appsv1.ControllerRevision{appConfigRevision(ac, 1, pinnedSpec("web-v1")), appConfigRevision(ac, 2, pinnedSpec("web-v2"))},
+			limit:      2,
+			wantLatest: &v1alpha2.ApplicationConfigurationRevision{Name: "app-appconfig-r2", Revision: 2},
+		},
+		"Changed": {
+			revs:         []appsv1.ControllerRevision{appConfigRevision(ac, 2, pinnedSpec("web-v1")), appConfigRevision(ac, 1, pinnedSpec("web-v0")), stale},
+			limit:        2,
+			wantRecorded: true,
+			wantLatest:   &v1alpha2.ApplicationConfigurationRevision{Name: "app-appconfig-r3", Revision: 3},
+			wantCreated:  []string{"app-appconfig-r3"},
+			wantDeleted:  []string{"app-appconfig-r1"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var created, deleted []string
+			c := &test.MockClient{
+				MockList: func(_ context.Context, list runtime.Object, _ ...client.ListOption) error {
+					list.(*appsv1.ControllerRevisionList).Items = tc.revs
+					return nil
+				},
+				MockCreate: func(_ context.Context, obj runtime.Object, _ ...client.CreateOption) error {
+					rev := obj.(*appsv1.ControllerRevision)
+					created = append(created, rev.GetName())
+					if diff := cmp.Diff(pinnedSpec("web-v2"), rev.Data.Object.(*v1alpha2.ApplicationConfiguration).Spec); diff != "" {
+						t.Errorf("r.recordRevision(...): -want spec, +got spec:\n%s", diff)
+					}
+					return nil
+				},
+				MockDelete: func(_ context.Context, obj runtime.Object, _ ...client.DeleteOption) error {
+					deleted = append(deleted, obj.(*appsv1.ControllerRevision).GetName())
+					return nil
+				},
+			}
+			r := &OAMApplicationReconciler{client: c, revisionLimit: tc.limit}
+			ac := historyAppConfig()
+			workloads := []Workload{{ComponentName: "web", ComponentRevisionName: "web-v2"}}
+
+			recorded, err := r.recordRevision(context.Background(), ac, workloads)
+			if err != nil {
+				t.Fatalf("r.recordRevision(...): %s", err)
+			}
+			if recorded != tc.wantRecorded {
+				t.Errorf("r.recordRevision(...): want recorded %t, got %t", tc.wantRecorded, recorded)
+			}
+			if diff := cmp.Diff(tc.wantLatest, ac.Status.LatestRevision); diff != "" {
+				t.Errorf("r.recordRevision(...): -want latest revision, +got latest revision:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantCreated, created); diff != "" {
+				t.Errorf("r.recordRevision(...): -want created, +got created:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantDeleted, deleted); diff != "" {
+				t.Errorf("r.recordRevision(...): -want deleted, +got deleted:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRollback(t *testing.T) {
+	ac := historyAppConfig()
+	revs := []appsv1.ControllerRevision{appConfigRevision(ac, 1, pinnedSpec("web-v1")), appConfigRevision(ac, 2, pinnedSpec("web-v2"))}
+
+	cases := map[string]struct {
+		to           string
+		missing      string
+		wantRevision string
+		wantPinned   []string
+		wantSpec     v1alpha2.ApplicationConfigurationSpec
+		wantErr      error
+	}{
+		"ByNumber": {
+			to:           "1",
+			wantRevision: "app-appconfig-r1",
+			wantPinned:   []string{"web-v1"},
+			wantSpec:     pinnedSpec("web-v1"),
+		},
+		"ByName": {
+			to:           "app-appconfig-r2",
+			wantRevision: "app-appconfig-r2",
+			wantPinned:   []string{"web-v2"},
+			wantSpec:     pinnedSpec("web-v2"),
+		},
+		"NoSuchRevision": {
+			to:       "5",
+			wantSpec: historyAppConfig().Spec,
+			wantErr:  errors.Errorf(errFmtNoRevision, "5"),
+		},
+		"ComponentRevisionGone": {
+			to:       "1",
+			missing:  "web-v1",
+			wantSpec: historyAppConfig().Spec,
+			wantErr: errors.Wrapf(kerrors.NewNotFound(schema.GroupResource{}, "web-v1"),
+				errFmtGetComponentRevison, "app-appconfig-r1", "web-v1"),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := &test.MockClient{
+				MockList: func(_ context.Context, list runtime.Object, _ ...client.ListOption) error {
+					list.(*appsv1.ControllerRevisionList).Items = revs
+					return nil
+				},
+				MockGet: func(_ context.Context, key client.ObjectKey, _ runtime.Object) error {
+					if key.Name == tc.missing {
+						return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
+					}
+					return nil
+				},
+			}
+			r := &OAMApplicationReconciler{client: c}
+			ac := historyAppConfig()
+			ac.SetAnnotations(map[string]string{oam.AnnotationRollbackTo: tc.to})
+
+			revision, pinned, err := r.rollback(context.Background(), ac)
+			if diff := cmp.Diff(tc.wantErr, err, test.EquateErrors()); diff != "" {
+				t.Errorf("r.rollback(...): -want error, +got error:\n%s", diff)
+			}
+			if revision != tc.wantRevision {
+				t.Errorf("r.rollback(...): want revision %q, got %q", tc.wantRevision, revision)
+			}
+			if diff := cmp.Diff(tc.wantPinned, pinned); diff != "" {
+				t.Errorf("r.rollback(...): -want pinned, +got pinned:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantSpec, ac.Spec); diff != "" {
+				t.Errorf("r.rollback(...): -want spec, +got spec:\n%s", diff)
+			}
+			if _, ok := ac.GetAnnotations()[oam.AnnotationRollbackTo]; ok {
+				t.Errorf("r.rollback(...): the %s annotation was not removed", oam.AnnotationRollbackTo)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationconfiguration
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
+)
+
+const (
+	errListRevisionWorkloads    = "cannot list the workloads of the revisions of the component"
+	errFmtDeleteHistoryWorkload = "cannot delete workload %q of an earlier revision"
+)
+
+// History workload event reasons.
+const (
+	reasonDeletedHistoryWorkload      = "DeletedHistoryWorkload"
+	reasonCannotDeleteHistoryWorkload = "CannotDeleteHistoryWorkload"
+)
+
+// revisionWorkloads returns the workload of the current revision of a
+// revision enabled component, if it exists, and the workloads of its earlier
+// revisions that still exist. Workloads of earlier revisions that the
+// ApplicationConfiguration no longer controls, or that it released with the
+// Retain deletion policy, keep their labels but are not part of its history.
+func (r *OAMApplicationReconciler) revisionWorkloads(ctx context.Context, ac *v1alpha2.ApplicationConfiguration,
+	w Workload) (*unstructured.Unstructured, []unstructured.Unstructured, error) {
+	var ul unstructured.UnstructuredList
+	ul.SetKind(w.Workload.GetKind())
+	ul.SetAPIVersion(w.Workload.GetAPIVersion())
+	if err := r.client.List(ctx, &ul, client.InNamespace(ac.GetNamespace()), client.MatchingLabels{oam.LabelAppName: ac.Name,
+		oam.LabelAppComponent: w.ComponentName, oam.LabelOAMResourceType: oam.ResourceTypeWorkload}); err != nil {
+		return nil, nil, errors.Wrap(err, errListRevisionWorkloads)
+	}
+	var current *unstructured.Unstructured
+	history := make([]unstructured.Unstructured, 0, len(ul.Items))
+	for i := range ul.Items {
+		if ul.Items[i].GetName() == w.ComponentRevisionName {
+			current = &ul.Items[i]
+			continue
+		}
+		if !metav1.IsControlledBy(&ul.Items[i], ac) || released(ac, util.ReferenceTo(&ul.Items[i])) {
+			continue
+		}
+		history = append(history, ul.Items[i])
+	}
+	return current, history, nil
+}
+
+// released tells whether the referenced resource was released by the
+// ApplicationConfiguration with the Retain deletion policy.
+func released(ac *v1alpha2.ApplicationConfiguration, ref runtimev1alpha1.TypedReference) bool {
+	for _, rr := range ac.Status.RetainedResources {
+		if util.SameResource(rr, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// healthy tells whether a workload is reported healthy by its HealthScopes or
+// ready by the StatusProjection of its WorkloadDefinition.
+func healthy(ws v1alpha2.WorkloadStatus) bool {
+	return ws.GetCondition(v1alpha2.TypeHealthy).Status == corev1.ConditionTrue ||
+		ws.GetCondition(runtimev1alpha1.TypeReady).Status == corev1.ConditionTrue
+}
+
+// expiredHistoryWorkloads returns the workloads of earlier revisions of a
+// component that are no longer kept by its retention policy. The workload of
+// the current revision is nil if it doesn't exist yet.
+func expiredHistoryWorkloads(policy *v1alpha2.HistoryWorkloadRetentionPolicy, current *unstructured.Unstructured,
+	currentHealthy bool, history []unstructured.Unstructured, now time.Time) []unstructured.Unstructured {
+	if policy == nil || len(history) == 0 {
+		return nil
+	}
+	if policy.RemoveWhenHealthy && currentHealthy {
+		return history
+	}
+	if policy.RemoveAfter != nil && current != nil &&
+		!now.Before(current.GetCreationTimestamp().Add(policy.RemoveAfter.Duration)) {
+		return history
+	}
+	if policy.MaxCount == nil || len(history) <= int(*policy.MaxCount) {
+		return nil
+	}
+	// keep the most recent ones
+	sorted := append([]unstructured.Unstructured{}, history...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ti, tj := sorted[i].GetCreationTimestamp(), sorted[j].GetCreationTimestamp()
+		return tj.Before(&ti)
+	})
+	return sorted[*policy.MaxCount:]
+}
+
+// cleanupHistoryWorkloads deletes the workloads of earlier revisions of the
+// revision enabled components of an ApplicationConfiguration that are no
+// longer kept by the retention policy of the component, according to the
+// deletion policy of the component. Workloads that are retained or that are
+// not controlled by the ApplicationConfiguration are left alone.
+func (r *OAMApplicationReconciler) cleanupHistoryWorkloads(ctx context.Context, ac *v1alpha2.ApplicationConfiguration, workloads []Workload) {
+	for i, w := range workloads {
+		if !w.RevisionEnabled || w.HistoryRetention == nil {
+			continue
+		}
+		log := r.log.WithValues("uid", ac.GetUID(), "version", ac.GetResourceVersion(), "component", w.ComponentName)
+		current, history, err := r.revisionWorkloads(ctx, ac, w)
+		if err != nil {
+			log.Debug("Cannot clean up workloads of earlier revisions", "error", err)
+			continue
+		}
+		candidates := make([]unstructured.Unstructured, 0, len(history))
+		for _, h := range history {
+			h := h
+			if !retained(&h) {
+				candidates = append(candidates, h)
+			}
+		}
+		for _, e := range expiredHistoryWorkloads(w.HistoryRetention, current, healthy(ac.Status.Workloads[i]), candidates, time.Now()) {
+			e := e
+			record := r.record.WithAnnotations("kind", e.GetKind(), "name", e.GetName(), "deletion-policy", string(w.DeletionPolicy))
+			if err := r.release(ctx, ac, &e, w.DeletionPolicy); err != nil {
+				log.Debug("Cannot delete workload of an earlier revision", "error", err, "name", e.GetName())
+				record.Event(ac, event.Warning(reasonCannotDeleteHistoryWorkload, errors.Wrapf(err, errFmtDeleteHistoryWorkload, e.GetName())))
+				continue
+			}
+			log.Debug("Deleted workload of an earlier revision", "name", e.GetName())
+			record.Event(ac, event.Normal(reasonDeletedHistoryWorkload, "Successfully deleted workload of an earlier revision"))
+			removeHistoryWorkload(ac, util.ReferenceTo(&e))
+		}
+	}
+}
+
+func removeHistoryWorkload(ac *v1alpha2.ApplicationConfiguration, ref runtimev1alpha1.TypedReference) {
+	kept := make([]v1alpha2.HistoryWorkload, 0, len(ac.Status.HistoryWorkloads))
+	for _, hw := range ac.Status.HistoryWorkloads {
+		if !util.SameResource(hw.Reference, ref) {
+			kept = append(kept, hw)
+		}
+	}
+	ac.Status.HistoryWorkloads = kept
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationconfiguration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+)
+
+// revisionWorkload returns a workload of a revision of the web component that
+// was created age ago.
+func revisionWorkload(ac *v1alpha2.ApplicationConfiguration, name string, now time.Time, age time.Duration) unstructured.Unstructured {
+	u := unstructured.Unstructured{}
+	u.SetAPIVersion("apps/v1")
+	u.SetKind("Deployment")
+	u.SetNamespace(ac.GetNamespace())
+	u.SetName(name)
+	u.SetCreationTimestamp(metav1.NewTime(now.Add(-age)))
+	u.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(ac, v1alpha2.ApplicationConfigurationGroupVersionKind)})
+	return u
+}
+
+func names(us []unstructured.Unstructured) []string {
+	var n []string
+	for _, u := range us {
+		n = append(n, u.GetName())
+	}
+	return n
+}
+
+func TestExpiredHistoryWorkloads(t *testing.T) {
+	ac := historyAppConfig()
+	now := time.Now()
+	current := revisionWorkload(ac, "web-v4", now, 10*time.Minute)
+	history := []unstructured.Unstructured{
+		revisionWorkload(ac, "web-v2", now, 2*time.Hour),
+		revisionWorkload(ac, "web-v3", now, time.Hour),
+		revisionWorkload(ac, "web-v1", now, 3*time.Hour),
+	}
+	count := func(n int32) *int32 { return &n }
+
+	cases := map[string]struct {
+		policy  *v1alpha2.HistoryWorkloadRetentionPolicy
+		current *unstructured.Unstructured
+		healthy bool
+		want    []string
+	}{
+		"NoPolicy": {
+			current: &current,
+			healthy: true,
+		},
+		"MaxCount": {
+			policy:  &v1alpha2.HistoryWorkloadRetentionPolicy{MaxCount: count(1)},
+			current: &current,
+			want:    []string{"web-v2", "web-v1"},
+		},
+		"WithinMaxCount": {
+			policy:  &v1alpha2.HistoryWorkloadRetentionPolicy{MaxCount: count(3)},
+			current: &current,
+		},
+		"RemoveAfter": {
+			policy:  &v1alpha2.HistoryWorkloadRetentionPolicy{RemoveAfter: &metav1.Duration{Duration: 5 * time.Minute}},
+			current: &current,
+			want:    []string{"web-v2", "web-v3", "web-v1"},
+		},
+		"NotYetRemoveAfter": {
+			policy:  &v1alpha2.HistoryWorkloadRetentionPolicy{MaxCount: count(2), RemoveAfter: &metav1.Duration{Duration: 15 * time.Minute}},
+			current: &current,
+			want:    []string{"web-v1"},
+		},
+		"RemoveAfterWithoutCurrent": {
+			policy: &v1alpha2.HistoryWorkloadRetentionPolicy{RemoveAfter: &metav1.Duration{Duration: 5 * time.Minute}},
+		},
+		"RemoveWhenHealthy": {
+			policy:  &v1alpha2.HistoryWorkloadRetentionPolicy{RemoveWhenHealthy: true},
+			current: &current,
+			healthy: true,
+			want:    []string{"web-v2", "web-v3", "web-v1"},
+		},
+		"NotYetHealthy": {
+			policy:  &v1alpha2.HistoryWorkloadRetentionPolicy{RemoveWhenHealthy: true},
+			current: &current,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := expiredHistoryWorkloads(tc.policy, tc.current, tc.healthy, history, now)
+			if diff := cmp.Diff(tc.want, names(got)); diff != "" {
+				t.Errorf("expiredHistoryWorkloads(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCleanupHistoryWorkloads(t *testing.T) {
+	ac := historyAppConfig()
+	now := time.Now()
+	retainedWorkload := revisionWorkload(ac, "web-v1", now, 3*time.Hour)
+	retainedWorkload.SetAnnotations(map[string]string{oam.AnnotationRetain: "true"})
+	foreign := revisionWorkload(ac, "web-v2", now, 2*time.Hour)
+	foreign.SetOwnerReferences(nil)
+	live := []unstructured.Unstructured{
+		retainedWorkload,
+		foreign,
+		revisionWorkload(ac, "web-v3", now, time.Hour),
+		revisionWorkload(ac, "web-v4", now, 10*time.Minute),
+	}
+	ref := func(name string) runtimev1alpha1.TypedReference {
+		return runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: name}
+	}
+
+	var deleted []string
+	r := &OAMApplicationReconciler{
+		client: &test.MockClient{
+			MockList: func(_ context.Context, list runtime.Object, _ ...client.ListOption) error {
+				list.(*unstructured.UnstructuredList).Items = live
+				return nil
+			},
+			MockDelete: func(_ context.Context, obj runtime.Object, _ ...client.DeleteOption) error {
+				deleted = append(deleted, obj.(*unstructured.Unstructured).GetName())
+				return nil
+			},
+		},
+		log:    logging.NewNopLogger(),
+		record: event.NewNopRecorder(),
+	}
+	current := live[3].DeepCopy()
+	workloads := []Workload{
+		{ComponentName: "web", ComponentRevisionName: "web-v4", Workload: current, RevisionEnabled: true,
+			HistoryRetention: &v1alpha2.HistoryWorkloadRetentionPolicy{RemoveWhenHealthy: true}},
+		{ComponentName: "db", Workload: current, RevisionEnabled: true},
+	}
+	ac.Status.Workloads = []v1alpha2.WorkloadStatus{{ComponentName: "web"}, {ComponentName: "db"}}
+	ac.Status.Workloads[0].SetConditions(v1alpha2.Healthy())
+	ac.Status.HistoryWorkloads = []v1alpha2.HistoryWorkload{
+		{Revision: "web-v1", Reference: ref("web-v1")},
+		{Revision: "web-v2", Reference: ref("web-v2")},
+		{Revision: "web-v3", Reference: ref("web-v3")},
+	}
+
+	r.cleanupHistoryWorkloads(context.Background(), ac, workloads)
+	if diff := cmp.Diff([]string{"web-v3"}, deleted); diff != "" {
+		t.Errorf("r.cleanupHistoryWorkloads(...): -want deleted, +got deleted:\n%s", diff)
+	}
+	want := []v1alpha2.HistoryWorkload{
+		{Revision: "web-v1", Reference: ref("web-v1")},
+		{Revision: "web-v2", Reference: ref("web-v2")},
+	}
+	if diff := cmp.Diff(want, ac.Status.HistoryWorkloads); diff != "" {
+		t.Errorf("r.cleanupHistoryWorkloads(...): -want history workloads, +got history workloads:\n%s", diff)
+	}
+}
+
+func TestRevisionWorkloads(t *testing.T) {
+	ac := historyAppConfig()
+	now := time.Now()
+	orphaned := revisionWorkload(ac, "web-v1", now, 3*time.Hour)
+	orphaned.SetOwnerReferences(nil)
+	live := []unstructured.Unstructured{
+		orphaned,
+		revisionWorkload(ac, "web-v2", now, 2*time.Hour),
+		revisionWorkload(ac, "web-v3", now, time.Hour),
+		revisionWorkload(ac, "web-v4", now, 10*time.Minute),
+	}
+	ac.Status.RetainedResources = []runtimev1alpha1.TypedReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "web-v2"}}
+
+	var listOpts client.ListOptions
+	r := &OAMApplicationReconciler{client: &test.MockClient{
+		MockList: func(_ context.Context, list runtime.Object, opts ...client.ListOption) error {
+			listOpts.ApplyOptions(opts)
+			list.(*unstructured.UnstructuredList).Items = live
+			return nil
+		},
+	}}
+	w := Workload{ComponentName: "web", ComponentRevisionName: "web-v4", Workload: live[3].DeepCopy(), RevisionEnabled: true}
+	current, history, err := r.revisionWorkloads(context.Background(), ac, w)
+	if err != nil {
+		t.Fatalf("r.revisionWorkloads(...): %s", err)
+	}
+	if listOpts.Namespace != ac.GetNamespace() {
+		t.Errorf("r.revisionWorkloads(...): want workloads listed in namespace %q, got %q", ac.GetNamespace(), listOpts.Namespace)
+	}
+	if current == nil || current.GetName() != "web-v4" {
+		t.Errorf("r.revisionWorkloads(...): want current workload web-v4, got %v", current)
+	}
+	if diff := cmp.Diff([]string{"web-v3"}, names(history)); diff != "" {
+		t.Errorf("r.revisionWorkloads(...): -want history, +got history:\n%s", diff)
+	}
+}
@@ -60,6 +60,9 @@ const (
 	errFmtRequiredParam    = "required parameter %q not specified"
 	errFmtCompRevision     = "cannot get latest revision for component %q while revision is enabled"
 	errSetValueForField    = "can not set value %q for fieldPath %q"
+	errFmtTraitNotApplied  = "%s %q is not applied: %s"
+
+	errFmtGetWorkloadDefinition = "cannot find workload definition %q %q %q"
 )
 
 var (
@@ -125,7 +128,7 @@ func (r *components) Render(ctx context.Context, ac *v1alpha2.ApplicationConfigu
 
 func (r *components) renderComponent(ctx context.Context, acc v1alpha2.ApplicationConfigurationComponent, ac *v1alpha2.ApplicationConfiguration, dag *dag) (*Workload, error) {
 	if acc.RevisionName != "" {
-		acc.ComponentName = ExtractComponentName(acc.RevisionName)
+		acc.ComponentName = util.ExtractComponentName(acc.RevisionName)
 	}
 	c, componentRevisionName, err := util.GetComponent(ctx, r.client, acc, ac.GetNamespace())
 	if err != nil {
@@ -160,6 +163,18 @@ func (r *components) renderComponent(ctx context.Context, acc v1alpha2.Applicati
 	w.SetOwnerReferences([]metav1.OwnerReference{*ref})
 	w.SetNamespace(ac.GetNamespace())
 
+	workloadDef, err := util.FetchWorkloadDefinition(ctx, r.client, r.dm, ac.GetNamespace(), w)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, errFmtGetWorkloadDefinition, w.GetAPIVersion(), w.GetKind(), w.GetName())
+		}
+		workloadDef = util.GetDummyWorkloadDefinition(w)
+	}
+	policy := deletionPolicy(acc.DeletionPolicy, workloadDef.Spec.DeletionPolicy)
+	if policy == v1alpha2.DeletionPolicyRetain {
+		util.AddAnnotations(w, map[string]string{oam.AnnotationRetain: "true"})
+	}
+
 	traits := make([]*Trait, 0, len(acc.Traits))
 	traitDefs := make([]v1alpha2.TraitDefinition, 0, len(acc.Traits))
 	compInfoLabels[oam.LabelOAMResourceType] = oam.ResourceTypeTrait
@@ -174,10 +189,16 @@ func (r *components) renderComponent(ctx context.Context, acc v1alpha2.Applicati
 
 		// pass through labels and annotation from app-config to trait
 		util.PassLabelAndAnnotation(ac, t)
-		traits = append(traits, &Trait{Object: *t, Definition: *traitDef})
+		traitPolicy := deletionPolicy(ct.DeletionPolicy, traitDef.Spec.DeletionPolicy)
+		if traitPolicy == v1alpha2.DeletionPolicyRetain {
+			util.AddAnnotations(t, map[string]string{oam.AnnotationRetain: "true"})
+		}
+		traits = append(traits, &Trait{Object: *t, Definition: *traitDef, DeletionPolicy: traitPolicy})
 		traitDefs = append(traitDefs, *traitDef)
 	}
 
+	traits, traitDefs, conflicts := dropConflictingTraits(traits, traitDefs)
+
 	existingWorkload, err := r.getExistingWorkload(ctx, ac, c, w)
 	if err != nil {
 		return nil, err
@@ -193,7 +214,7 @@ func (r *components) renderComponent(ctx context.Context, acc v1alpha2.Applicati
 		Name:       w.GetName(),
 	}
 	//  We only patch a TypedReference object to the trait if it asks for it
-	for i := range acc.Traits {
+	for i := range traits {
 		traitDef := traitDefs[i]
 		trait := traits[i]
 		workloadRefPath := traitDef.Spec.WorkloadRefPath
@@ -215,8 +236,40 @@ func (r *components) renderComponent(ctx context.Context, acc v1alpha2.Applicati
 
 	addDataOutputsToDAG(dag, acc.DataOutputs, w)
 
-	return &Workload{ComponentName: acc.ComponentName, ComponentRevisionName: componentRevisionName,
-		Workload: w, Traits: traits, RevisionEnabled: isRevisionEnabled(traitDefs), Scopes: scopes}, nil
+	workload := &Workload{ComponentName: acc.ComponentName, ComponentRevisionName: componentRevisionName,
+		Workload: w, Traits: traits, RevisionEnabled: isRevisionEnabled(traitDefs), Scopes: scopes,
+		HistoryRetention: acc.HistoryWorkloadRetention,
+		StatusProjection: workloadDef.Spec.StatusProjection, DeletionPolicy: policy, Adopt: adopts(ac, c)}
+	if len(conflicts) > 0 {
+		workload.Conditions = append(workload.Conditions, v1alpha2.TraitsConflict(strings.Join(conflicts, "; ")))
+	}
+	return workload, nil
+}
+
+// dropConflictingTraits drops every trait that conflicts with a trait before
+// it, according to the ConflictsWith of their TraitDefinitions. It returns the
+// remaining traits and their definitions, and why traits were dropped.
+func dropConflictingTraits(traits []*Trait, traitDefs []v1alpha2.TraitDefinition) ([]*Trait,
+	[]v1alpha2.TraitDefinition, []string) {
+	keptTraits := make([]*Trait, 0, len(traits))
+	keptDefs := make([]v1alpha2.TraitDefinition, 0, len(traitDefs))
+	var conflicts []string
+	for i := range traits {
+		conflict := ""
+		for j := range keptDefs {
+			if conflict = util.TraitsConflict(&keptDefs[j], &traitDefs[i]); conflict != "" {
+				break
+			}
+		}
+		if conflict != "" {
+			conflicts = append(conflicts, fmt.Sprintf(errFmtTraitNotApplied,
+				traits[i].Object.GetKind(), traits[i].Object.GetName(), conflict))
+			continue
+		}
+		keptTraits = append(keptTraits, traits[i])
+		keptDefs = append(keptDefs, traitDefs[i])
+	}
+	return keptTraits, keptDefs, conflicts
 }
 
 func (r *components) renderTrait(ctx context.Context, ct v1alpha2.ComponentTrait, ac *v1alpha2.ApplicationConfiguration,
@@ -225,7 +278,7 @@ func (r *components) renderTrait(ctx context.Context, ct v1alpha2.ComponentTrait
 	if err != nil {
 		return nil, nil, errors.Wrapf(err, errFmtRenderTrait, componentName)
 	}
-	traitDef, err := util.FetchTraitDefinition(ctx, r.client, r.dm, t)
+	traitDef, err := util.FetchTraitDefinition(ctx, r.client, r.dm, ac.GetNamespace(), t)
 	if err != nil {
 		if !apierrors.IsNotFound(err) {
 			return nil, nil, errors.Wrapf(err, errFmtGetTraitDefinition, t.GetAPIVersion(), t.GetKind(), t.GetName())
@@ -324,6 +377,17 @@ func (fn ResourceRenderFn) Render(data []byte, p ...Parameter) (*unstructured.Un
 	return fn(data, p...)
 }
 
+// RenderWorkload returns the workload of the supplied Component with the
+// supplied parameter values applied, the way the ApplicationConfiguration
+// controller renders it.
+func RenderWorkload(c *v1alpha2.Component, values []v1alpha2.ComponentParameterValue) (*unstructured.Unstructured, error) {
+	p, err := resolve(c.Spec.Parameters, values)
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtResolveParams, c.GetName())
+	}
+	return renderWorkload(c.Spec.Workload.Raw, p...)
+}
+
 func renderWorkload(data []byte, p ...Parameter) (*unstructured.Unstructured, error) {
 	// TODO(negz): Is there a better decoder to use here?
 	w := &fieldpath.Paved{}
@@ -30,6 +30,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/apps/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -178,6 +179,8 @@ func TestRenderComponents(t *testing.T) {
 					case *v1alpha2.Component:
 						ccomp := v1alpha2.Component{Status: v1alpha2.ComponentStatus{LatestRevision: &v1alpha2.Revision{Name: revisionName2}}}
 						ccomp.DeepCopyInto(robj)
+					case *v1alpha2.NamespacedTraitDefinition:
+						return kerrors.NewNotFound(v1alpha2.SchemeGroupVersion.WithResource("namespacedtraitdefinitions").GroupResource(), "")
 					case *v1alpha2.TraitDefinition:
 						return errTrait
 					}
@@ -256,11 +259,13 @@ func TestRenderComponents(t *testing.T) {
 									oam.LabelAppComponentRevision: "",
 									oam.LabelOAMResourceType:      oam.ResourceTypeTrait,
 								})
-								return &Trait{Object: *t, DataOutputs: make(map[string]v1alpha2.DataOutput)}
+								return &Trait{Object: *t, DataOutputs: make(map[string]v1alpha2.DataOutput),
+									DeletionPolicy: v1alpha2.DeletionPolicyDelete}
 							}(),
 						},
-						Scopes:      []unstructured.Unstructured{},
-						DataOutputs: make(map[string]v1alpha2.DataOutput),
+						Scopes:         []unstructured.Unstructured{},
+						DataOutputs:    make(map[string]v1alpha2.DataOutput),
+						DeletionPolicy: v1alpha2.DeletionPolicyDelete,
 					},
 				},
 			},
@@ -338,11 +343,13 @@ func TestRenderComponents(t *testing.T) {
 									oam.LabelAppComponentRevision: revisionName,
 									oam.LabelOAMResourceType:      oam.ResourceTypeTrait,
 								})
-								return &Trait{Object: *t, DataOutputs: make(map[string]v1alpha2.DataOutput)}
+								return &Trait{Object: *t, DataOutputs: make(map[string]v1alpha2.DataOutput),
+									DeletionPolicy: v1alpha2.DeletionPolicyDelete}
 							}(),
 						},
-						Scopes:      []unstructured.Unstructured{},
-						DataOutputs: make(map[string]v1alpha2.DataOutput),
+						Scopes:         []unstructured.Unstructured{},
+						DataOutputs:    make(map[string]v1alpha2.DataOutput),
+						DeletionPolicy: v1alpha2.DeletionPolicyDelete,
 					},
 				},
 			},
@@ -355,6 +362,8 @@ func TestRenderComponents(t *testing.T) {
 					case *v1alpha2.Component:
 						ccomp := v1alpha2.Component{Status: v1alpha2.ComponentStatus{LatestRevision: &v1alpha2.Revision{Name: revisionName2}}}
 						ccomp.DeepCopyInto(robj)
+					case *v1alpha2.NamespacedTraitDefinition:
+						return kerrors.NewNotFound(v1alpha2.SchemeGroupVersion.WithResource("namespacedtraitdefinitions").GroupResource(), "")
 					case *v1alpha2.TraitDefinition:
 						ttrait := v1alpha2.TraitDefinition{ObjectMeta: metav1.ObjectMeta{Name: traitName}, Spec: v1alpha2.TraitDefinitionSpec{RevisionEnabled: true}}
 						ttrait.DeepCopyInto(robj)
@@ -412,12 +421,14 @@ func TestRenderComponents(t *testing.T) {
 									oam.LabelOAMResourceType:      oam.ResourceTypeTrait,
 								})
 								return &Trait{Object: *t,
-									Definition: v1alpha2.TraitDefinition{ObjectMeta: metav1.ObjectMeta{Name: "coolTrait"}, Spec: v1alpha2.TraitDefinitionSpec{RevisionEnabled: true}}, DataOutputs: make(map[string]v1alpha2.DataOutput)}
+									Definition: v1alpha2.TraitDefinition{ObjectMeta: metav1.ObjectMeta{Name: "coolTrait"}, Spec: v1alpha2.TraitDefinitionSpec{RevisionEnabled: true}}, DataOutputs: make(map[string]v1alpha2.DataOutput),
+									DeletionPolicy: v1alpha2.DeletionPolicyDelete}
 							}(),
 						},
 						RevisionEnabled: true,
 						Scopes:          []unstructured.Unstructured{},
 						DataOutputs:     make(map[string]v1alpha2.DataOutput),
+						DeletionPolicy:  v1alpha2.DeletionPolicyDelete,
 					},
 				},
 			},
@@ -443,9 +454,10 @@ func TestRenderComponents(t *testing.T) {
 						rev.DeepCopyInto(robj)
 						return nil
 					}
-					trd, ok := obj.(*v1alpha2.TraitDefinition)
+					// the namespaced definition shadows the cluster scoped one
+					trd, ok := obj.(*v1alpha2.NamespacedTraitDefinition)
 					if ok {
-						td := v1alpha2.TraitDefinition{
+						td := v1alpha2.NamespacedTraitDefinition{
 							Spec: v1alpha2.TraitDefinitionSpec{
 								WorkloadRefPath: "spec.workload.path",
 							},
@@ -516,11 +528,13 @@ func TestRenderComponents(t *testing.T) {
 								if err := fieldpath.Pave(tr.Object).SetValue("spec.workload.path", workloadRef); err != nil {
 									t.Fail()
 								}
-								return &Trait{Object: *tr, Definition: v1alpha2.TraitDefinition{Spec: v1alpha2.TraitDefinitionSpec{WorkloadRefPath: "spec.workload.path"}}, DataOutputs: make(map[string]v1alpha2.DataOutput)}
+								return &Trait{Object: *tr, Definition: v1alpha2.TraitDefinition{Spec: v1alpha2.TraitDefinitionSpec{WorkloadRefPath: "spec.workload.path"}}, DataOutputs: make(map[string]v1alpha2.DataOutput),
+									DeletionPolicy: v1alpha2.DeletionPolicyDelete}
 							}(),
 						},
-						Scopes:      []unstructured.Unstructured{},
-						DataOutputs: make(map[string]v1alpha2.DataOutput),
+						Scopes:         []unstructured.Unstructured{},
+						DataOutputs:    make(map[string]v1alpha2.DataOutput),
+						DeletionPolicy: v1alpha2.DeletionPolicyDelete,
 					},
 				},
 			},
@@ -540,6 +554,31 @@ func TestRenderComponents(t *testing.T) {
 	}
 }
 
+func TestDropConflictingTraits(t *testing.T) {
+	trait := func(kind, name string) *Trait {
+		u := unstructured.Unstructured{}
+		u.SetKind(kind)
+		u.SetName(name)
+		return &Trait{Object: u}
+	}
+	replicas := []v1alpha2.TraitConflict{{WorkloadFieldPath: "spec.replicas"}}
+	scalerDef := v1alpha2.TraitDefinition{ObjectMeta: metav1.ObjectMeta{Name: "manualscalertraits.core.oam.dev"},
+		Spec: v1alpha2.TraitDefinitionSpec{ConflictsWith: replicas}}
+	autoscalerDef := v1alpha2.TraitDefinition{ObjectMeta: metav1.ObjectMeta{Name: "autoscalers.standard.oam.dev"},
+		Spec: v1alpha2.TraitDefinitionSpec{ConflictsWith: replicas}}
+	routeDef := v1alpha2.TraitDefinition{ObjectMeta: metav1.ObjectMeta{Name: "routes.standard.oam.dev"}}
+
+	scaler, route, autoscaler := trait("ManualScalerTrait", "scaler"), trait("Route", "route"), trait("Autoscaler", "hpa")
+	traits, defs, conflicts := dropConflictingTraits(
+		[]*Trait{scaler, route, autoscaler},
+		[]v1alpha2.TraitDefinition{scalerDef, routeDef, autoscalerDef})
+
+	assert.Equal(t, []*Trait{scaler, route}, traits)
+	assert.Equal(t, []v1alpha2.TraitDefinition{scalerDef, routeDef}, defs)
+	assert.Equal(t, []string{`Autoscaler "hpa" is not applied: traits "manualscalertraits.core.oam.dev" and ` +
+		`"autoscalers.standard.oam.dev" both manage workload field "spec.replicas"`}, conflicts)
+}
+
 func TestRenderWorkload(t *testing.T) {
 	namespace := "ns"
 	paramName := "coolparam"
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationconfiguration
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+)
+
+const errListComponents = "cannot list components"
+
+// A RevisionCollector periodically cleans up the revisions of every Component
+// according to its revision retention policy, so that revisions expire even
+// when no new revision is created.
+type RevisionCollector struct {
+	handler  *ComponentHandler
+	interval time.Duration
+}
+
+var _ manager.Runnable = &RevisionCollector{}
+var _ manager.LeaderElectionRunnable = &RevisionCollector{}
+
+// NewRevisionCollector returns a RevisionCollector that cleans up revisions
+// using the supplied ComponentHandler every interval.
+func NewRevisionCollector(h *ComponentHandler, interval time.Duration) *RevisionCollector {
+	return &RevisionCollector{handler: h, interval: interval}
+}
+
+// Start cleans up revisions periodically until the stop channel is closed.
+func (rc *RevisionCollector) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(rc.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+			if err := rc.Collect(context.Background()); err != nil {
+				rc.handler.Logger.Info("Cannot clean up component revisions", "error", err)
+			}
+		}
+	}
+}
+
+// NeedLeaderElection returns true, only the leader should delete revisions.
+func (rc *RevisionCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// Collect cleans up the revisions of every Component once.
+func (rc *RevisionCollector) Collect(ctx context.Context) error {
+	comps := &v1alpha2.ComponentList{}
+	if err := rc.handler.Client.List(ctx, comps); err != nil {
+		return errors.Wrap(err, errListComponents)
+	}
+	for i := range comps.Items {
+		comp := &comps.Items[i]
+		if err := rc.handler.cleanupControllerRevision(comp); err != nil {
+			rc.handler.Logger.Info("Cannot clean up component revisions", "error", err, "componentName", comp.GetName())
+		}
+	}
+	return nil
+}
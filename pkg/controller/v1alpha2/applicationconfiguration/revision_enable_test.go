@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
 	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
 )
 
@@ -141,7 +142,7 @@ var _ = Describe("Test ApplicationConfiguration Component Revision Enabled trait
 		Eventually(func() error {
 			labels := &metav1.LabelSelector{
 				MatchLabels: map[string]string{
-					ControllerRevisionComponentLabel: compName,
+					oam.ControllerRevisionComponentLabel: compName,
 				},
 			}
 			selector, err := metav1.LabelSelectorAsSelector(labels)
@@ -253,7 +254,7 @@ var _ = Describe("Test ApplicationConfiguration Component Revision Enabled trait
 		Eventually(func() error {
 			labels := &metav1.LabelSelector{
 				MatchLabels: map[string]string{
-					ControllerRevisionComponentLabel: compName,
+					oam.ControllerRevisionComponentLabel: compName,
 				},
 			}
 			selector, err := metav1.LabelSelectorAsSelector(labels)
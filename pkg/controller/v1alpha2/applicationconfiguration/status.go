@@ -0,0 +1,423 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationconfiguration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+)
+
+// States of workloads and traits, summarized from their ready condition or,
+// for traits without a StatusProjection, from all of their conditions.
+const (
+	StatusReady    = "Ready"
+	StatusNotReady = "NotReady"
+)
+
+const (
+	msgFmtNotReady      = "workloads of components %s are not ready"
+	msgFmtNotObserved   = "readiness of the workloads of components %s is not observed yet"
+	msgFmtUnhealthy     = "workloads of components %s are unhealthy"
+	msgFmtHealthUnknown = "health of the workloads of components %s is unknown"
+	msgFmtScopeHealth   = "%s %q: %s"
+	msgNotProbed        = "the workload has not been probed by the scope yet"
+	msgFmtCondition     = "%s: %s"
+	msgFmtScopeOverlap  = "did not join scope %q: the workload already belongs to scope %q and ScopeDefinition %q does not allow component overlap"
+	msgFmtScopesOverlap = "workloads of components %s did not join some of their scopes because they would overlap"
+)
+
+// reasonReadinessUnknown is the reason of the Ready condition of a workload
+// that doesn't report its ready condition yet.
+const reasonReadinessUnknown = "ReadinessUnknown"
+
+// Trait status event reasons.
+const (
+	reasonTraitReady    = "TraitReady"
+	reasonTraitNotReady = "TraitNotReady"
+)
+
+// projectStatus copies the fields declared by the StatusProjections of the
+// definitions of the workloads and traits into the status of the
+// ApplicationConfiguration. Traits whose TraitDefinition declares no
+// StatusProjection are summarized from their conditions instead, and an event
+// is emitted when the status of a trait changes. Workloads and traits that
+// can't be read keep the status they had.
+func (r *OAMApplicationReconciler) projectStatus(ctx context.Context, ac *v1alpha2.ApplicationConfiguration, workloads []Workload) {
+	for i, w := range workloads {
+		ws := &ac.Status.Workloads[i]
+		if w.StatusProjection == nil || w.StatusProjection.ReadyConditionType == "" {
+			removeCondition(&ws.ConditionedStatus, runtimev1alpha1.TypeReady)
+		}
+		if w.StatusProjection != nil {
+			if live, err := r.getLive(ctx, w.Workload); err != nil {
+				r.log.Debug("Cannot get workload to project its status", "error", err,
+					"kind", w.Workload.GetKind(), "name", w.Workload.GetName())
+			} else {
+				ws.Status, ws.Message, ws.Fields = project(live.Object, *w.StatusProjection)
+				if t := w.StatusProjection.ReadyConditionType; t != "" {
+					ws.SetConditions(readiness(live.Object, t))
+				}
+			}
+		}
+		for j, tr := range w.Traits {
+			wt := &ws.Traits[j]
+			prev := wt.Status
+			live, err := r.getLive(ctx, &tr.Object)
+			if err != nil {
+				r.log.Debug("Cannot get trait to project its status", "error", err,
+					"kind", tr.Object.GetKind(), "name", tr.Object.GetName())
+				continue
+			}
+			if p := tr.Definition.Spec.StatusProjection; p != nil {
+				var status string
+				status, wt.Message, wt.Fields = project(live.Object, *p)
+				wt.Status = v1alpha2.TraitStatus(status)
+			} else if status, msg, ok := summarize(live.Object); ok {
+				wt.Status, wt.Message = v1alpha2.TraitStatus(status), msg
+			}
+			if wt.Status != prev {
+				r.recordTraitStatus(ac, ws.ComponentName, *wt)
+			}
+		}
+	}
+}
+
+// recordTraitStatus emits an event for a trait whose status changed.
+func (r *OAMApplicationReconciler) recordTraitStatus(ac *v1alpha2.ApplicationConfiguration, componentName string, wt v1alpha2.WorkloadTrait) {
+	record := r.record.WithAnnotations("component", componentName, "kind", wt.Reference.Kind, "name", wt.Reference.Name)
+	switch string(wt.Status) {
+	case StatusReady:
+		record.Event(ac, event.Normal(reasonTraitReady, "Trait is ready"))
+	case StatusNotReady:
+		record.Event(ac, event.Warning(reasonTraitNotReady, errors.New(wt.Message)))
+	}
+}
+
+// summarize returns the status and message of an object from its
+// status.conditions. The object is ready if all of its conditions are True;
+// the message lists the conditions that are not. ok is false if the object
+// has no conditions.
+func summarize(obj map[string]interface{}) (status string, message string, ok bool) {
+	var conditions []runtimev1alpha1.Condition
+	if err := fieldpath.Pave(obj).GetValueInto("status.conditions", &conditions); err != nil || len(conditions) == 0 {
+		return "", "", false
+	}
+	var messages []string
+	for _, c := range conditions {
+		if c.Status == corev1.ConditionTrue {
+			continue
+		}
+		msg := c.Message
+		if msg == "" {
+			msg = string(c.Reason)
+		}
+		messages = append(messages, fmt.Sprintf(msgFmtCondition, c.Type, msg))
+	}
+	if len(messages) > 0 {
+		return StatusNotReady, strings.Join(messages, "; "), true
+	}
+	return StatusReady, "", true
+}
+
+func (r *OAMApplicationReconciler) getLive(ctx context.Context, u *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(u.GroupVersionKind())
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: u.GetNamespace(), Name: u.GetName()}, live)
+	return live, err
+}
+
+// readiness returns the Ready condition of a workload whose ready condition
+// is of the supplied type. The readiness of a workload that doesn't report
+// the condition yet is unknown.
+func readiness(obj map[string]interface{}, conditionType string) runtimev1alpha1.Condition {
+	status, msg := readyCondition(fieldpath.Pave(obj), conditionType)
+	switch status {
+	case corev1.ConditionTrue:
+		return runtimev1alpha1.Available()
+	case corev1.ConditionFalse:
+		return runtimev1alpha1.Unavailable().WithMessage(msg)
+	default:
+		return runtimev1alpha1.Condition{
+			Type:               runtimev1alpha1.TypeReady,
+			Status:             corev1.ConditionUnknown,
+			LastTransitionTime: metav1.Now(),
+			Reason:             reasonReadinessUnknown,
+			Message:            msg,
+		}
+	}
+}
+
+// aggregateHealth records the health of each workload according to the
+// HealthScopes it is in, and the health of the ApplicationConfiguration as a
+// whole. A workload is as healthy as its least healthy scope considers it.
+func aggregateHealth(ac *v1alpha2.ApplicationConfiguration, workloads []Workload) {
+	var scoped bool
+	var unhealthy, unknown []string
+	for i, w := range workloads {
+		ws := &ac.Status.Workloads[i]
+		health := v1alpha2.HealthStatus("")
+		var diagnoses []string
+		for j, s := range w.Scopes {
+			if s.GroupVersionKind().GroupKind() != v1alpha2.HealthScopeGroupVersionKind.GroupKind() {
+				continue
+			}
+			hs := &v1alpha2.HealthScope{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(s.Object, hs); err != nil {
+				continue
+			}
+			c := workloadHealth(hs, ws.ComponentName, ws.Reference)
+			ws.Scopes[j].Status = v1alpha2.ScopeStatus(c.HealthStatus)
+			ws.Scopes[j].Diagnosis = c.Diagnosis
+			if c.HealthStatus != v1alpha2.StatusHealthy && c.Diagnosis != "" {
+				diagnoses = append(diagnoses, fmt.Sprintf(msgFmtScopeHealth, s.GetKind(), s.GetName(), c.Diagnosis))
+			}
+			health = worse(health, c.HealthStatus)
+		}
+		switch health {
+		case "":
+			removeCondition(&ws.ConditionedStatus, v1alpha2.TypeHealthy)
+			continue
+		case v1alpha2.StatusHealthy:
+			ws.SetConditions(v1alpha2.Healthy())
+		case v1alpha2.StatusUnhealthy:
+			ws.SetConditions(v1alpha2.Unhealthy(strings.Join(diagnoses, "; ")))
+			unhealthy = append(unhealthy, ws.ComponentName)
+		default:
+			ws.SetConditions(v1alpha2.HealthUnknown(strings.Join(diagnoses, "; ")))
+			unknown = append(unknown, ws.ComponentName)
+		}
+		scoped = true
+	}
+
+	switch {
+	case !scoped:
+		removeCondition(&ac.Status.ConditionedStatus, v1alpha2.TypeHealthy)
+	case len(unhealthy) > 0:
+		ac.SetConditions(v1alpha2.Unhealthy(fmt.Sprintf(msgFmtUnhealthy, strings.Join(unhealthy, ", "))))
+	case len(unknown) > 0:
+		ac.SetConditions(v1alpha2.HealthUnknown(fmt.Sprintf(msgFmtHealthUnknown, strings.Join(unknown, ", "))))
+	default:
+		ac.SetConditions(v1alpha2.Healthy())
+	}
+}
+
+// removeCondition removes the condition of the type, e.g. when it no longer
+// applies.
+func removeCondition(cs *runtimev1alpha1.ConditionedStatus, t runtimev1alpha1.ConditionType) {
+	kept := cs.Conditions[:0]
+	for _, c := range cs.Conditions {
+		if c.Type != t {
+			kept = append(kept, c)
+		}
+	}
+	cs.Conditions = kept
+}
+
+// workloadHealth returns the health condition the HealthScope recorded for
+// the workload. The scope may have recorded the workload at another version of
+// its API group.
+func workloadHealth(hs *v1alpha2.HealthScope, componentName string, ref runtimev1alpha1.TypedReference) v1alpha2.WorkloadHealthCondition {
+	gk := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind).GroupKind()
+	for _, c := range hs.Status.WorkloadHealthConditions {
+		if c == nil {
+			continue
+		}
+		target := c.TargetWorkload
+		if schema.FromAPIVersionAndKind(target.APIVersion, target.Kind).GroupKind() == gk && target.Name == ref.Name {
+			return *c
+		}
+	}
+	return v1alpha2.WorkloadHealthCondition{
+		ComponentName:  componentName,
+		TargetWorkload: ref,
+		HealthStatus:   v1alpha2.StatusUnknown,
+		Diagnosis:      msgNotProbed,
+	}
+}
+
+// worse returns the worse of two health statuses. Unhealthy is worse than
+// unknown, which is worse than healthy.
+func worse(a, b v1alpha2.HealthStatus) v1alpha2.HealthStatus {
+	rank := func(h v1alpha2.HealthStatus) int {
+		switch h {
+		case "":
+			return 0
+		case v1alpha2.StatusHealthy:
+			return 1
+		case v1alpha2.StatusUnhealthy:
+			return 3
+		default:
+			return 2
+		}
+	}
+	if rank(b) > rank(a) {
+		return b
+	}
+	return a
+}
+
+// updatePhase sets the phase and the Ready condition of the
+// ApplicationConfiguration from the readiness of its workloads. Workloads
+// whose WorkloadDefinition declares no ready condition are considered ready
+// once they are applied, unless a HealthScope considers them unhealthy. prev
+// is the phase before the reconciliation. Workloads that did not join some of
+// their scopes because they would overlap are reported by the ScopeOverlap
+// condition.
+func updatePhase(ac *v1alpha2.ApplicationConfiguration, prev v1alpha2.ApplicationStatus, workloads []Workload) {
+	var notReady, unobserved, conflicting, overlapping []string
+	for i, w := range workloads {
+		ws := ac.Status.Workloads[i]
+		if ws.GetCondition(v1alpha2.TypeTraitsConflict).Status == corev1.ConditionTrue {
+			conflicting = append(conflicting, ws.ComponentName)
+		}
+		if ws.GetCondition(v1alpha2.TypeScopeOverlap).Status == corev1.ConditionTrue {
+			overlapping = append(overlapping, ws.ComponentName)
+		}
+		if ws.GetCondition(v1alpha2.TypeHealthy).Status == corev1.ConditionFalse {
+			notReady = append(notReady, ws.ComponentName)
+			continue
+		}
+		if w.StatusProjection == nil || w.StatusProjection.ReadyConditionType == "" {
+			continue
+		}
+		switch ws.GetCondition(runtimev1alpha1.TypeReady).Status {
+		case corev1.ConditionTrue:
+		case corev1.ConditionFalse:
+			notReady = append(notReady, ws.ComponentName)
+		default:
+			unobserved = append(unobserved, ws.ComponentName)
+		}
+	}
+
+	switch {
+	case len(notReady) > 0:
+		ac.SetConditions(runtimev1alpha1.Unavailable().WithMessage(fmt.Sprintf(msgFmtNotReady, strings.Join(notReady, ", "))))
+		// an application that was running is degraded, unless it changed
+		// since and is rolling the change out
+		ac.Status.Status = v1alpha2.ApplicationProgressing
+		if (prev == v1alpha2.ApplicationRunning || prev == v1alpha2.ApplicationDegraded) &&
+			ac.Status.ObservedGeneration == ac.Generation {
+			ac.Status.Status = v1alpha2.ApplicationDegraded
+		}
+	case len(unobserved) > 0:
+		ac.SetConditions(runtimev1alpha1.Unavailable().WithMessage(fmt.Sprintf(msgFmtNotObserved, strings.Join(unobserved, ", "))))
+		ac.Status.Status = v1alpha2.ApplicationApplying
+	case len(conflicting) > 0, len(overlapping) > 0:
+		ac.SetConditions(runtimev1alpha1.Available())
+		ac.Status.Status = v1alpha2.ApplicationDegraded
+	default:
+		ac.SetConditions(runtimev1alpha1.Available())
+		ac.Status.Status = v1alpha2.ApplicationRunning
+	}
+	if len(overlapping) > 0 {
+		ac.SetConditions(v1alpha2.ScopeOverlap(fmt.Sprintf(msgFmtScopesOverlap, strings.Join(overlapping, ", "))))
+	} else {
+		removeCondition(&ac.Status.ConditionedStatus, v1alpha2.TypeScopeOverlap)
+	}
+	if len(ac.Status.Dependency.Unsatisfied) > 0 {
+		ac.Status.Status = v1alpha2.ApplicationWaitingForDependencies
+	}
+}
+
+// project returns the status, message and fields of obj declared by the
+// StatusProjection. Values that are not strings are JSON encoded.
+func project(obj map[string]interface{}, p v1alpha2.StatusProjection) (string, string, map[string]string) {
+	paved := fieldpath.Pave(obj)
+	status := stringAt(paved, p.StatusPath)
+	var message string
+	if p.ReadyConditionType != "" {
+		readyStatus, msg := readyCondition(paved, p.ReadyConditionType)
+		if status == "" {
+			status = StatusNotReady
+			if readyStatus == corev1.ConditionTrue {
+				status = StatusReady
+			}
+		}
+		message = msg
+	}
+	if p.MessagePath != "" {
+		message = stringAt(paved, p.MessagePath)
+	}
+	var fields map[string]string
+	for name, path := range p.FieldPaths {
+		v := stringAt(paved, path)
+		if v == "" {
+			continue
+		}
+		if fields == nil {
+			fields = make(map[string]string, len(p.FieldPaths))
+		}
+		fields[name] = v
+	}
+	return status, message, fields
+}
+
+// readyCondition returns the status and message of the condition of the type
+// in status.conditions, or an empty status if there is no such condition.
+func readyCondition(paved *fieldpath.Paved, conditionType string) (corev1.ConditionStatus, string) {
+	v, err := paved.GetValue("status.conditions")
+	if err != nil {
+		return "", ""
+	}
+	conditions, ok := v.([]interface{})
+	if !ok {
+		return "", ""
+	}
+	for _, c := range conditions {
+		m, ok := c.(map[string]interface{})
+		if !ok || m["type"] != conditionType {
+			continue
+		}
+		status, _ := m["status"].(string)
+		msg, _ := m["message"].(string)
+		return corev1.ConditionStatus(status), msg
+	}
+	return "", ""
+}
+
+func stringAt(paved *fieldpath.Paved, path string) string {
+	if path == "" {
+		return ""
+	}
+	v, err := paved.GetValue(path)
+	if err != nil || v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
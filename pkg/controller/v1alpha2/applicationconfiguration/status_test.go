@@ -0,0 +1,551 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationconfiguration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+)
+
+func TestProject(t *testing.T) {
+	obj := map[string]interface{}{
+		"status": map[string]interface{}{
+			"phase": "Running",
+			"url":   "http://example.com",
+			"ports": []interface{}{int64(80)},
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Available", "status": "True", "message": "all replicas available"},
+				map[string]interface{}{"type": "Progressing", "status": "False", "message": "rollout stalled"},
+			},
+		},
+	}
+
+	type want struct {
+		status  string
+		message string
+		fields  map[string]string
+	}
+	cases := map[string]struct {
+		p    v1alpha2.StatusProjection
+		want want
+	}{
+		"StatusPath": {
+			p:    v1alpha2.StatusProjection{StatusPath: "status.phase"},
+			want: want{status: "Running"},
+		},
+		"ReadyCondition": {
+			p:    v1alpha2.StatusProjection{ReadyConditionType: "Available"},
+			want: want{status: StatusReady, message: "all replicas available"},
+		},
+		"NotReadyCondition": {
+			p:    v1alpha2.StatusProjection{ReadyConditionType: "Progressing"},
+			want: want{status: StatusNotReady, message: "rollout stalled"},
+		},
+		"MissingCondition": {
+			p:    v1alpha2.StatusProjection{ReadyConditionType: "Ready"},
+			want: want{status: StatusNotReady},
+		},
+		"StatusPathTakesPrecedence": {
+			p:    v1alpha2.StatusProjection{StatusPath: "status.phase", ReadyConditionType: "Progressing", MessagePath: "status.url"},
+			want: want{status: "Running", message: "http://example.com"},
+		},
+		"FallBackToReadyCondition": {
+			p:    v1alpha2.StatusProjection{StatusPath: "status.state", ReadyConditionType: "Available"},
+			want: want{status: StatusReady, message: "all replicas available"},
+		},
+		"Fields": {
+			p: v1alpha2.StatusProjection{FieldPaths: map[string]string{
+				"endpoint": "status.url",
+				"ports":    "status.ports",
+				"missing":  "status.missing",
+			}},
+			want: want{fields: map[string]string{"endpoint": "http://example.com", "ports": "[80]"}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got want
+			got.status, got.message, got.fields = project(obj, tc.p)
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("project(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	conditions := func(c ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"status": map[string]interface{}{"conditions": c}}
+	}
+	unknown := runtimev1alpha1.Condition{Type: runtimev1alpha1.TypeReady, Status: corev1.ConditionUnknown, Reason: reasonReadinessUnknown}
+
+	cases := map[string]struct {
+		obj  map[string]interface{}
+		want runtimev1alpha1.Condition
+	}{
+		"Ready": {
+			obj:  conditions(map[string]interface{}{"type": "Available", "status": "True"}),
+			want: runtimev1alpha1.Available(),
+		},
+		"NotReady": {
+			obj:  conditions(map[string]interface{}{"type": "Available", "status": "False", "message": "scaling up"}),
+			want: runtimev1alpha1.Unavailable().WithMessage("scaling up"),
+		},
+		"NotObserved": {
+			obj:  conditions(map[string]interface{}{"type": "Progressing", "status": "True"}),
+			want: unknown,
+		},
+		"NoStatus": {
+			obj:  map[string]interface{}{},
+			want: unknown,
+		},
+		"Unknown": {
+			obj:  conditions(map[string]interface{}{"type": "Available", "status": "Unknown", "message": "probing"}),
+			want: unknown.WithMessage("probing"),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := readiness(tc.obj, "Available")
+			if !tc.want.Equal(got) {
+				t.Errorf("readiness(...): want %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestProjectStatus(t *testing.T) {
+	workload := unstructured.Unstructured{}
+	workload.SetAPIVersion("apps/v1")
+	workload.SetKind("Deployment")
+	workload.SetNamespace("ns")
+	workload.SetName("web")
+	trait := unstructured.Unstructured{}
+	trait.SetAPIVersion("example.com/v1")
+	trait.SetKind("Route")
+	trait.SetNamespace("ns")
+	trait.SetName("web-route")
+	gone := unstructured.Unstructured{}
+	gone.SetAPIVersion("example.com/v1")
+	gone.SetKind("Gone")
+	gone.SetNamespace("ns")
+	gone.SetName("web-gone")
+	scaler := unstructured.Unstructured{}
+	scaler.SetAPIVersion("core.oam.dev/v1alpha2")
+	scaler.SetKind("ManualScalerTrait")
+	scaler.SetNamespace("ns")
+	scaler.SetName("web-scaler")
+
+	c := &test.MockClient{MockGet: func(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+		u := obj.(*unstructured.Unstructured)
+		switch u.GetKind() {
+		case "Deployment":
+			u.Object["status"] = map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "Available", "status": "True", "message": "ready"},
+			}}
+		case "Route":
+			u.Object["status"] = map[string]interface{}{"url": "http://web.example.com"}
+		case "ManualScalerTrait":
+			u.Object["status"] = map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "Synced", "status": "False", "reason": "ReconcileError", "message": "cannot scale"},
+			}}
+		default:
+			return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
+		}
+		return nil
+	}}
+	w := Workload{
+		ComponentName:    "web",
+		Workload:         &workload,
+		StatusProjection: &v1alpha2.StatusProjection{ReadyConditionType: "Available"},
+		Traits: []*Trait{
+			{Object: trait, Definition: v1alpha2.TraitDefinition{Spec: v1alpha2.TraitDefinitionSpec{
+				StatusProjection: &v1alpha2.StatusProjection{FieldPaths: map[string]string{"url": "status.url"}},
+			}}},
+			{Object: gone, Definition: v1alpha2.TraitDefinition{Spec: v1alpha2.TraitDefinitionSpec{
+				StatusProjection: &v1alpha2.StatusProjection{StatusPath: "status.phase"},
+			}}},
+			{Object: scaler},
+		},
+	}
+	ac := &v1alpha2.ApplicationConfiguration{}
+	ac.Status.Workloads = []v1alpha2.WorkloadStatus{w.Status()}
+	ac.Status.Workloads[0].Traits[1].Status = "Kept"
+
+	record := &recorder{}
+	r := &OAMApplicationReconciler{client: c, log: logging.NewNopLogger(), record: record}
+	r.projectStatus(context.Background(), ac, []Workload{w})
+
+	want := []v1alpha2.WorkloadStatus{{
+		ConditionedStatus: runtimev1alpha1.ConditionedStatus{
+			Conditions: []runtimev1alpha1.Condition{runtimev1alpha1.Available()},
+		},
+		ComponentName: "web",
+		Status:        StatusReady,
+		Message:       "ready",
+		Reference:     runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+		Traits: []v1alpha2.WorkloadTrait{
+			{
+				Reference: runtimev1alpha1.TypedReference{APIVersion: "example.com/v1", Kind: "Route", Name: "web-route"},
+				Fields:    map[string]string{"url": "http://web.example.com"},
+			},
+			{
+				Reference: runtimev1alpha1.TypedReference{APIVersion: "example.com/v1", Kind: "Gone", Name: "web-gone"},
+				Status:    "Kept",
+			},
+			{
+				Reference: runtimev1alpha1.TypedReference{APIVersion: "core.oam.dev/v1alpha2", Kind: "ManualScalerTrait", Name: "web-scaler"},
+				Status:    StatusNotReady,
+				Message:   "Synced: cannot scale",
+			},
+		},
+		Scopes: []v1alpha2.WorkloadScope{},
+	}}
+	if diff := cmp.Diff(want, ac.Status.Workloads); diff != "" {
+		t.Errorf("projectStatus(...): -want, +got:\n%s", diff)
+	}
+	wantEvents := []event.Event{event.Warning(reasonTraitNotReady, errors.New("Synced: cannot scale"))}
+	if diff := cmp.Diff(wantEvents, record.events); diff != "" {
+		t.Errorf("projectStatus(...): -want events, +got events:\n%s", diff)
+	}
+
+	// the status did not change, so no further event is emitted
+	r.projectStatus(context.Background(), ac, []Workload{w})
+	if diff := cmp.Diff(wantEvents, record.events); diff != "" {
+		t.Errorf("projectStatus(...): -want events, +got events:\n%s", diff)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	conditions := func(cs ...map[string]interface{}) map[string]interface{} {
+		l := make([]interface{}, 0, len(cs))
+		for _, c := range cs {
+			l = append(l, c)
+		}
+		return map[string]interface{}{"status": map[string]interface{}{"conditions": l}}
+	}
+	cases := map[string]struct {
+		obj         map[string]interface{}
+		wantStatus  string
+		wantMessage string
+		wantOK      bool
+	}{
+		"NoConditions": {
+			obj: map[string]interface{}{"status": map[string]interface{}{}},
+		},
+		"AllTrue": {
+			obj: conditions(
+				map[string]interface{}{"type": "Synced", "status": "True"},
+				map[string]interface{}{"type": "Ready", "status": "True"},
+			),
+			wantStatus: StatusReady,
+			wantOK:     true,
+		},
+		"NotTrue": {
+			obj: conditions(
+				map[string]interface{}{"type": "Synced", "status": "False", "reason": "ReconcileError", "message": "cannot scale"},
+				map[string]interface{}{"type": "Ready", "status": "Unknown", "reason": "Creating"},
+			),
+			wantStatus:  StatusNotReady,
+			wantMessage: "Synced: cannot scale; Ready: Creating",
+			wantOK:      true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			status, message, ok := summarize(tc.obj)
+			if diff := cmp.Diff(tc.wantStatus, status); diff != "" {
+				t.Errorf("summarize(...): -want status, +got status:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantMessage, message); diff != "" {
+				t.Errorf("summarize(...): -want message, +got message:\n%s", diff)
+			}
+			if ok != tc.wantOK {
+				t.Errorf("summarize(...): want ok %t, got %t", tc.wantOK, ok)
+			}
+		})
+	}
+}
+
+// recorder records the events it is asked to emit.
+type recorder struct {
+	events []event.Event
+}
+
+func (r *recorder) Event(_ runtime.Object, e event.Event) {
+	r.events = append(r.events, e)
+}
+
+func (r *recorder) WithAnnotations(_ ...string) event.Recorder {
+	return r
+}
+
+func TestUpdatePhase(t *testing.T) {
+	declared := &v1alpha2.StatusProjection{ReadyConditionType: "Available"}
+	workloads := []Workload{{ComponentName: "web", StatusProjection: declared}, {ComponentName: "db"}}
+	status := func(ready ...runtimev1alpha1.Condition) []v1alpha2.WorkloadStatus {
+		ws := []v1alpha2.WorkloadStatus{{ComponentName: "web"}, {ComponentName: "db"}}
+		ws[0].SetConditions(ready...)
+		return ws
+	}
+	notReady := runtimev1alpha1.Unavailable().WithMessage(fmt.Sprintf(msgFmtNotReady, "web"))
+
+	cases := map[string]struct {
+		prev        v1alpha2.ApplicationStatus
+		generation  int64
+		workloads   []v1alpha2.WorkloadStatus
+		unsatisfied bool
+		wantPhase   v1alpha2.ApplicationStatus
+		wantReady   runtimev1alpha1.Condition
+		wantOverlap string
+	}{
+		"Running": {
+			workloads: status(runtimev1alpha1.Available()),
+			wantPhase: v1alpha2.ApplicationRunning,
+			wantReady: runtimev1alpha1.Available(),
+		},
+		"Applying": {
+			workloads: status(),
+			wantPhase: v1alpha2.ApplicationApplying,
+			wantReady: runtimev1alpha1.Unavailable().WithMessage(fmt.Sprintf(msgFmtNotObserved, "web")),
+		},
+		"ReadinessUnknown": {
+			workloads: status(readiness(map[string]interface{}{}, "Available")),
+			wantPhase: v1alpha2.ApplicationApplying,
+			wantReady: runtimev1alpha1.Unavailable().WithMessage(fmt.Sprintf(msgFmtNotObserved, "web")),
+		},
+		"Progressing": {
+			prev:      v1alpha2.ApplicationApplying,
+			workloads: status(runtimev1alpha1.Unavailable()),
+			wantPhase: v1alpha2.ApplicationProgressing,
+			wantReady: notReady,
+		},
+		"Degraded": {
+			prev:      v1alpha2.ApplicationRunning,
+			workloads: status(runtimev1alpha1.Unavailable()),
+			wantPhase: v1alpha2.ApplicationDegraded,
+			wantReady: notReady,
+		},
+		"ProgressingAfterChange": {
+			prev:       v1alpha2.ApplicationRunning,
+			generation: 2,
+			workloads:  status(runtimev1alpha1.Unavailable()),
+			wantPhase:  v1alpha2.ApplicationProgressing,
+			wantReady:  notReady,
+		},
+		"TraitsConflict": {
+			workloads: func() []v1alpha2.WorkloadStatus {
+				ws := status(runtimev1alpha1.Available())
+				ws[1].SetConditions(v1alpha2.TraitsConflict("conflict"))
+				return ws
+			}(),
+			wantPhase: v1alpha2.ApplicationDegraded,
+			wantReady: runtimev1alpha1.Available(),
+		},
+		"ScopeOverlap": {
+			workloads: func() []v1alpha2.WorkloadStatus {
+				ws := status(runtimev1alpha1.Available())
+				ws[1].SetConditions(v1alpha2.ScopeOverlap("overlap"))
+				return ws
+			}(),
+			wantPhase:   v1alpha2.ApplicationDegraded,
+			wantReady:   runtimev1alpha1.Available(),
+			wantOverlap: fmt.Sprintf(msgFmtScopesOverlap, "db"),
+		},
+		"WaitingForDependencies": {
+			workloads:   status(runtimev1alpha1.Available()),
+			unsatisfied: true,
+			wantPhase:   v1alpha2.ApplicationWaitingForDependencies,
+			wantReady:   runtimev1alpha1.Available(),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ac := &v1alpha2.ApplicationConfiguration{}
+			ac.Generation = tc.generation
+			ac.Status.Workloads = tc.workloads
+			if tc.unsatisfied {
+				ac.Status.Dependency.Unsatisfied = []v1alpha2.UnstaifiedDependency{{Reason: "not ready"}}
+			}
+			updatePhase(ac, tc.prev, workloads)
+			if diff := cmp.Diff(tc.wantPhase, ac.Status.Status); diff != "" {
+				t.Errorf("updatePhase(...): -want phase, +got phase:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantReady, ac.GetCondition(runtimev1alpha1.TypeReady)); diff != "" {
+				t.Errorf("updatePhase(...): -want ready, +got ready:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantOverlap, ac.GetCondition(v1alpha2.TypeScopeOverlap).Message); diff != "" {
+				t.Errorf("updatePhase(...): -want overlap, +got overlap:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWorkloadHealth(t *testing.T) {
+	ref := runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"}
+	healthy := func(apiVersion string) *v1alpha2.WorkloadHealthCondition {
+		return &v1alpha2.WorkloadHealthCondition{
+			TargetWorkload: runtimev1alpha1.TypedReference{APIVersion: apiVersion, Kind: "Deployment", Name: "web"},
+			HealthStatus:   v1alpha2.StatusHealthy,
+		}
+	}
+	notProbed := v1alpha2.WorkloadHealthCondition{ComponentName: "web", TargetWorkload: ref, HealthStatus: v1alpha2.StatusUnknown, Diagnosis: msgNotProbed}
+
+	cases := map[string]struct {
+		conditions []*v1alpha2.WorkloadHealthCondition
+		want       v1alpha2.WorkloadHealthCondition
+	}{
+		"SameResource": {
+			conditions: []*v1alpha2.WorkloadHealthCondition{nil, healthy("apps/v1")},
+			want:       *healthy("apps/v1"),
+		},
+		"OtherVersion": {
+			conditions: []*v1alpha2.WorkloadHealthCondition{healthy("apps/v1beta2")},
+			want:       *healthy("apps/v1beta2"),
+		},
+		"OtherGroup": {
+			conditions: []*v1alpha2.WorkloadHealthCondition{healthy("example.com/v1")},
+			want:       notProbed,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			hs := &v1alpha2.HealthScope{Status: v1alpha2.HealthScopeStatus{WorkloadHealthConditions: tc.conditions}}
+			if diff := cmp.Diff(tc.want, workloadHealth(hs, "web", ref)); diff != "" {
+				t.Errorf("workloadHealth(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAggregateHealth(t *testing.T) {
+	healthScope := func(name string, conditions ...*v1alpha2.WorkloadHealthCondition) unstructured.Unstructured {
+		hs := &v1alpha2.HealthScope{Status: v1alpha2.HealthScopeStatus{WorkloadHealthConditions: conditions}}
+		obj, _ := runtime.DefaultUnstructuredConverter.ToUnstructured(hs)
+		u := unstructured.Unstructured{Object: obj}
+		u.SetGroupVersionKind(v1alpha2.HealthScopeGroupVersionKind)
+		u.SetName(name)
+		return u
+	}
+	otherScope := unstructured.Unstructured{}
+	otherScope.SetAPIVersion("example.com/v1")
+	otherScope.SetKind("NetworkScope")
+	otherScope.SetName("network")
+	ref := func(name string) runtimev1alpha1.TypedReference {
+		return runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: name}
+	}
+	workload := func(name string, scopes ...unstructured.Unstructured) Workload {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("apps/v1")
+		u.SetKind("Deployment")
+		u.SetName(name)
+		return Workload{ComponentName: name, Workload: u, Scopes: scopes}
+	}
+	health := func(name string, status v1alpha2.HealthStatus, diagnosis string) *v1alpha2.WorkloadHealthCondition {
+		return &v1alpha2.WorkloadHealthCondition{TargetWorkload: ref(name), HealthStatus: status, Diagnosis: diagnosis}
+	}
+
+	cases := map[string]struct {
+		workloads     []Workload
+		wantScopes    [][]v1alpha2.WorkloadScope
+		wantWorkloads []runtimev1alpha1.Condition
+		wantApp       runtimev1alpha1.Condition
+	}{
+		"NoHealthScopes": {
+			workloads:     []Workload{workload("web", otherScope)},
+			wantScopes:    [][]v1alpha2.WorkloadScope{{{Reference: runtimev1alpha1.TypedReference{APIVersion: "example.com/v1", Kind: "NetworkScope", Name: "network"}}}},
+			wantWorkloads: []runtimev1alpha1.Condition{{Type: v1alpha2.TypeHealthy, Status: "Unknown"}},
+			wantApp:       runtimev1alpha1.Condition{Type: v1alpha2.TypeHealthy, Status: "Unknown"},
+		},
+		"Healthy": {
+			workloads: []Workload{workload("web", healthScope("health", health("web", v1alpha2.StatusHealthy, "")))},
+			wantScopes: [][]v1alpha2.WorkloadScope{{{
+				Status:    v1alpha2.ScopeStatus(v1alpha2.StatusHealthy),
+				Reference: runtimev1alpha1.TypedReference{APIVersion: v1alpha2.SchemeGroupVersion.String(), Kind: v1alpha2.HealthScopeKind, Name: "health"},
+			}}},
+			wantWorkloads: []runtimev1alpha1.Condition{v1alpha2.Healthy()},
+			wantApp:       v1alpha2.Healthy(),
+		},
+		"LeastHealthyScopeWins": {
+			workloads: []Workload{
+				workload("web",
+					healthScope("a", health("web", v1alpha2.StatusHealthy, "")),
+					healthScope("b", health("web", v1alpha2.StatusUnhealthy, "no ready replicas"))),
+				workload("db", healthScope("a")),
+			},
+			wantScopes: [][]v1alpha2.WorkloadScope{
+				{
+					{
+						Status:    v1alpha2.ScopeStatus(v1alpha2.StatusHealthy),
+						Reference: runtimev1alpha1.TypedReference{APIVersion: v1alpha2.SchemeGroupVersion.String(), Kind: v1alpha2.HealthScopeKind, Name: "a"},
+					},
+					{
+						Status:    v1alpha2.ScopeStatus(v1alpha2.StatusUnhealthy),
+						Reference: runtimev1alpha1.TypedReference{APIVersion: v1alpha2.SchemeGroupVersion.String(), Kind: v1alpha2.HealthScopeKind, Name: "b"},
+						Diagnosis: "no ready replicas",
+					},
+				},
+				{{
+					Status:    v1alpha2.ScopeStatus(v1alpha2.StatusUnknown),
+					Reference: runtimev1alpha1.TypedReference{APIVersion: v1alpha2.SchemeGroupVersion.String(), Kind: v1alpha2.HealthScopeKind, Name: "a"},
+					Diagnosis: msgNotProbed,
+				}},
+			},
+			wantWorkloads: []runtimev1alpha1.Condition{
+				v1alpha2.Unhealthy(`HealthScope "b": no ready replicas`),
+				v1alpha2.HealthUnknown(`HealthScope "a": ` + msgNotProbed),
+			},
+			wantApp: v1alpha2.Unhealthy(fmt.Sprintf(msgFmtUnhealthy, "web")),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ac := &v1alpha2.ApplicationConfiguration{}
+			for _, w := range tc.workloads {
+				ac.Status.Workloads = append(ac.Status.Workloads, w.Status())
+			}
+			aggregateHealth(ac, tc.workloads)
+			for i := range tc.workloads {
+				if diff := cmp.Diff(tc.wantScopes[i], ac.Status.Workloads[i].Scopes); diff != "" {
+					t.Errorf("aggregateHealth(...): -want scopes, +got scopes:\n%s", diff)
+				}
+				if diff := cmp.Diff(tc.wantWorkloads[i], ac.Status.Workloads[i].GetCondition(v1alpha2.TypeHealthy)); diff != "" {
+					t.Errorf("aggregateHealth(...): -want workload health, +got workload health:\n%s", diff)
+				}
+			}
+			if diff := cmp.Diff(tc.wantApp, ac.GetCondition(v1alpha2.TypeHealthy)); diff != "" {
+				t.Errorf("aggregateHealth(...): -want app health, +got app health:\n%s", diff)
+			}
+		})
+	}
+}
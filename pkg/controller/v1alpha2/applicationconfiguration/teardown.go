@@ -0,0 +1,193 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationconfiguration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
+)
+
+const teardownFinalizer = "teardown.finalizer.core.oam.dev"
+
+const (
+	errFmtGetTeardown    = "cannot get %s %q to tear it down"
+	errFmtDeleteTeardown = "cannot delete %s %q to tear it down"
+
+	msgFmtTeardown         = "waiting for step %d of %d of the teardown: %s"
+	msgFmtTeardownTimedOut = "teardown did not finish within %s, leaving the remaining resources to garbage collection"
+)
+
+// Teardown event reasons.
+const (
+	reasonTeardownTimedOut = "TeardownTimedOut"
+)
+
+// teardownSteps returns the resources of an ApplicationConfiguration to be
+// deleted one step after the other. The traits of all components are deleted
+// before their workloads. Within both, the resources of components are deleted
+// before those of the components they depend on, so that e.g. a frontend is
+// gone before its backend. The workloads of earlier revisions of a component
+// are deleted along with its current workload. Resources whose deletion
+// policy is not Delete are released instead, and are not part of the
+// teardown.
+func teardownSteps(ac *v1alpha2.ApplicationConfiguration) [][]runtimev1alpha1.TypedReference {
+	levels := teardownLevels(ac)
+	depth := 0
+	for _, l := range levels {
+		if l >= depth {
+			depth = l + 1
+		}
+	}
+	traits := make([][]runtimev1alpha1.TypedReference, depth)
+	workloads := make([][]runtimev1alpha1.TypedReference, depth)
+	policies := make(map[string]v1alpha2.DeletionPolicy, len(ac.Status.Workloads))
+	for _, ws := range ac.Status.Workloads {
+		l := levels[ws.ComponentName]
+		for _, ts := range ws.Traits {
+			if deletionPolicy(ts.DeletionPolicy, "") == v1alpha2.DeletionPolicyDelete {
+				traits[l] = append(traits[l], ts.Reference)
+			}
+		}
+		policies[ws.ComponentName] = ws.DeletionPolicy
+		if deletionPolicy(ws.DeletionPolicy, "") == v1alpha2.DeletionPolicyDelete {
+			workloads[l] = append(workloads[l], ws.Reference)
+		}
+	}
+	for _, hw := range ac.Status.HistoryWorkloads {
+		// the revision of a history workload is the revision of its component
+		c := util.ExtractComponentName(hw.Revision)
+		if deletionPolicy(policies[c], "") == v1alpha2.DeletionPolicyDelete {
+			workloads[levels[c]] = append(workloads[levels[c]], hw.Reference)
+		}
+	}
+	steps := make([][]runtimev1alpha1.TypedReference, 0, 2*depth)
+	for _, step := range append(traits, workloads...) {
+		if len(step) > 0 {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// teardownLevels returns the level of each component of an
+// ApplicationConfiguration in its dependency graph. Components no other
+// component depends on are at level 0, the components they depend on at level
+// 1 and so on.
+func teardownLevels(ac *v1alpha2.ApplicationConfiguration) map[string]int {
+	producers := make(map[string]string)
+	for _, acc := range ac.Spec.Components {
+		for _, o := range acc.DataOutputs {
+			producers[o.Name] = acc.ComponentName
+		}
+		for _, ct := range acc.Traits {
+			for _, o := range ct.DataOutputs {
+				producers[o.Name] = acc.ComponentName
+			}
+		}
+	}
+	// dependencies of each component
+	deps := make(map[string][]string)
+	for _, acc := range ac.Spec.Components {
+		inputs := append([]v1alpha2.DataInput{}, acc.DataInputs...)
+		for _, ct := range acc.Traits {
+			inputs = append(inputs, ct.DataInputs...)
+		}
+		for _, in := range inputs {
+			if p, ok := producers[in.ValueFrom.DataOutputName]; ok && p != acc.ComponentName {
+				deps[acc.ComponentName] = append(deps[acc.ComponentName], p)
+			}
+		}
+	}
+
+	levels := make(map[string]int, len(ac.Spec.Components))
+	// a component is at least one level above each of its dependents; at most
+	// as many passes as there are components are needed, which also bounds
+	// the passes if the dependencies are cyclic
+	for i := 0; i < len(ac.Spec.Components); i++ {
+		changed := false
+		for c, ds := range deps {
+			for _, d := range ds {
+				if levels[d] < levels[c]+1 && levels[c]+1 < len(ac.Spec.Components) {
+					levels[d] = levels[c] + 1
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return levels
+}
+
+// teardown deletes the workloads and traits of an ApplicationConfiguration that
+// is being deleted in order, and reports the progress in its status. It
+// returns true once all of them are gone, or once the teardown timed out.
+func (r *OAMApplicationReconciler) teardown(ctx context.Context, ac *v1alpha2.ApplicationConfiguration) (bool, error) {
+	if ac.DeletionTimestamp != nil && time.Since(ac.DeletionTimestamp.Time) > r.teardownTimeout {
+		r.record.Event(ac, event.Warning(reasonTeardownTimedOut, errors.Errorf(msgFmtTeardownTimedOut, r.teardownTimeout)))
+		ac.Status.Teardown = nil
+		return true, nil
+	}
+
+	steps := teardownSteps(ac)
+	for i, step := range steps {
+		pending := make([]runtimev1alpha1.TypedReference, 0, len(step))
+		for _, ref := range step {
+			u := util.ObjectOf(ac.GetNamespace(), ref)
+			err := r.client.Get(ctx, types.NamespacedName{Namespace: u.GetNamespace(), Name: u.GetName()}, u)
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return false, errors.Wrapf(err, errFmtGetTeardown, ref.Kind, ref.Name)
+			}
+			pending = append(pending, ref)
+			if u.GetDeletionTimestamp() != nil {
+				continue
+			}
+			if err := r.client.Delete(ctx, u); resource.IgnoreNotFound(err) != nil {
+				return false, errors.Wrapf(err, errFmtDeleteTeardown, ref.Kind, ref.Name)
+			}
+		}
+		if len(pending) == 0 {
+			continue
+		}
+		ac.Status.Teardown = &v1alpha2.TeardownStatus{Step: i + 1, Steps: len(steps), Pending: pending}
+		names := make([]string, 0, len(pending))
+		for _, ref := range pending {
+			names = append(names, fmt.Sprintf("%s %q", ref.Kind, ref.Name))
+		}
+		ac.SetConditions(runtimev1alpha1.Deleting().WithMessage(
+			fmt.Sprintf(msgFmtTeardown, i+1, len(steps), strings.Join(names, ", "))))
+		return false, nil
+	}
+	ac.Status.Teardown = nil
+	return true, nil
+}
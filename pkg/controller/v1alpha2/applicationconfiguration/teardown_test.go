@@ -0,0 +1,194 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationconfiguration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/mock"
+)
+
+// teardownAppConfig returns an ApplicationConfiguration whose frontend
+// component depends on its backend component, which depends on its database
+// component.
+func teardownAppConfig() *v1alpha2.ApplicationConfiguration {
+	ref := func(name string) runtimev1alpha1.TypedReference {
+		return runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: name}
+	}
+	input := func(name string) []v1alpha2.DataInput {
+		return []v1alpha2.DataInput{{ValueFrom: v1alpha2.DataInputValueFrom{DataOutputName: name}}}
+	}
+	ac := &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", UID: "ac"}}
+	ac.Spec.Components = []v1alpha2.ApplicationConfigurationComponent{
+		{ComponentName: "db", DataOutputs: []v1alpha2.DataOutput{{Name: "db-host"}}},
+		{ComponentName: "frontend", Traits: []v1alpha2.ComponentTrait{{DataInputs: input("backend-host")}}},
+		{
+			ComponentName: "backend",
+			DataInputs:    input("db-host"),
+			Traits:        []v1alpha2.ComponentTrait{{DataOutputs: []v1alpha2.DataOutput{{Name: "backend-host"}}}},
+		},
+	}
+	ac.Status.Workloads = []v1alpha2.WorkloadStatus{
+		{ComponentName: "db", Reference: ref("db"), DeletionPolicy: v1alpha2.DeletionPolicyRetain},
+		{
+			ComponentName: "frontend",
+			Reference:     ref("frontend"),
+			Traits:        []v1alpha2.WorkloadTrait{{Reference: ref("frontend-route")}},
+		},
+		{
+			ComponentName: "backend",
+			Reference:     ref("backend"),
+			Traits: []v1alpha2.WorkloadTrait{
+				{Reference: ref("backend-service")},
+				{Reference: ref("backend-volume"), DeletionPolicy: v1alpha2.DeletionPolicyOrphan},
+			},
+		},
+	}
+	return ac
+}
+
+func TestTeardownSteps(t *testing.T) {
+	ref := func(name string) runtimev1alpha1.TypedReference {
+		return runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: name}
+	}
+	ac := teardownAppConfig()
+	ac.Status.HistoryWorkloads = []v1alpha2.HistoryWorkload{
+		{Revision: "backend-v1", Reference: ref("backend-v1")},
+		{Revision: "db-v1", Reference: ref("db-v1")},
+	}
+	want := [][]runtimev1alpha1.TypedReference{
+		{ref("frontend-route")},
+		{ref("backend-service")},
+		{ref("frontend")},
+		{ref("backend"), ref("backend-v1")},
+	}
+	if diff := cmp.Diff(want, teardownSteps(ac)); diff != "" {
+		t.Errorf("teardownSteps(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestTeardownLevelsCycle(t *testing.T) {
+	input := func(name string) []v1alpha2.DataInput {
+		return []v1alpha2.DataInput{{ValueFrom: v1alpha2.DataInputValueFrom{DataOutputName: name}}}
+	}
+	ac := &v1alpha2.ApplicationConfiguration{}
+	ac.Spec.Components = []v1alpha2.ApplicationConfigurationComponent{
+		{ComponentName: "a", DataOutputs: []v1alpha2.DataOutput{{Name: "a"}}, DataInputs: input("b")},
+		{ComponentName: "b", DataOutputs: []v1alpha2.DataOutput{{Name: "b"}}, DataInputs: input("a")},
+	}
+	for c, l := range teardownLevels(ac) {
+		if l >= len(ac.Spec.Components) {
+			t.Errorf("teardownLevels(...): level %d of component %q exceeds the number of components", l, c)
+		}
+	}
+}
+
+func TestTeardown(t *testing.T) {
+	ref := func(name string) runtimev1alpha1.TypedReference {
+		return runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: name}
+	}
+	l := &liveObjects{objects: map[string]*unstructured.Unstructured{}}
+	for _, name := range []string{"frontend-route", "backend-service", "frontend", "backend"} {
+		l.objects[name] = liveObject(name, nil, "ac")
+	}
+	r := &OAMApplicationReconciler{client: l.client(), record: event.NewNopRecorder(), teardownTimeout: time.Minute}
+	ac := teardownAppConfig()
+	now := metav1.Now()
+	ac.SetDeletionTimestamp(&now)
+
+	// each step waits for the resources of the previous step to be gone
+	for i, name := range []string{"frontend-route", "backend-service", "frontend", "backend"} {
+		done, err := r.teardown(context.Background(), ac)
+		if err != nil {
+			t.Fatalf("r.teardown(...): %s", err)
+		}
+		if done {
+			t.Fatalf("r.teardown(...): done before %q was deleted", name)
+		}
+		if diff := cmp.Diff([]string{name}, l.deleted); diff != "" {
+			t.Errorf("r.teardown(...): -want deleted, +got deleted:\n%s", diff)
+		}
+		want := &v1alpha2.TeardownStatus{Step: i + 1, Steps: 4, Pending: []runtimev1alpha1.TypedReference{ref(name)}}
+		if diff := cmp.Diff(want, ac.Status.Teardown); diff != "" {
+			t.Errorf("r.teardown(...): -want teardown status, +got teardown status:\n%s", diff)
+		}
+		wantCondition := runtimev1alpha1.Deleting().WithMessage(fmt.Sprintf(msgFmtTeardown, i+1, 4, fmt.Sprintf("Deployment %q", name)))
+		if diff := cmp.Diff(wantCondition, ac.GetCondition(runtimev1alpha1.TypeReady)); diff != "" {
+			t.Errorf("r.teardown(...): -want condition, +got condition:\n%s", diff)
+		}
+		delete(l.objects, name)
+		l.deleted = nil
+	}
+
+	done, err := r.teardown(context.Background(), ac)
+	if err != nil {
+		t.Fatalf("r.teardown(...): %s", err)
+	}
+	if !done || ac.Status.Teardown != nil {
+		t.Errorf("r.teardown(...): want teardown to be done, got %t, %v", done, ac.Status.Teardown)
+	}
+}
+
+func TestTeardownTimeout(t *testing.T) {
+	l := &liveObjects{objects: map[string]*unstructured.Unstructured{"frontend-route": liveObject("frontend-route", nil, "ac")}}
+	r := &OAMApplicationReconciler{client: l.client(), record: event.NewNopRecorder(), teardownTimeout: time.Minute}
+	ac := teardownAppConfig()
+	deleted := metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	ac.SetDeletionTimestamp(&deleted)
+
+	done, err := r.teardown(context.Background(), ac)
+	if err != nil {
+		t.Fatalf("r.teardown(...): %s", err)
+	}
+	if !done {
+		t.Errorf("r.teardown(...): want a timed out teardown to be done")
+	}
+	if len(l.deleted) != 0 {
+		t.Errorf("r.teardown(...): want no deletion after the timeout, got %v", l.deleted)
+	}
+}
+
+func TestRegisterTeardownFinalizer(t *testing.T) {
+	cases := map[string]struct {
+		o    []ReconcilerOption
+		want bool
+	}{
+		"Default":         {want: false},
+		"TeardownTimeout": {o: []ReconcilerOption{WithTeardownTimeout(time.Minute)}, want: true},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := NewReconciler(&mock.Manager{}, nil, tc.o...)
+			ac := teardownAppConfig()
+			r.registerFinalizers(ac)
+			if got := meta.FinalizerExists(&ac.ObjectMeta, teardownFinalizer); got != tc.want {
+				t.Errorf("r.registerFinalizers(...): want teardown finalizer %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
@@ -78,7 +78,9 @@ type WorkloadHealthCondition = v1alpha2.WorkloadHealthCondition
 type ScopeHealthCondition = v1alpha2.ScopeHealthCondition
 
 // A WorloadHealthChecker checks health status of specified resource
-// and saves status into an HealthCondition object.
+// and saves status into an HealthCondition object. Checkers match workloads
+// by group and kind, so that workloads referenced by any served version of
+// their kind are checked.
 type WorloadHealthChecker interface {
 	Check(context.Context, client.Client, runtimev1alpha1.TypedReference, string) *WorkloadHealthCondition
 }
@@ -118,7 +120,7 @@ func (fn WorkloadHealthCheckFn) Check(ctx context.Context, c client.Client, tr r
 
 // CheckContainerziedWorkloadHealth check health condition of ContainerizedWorkload
 func CheckContainerziedWorkloadHealth(ctx context.Context, c client.Client, ref runtimev1alpha1.TypedReference, namespace string) *WorkloadHealthCondition {
-	if ref.GroupVersionKind() != corev1alpha2.SchemeGroupVersion.WithKind(kindContainerizedWorkload) {
+	if ref.GroupVersionKind().GroupKind() != corev1alpha2.SchemeGroupVersion.WithKind(kindContainerizedWorkload).GroupKind() {
 		return nil
 	}
 	r := &WorkloadHealthCondition{
@@ -181,7 +183,7 @@ func updateChildResourcesCondition(ctx context.Context, c client.Client, namespa
 
 // CheckDeploymentHealth checks health condition of Deployment
 func CheckDeploymentHealth(ctx context.Context, client client.Client, ref runtimev1alpha1.TypedReference, namespace string) *WorkloadHealthCondition {
-	if ref.GroupVersionKind() != apps.SchemeGroupVersion.WithKind(kindDeployment) {
+	if ref.GroupVersionKind().GroupKind() != apps.SchemeGroupVersion.WithKind(kindDeployment).GroupKind() {
 		return nil
 	}
 	r := &WorkloadHealthCondition{
@@ -215,7 +217,7 @@ func CheckDeploymentHealth(ctx context.Context, client client.Client, ref runtim
 
 // CheckStatefulsetHealth checks health condition of StatefulSet
 func CheckStatefulsetHealth(ctx context.Context, client client.Client, ref runtimev1alpha1.TypedReference, namespace string) *WorkloadHealthCondition {
-	if ref.GroupVersionKind() != apps.SchemeGroupVersion.WithKind(kindStatefulSet) {
+	if ref.GroupVersionKind().GroupKind() != apps.SchemeGroupVersion.WithKind(kindStatefulSet).GroupKind() {
 		return nil
 	}
 	r := &WorkloadHealthCondition{
@@ -248,7 +250,7 @@ func CheckStatefulsetHealth(ctx context.Context, client client.Client, ref runti
 
 // CheckDaemonsetHealth checks health condition of DaemonSet
 func CheckDaemonsetHealth(ctx context.Context, client client.Client, ref runtimev1alpha1.TypedReference, namespace string) *WorkloadHealthCondition {
-	if ref.GroupVersionKind() != apps.SchemeGroupVersion.WithKind(kindDaemonSet) {
+	if ref.GroupVersionKind().GroupKind() != apps.SchemeGroupVersion.WithKind(kindDaemonSet).GroupKind() {
 		return nil
 	}
 	r := &WorkloadHealthCondition{
@@ -193,6 +193,8 @@ func TestCheckDeploymentHealth(t *testing.T) {
 	mockClient := test.NewMockClient()
 	deployRef := runtimev1alpha1.TypedReference{}
 	deployRef.SetGroupVersionKind(apps.SchemeGroupVersion.WithKind(kindDeployment))
+	deployV1beta2Ref := runtimev1alpha1.TypedReference{APIVersion: "apps/v1beta2", Kind: kindDeployment}
+	otherGroupRef := runtimev1alpha1.TypedReference{APIVersion: "example.com/v1", Kind: kindDeployment}
 
 	tests := []struct {
 		caseName  string
@@ -225,6 +227,31 @@ func TestCheckDeploymentHealth(t *testing.T) {
 				HealthStatus: StatusHealthy,
 			},
 		},
+		{
+			caseName: "not matched group",
+			wlRef:    otherGroupRef,
+			expect:   nil,
+		},
+		{
+			caseName: "healthy workload of another version",
+			wlRef:    deployV1beta2Ref,
+			mockGetFn: func(ctx context.Context, key types.NamespacedName, obj runtime.Object) error {
+				if o, ok := obj.(*apps.Deployment); ok {
+					*o = apps.Deployment{
+						Spec: apps.DeploymentSpec{
+							Replicas: &varInt1,
+						},
+						Status: apps.DeploymentStatus{
+							ReadyReplicas: 1, // healthy
+						},
+					}
+				}
+				return nil
+			},
+			expect: &WorkloadHealthCondition{
+				HealthStatus: StatusHealthy,
+			},
+		},
 		{
 			caseName: "unhealthy for deployment not ready",
 			wlRef:    deployRef,
@@ -24,7 +24,7 @@ var (
 
 // CheckPodSpecWorkloadHealth check health condition of podspecworkloads.standard.oam.dev
 func CheckPodSpecWorkloadHealth(ctx context.Context, c client.Client, ref runtimev1alpha1.TypedReference, namespace string) *WorkloadHealthCondition {
-	if ref.GroupVersionKind() != podSpecWorkloadGVK {
+	if ref.GroupVersionKind().GroupKind() != podSpecWorkloadGVK.GroupKind() {
 		return nil
 	}
 	r := &WorkloadHealthCondition{
@@ -0,0 +1,156 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package history reads the revision history of OAM Components and compares
+// their revisions.
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/jsondiff"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
+)
+
+const (
+	errListRevisions     = "cannot list the revisions of the component"
+	errFmtUnpackRevision = "cannot unpack revision %q"
+	errFmtNoRevision     = "component has no revision %q"
+	errFmtUnmarshal      = "cannot unmarshal the %s of revision %q"
+)
+
+// A ComponentRevision is a revision of a Component.
+type ComponentRevision struct {
+	// Name of the ControllerRevision.
+	Name string
+
+	// Revision number.
+	Revision int64
+
+	// Created is when the revision was created.
+	Created metav1.Time
+
+	// Reused is when the revision last became the latest revision again
+	// because the Component was reverted to it, if it ever did.
+	Reused *metav1.Time
+
+	// ChangeCause of the revision, if the Component recorded one.
+	ChangeCause string
+
+	// Author of the revision, if the Component mutating webhook recorded
+	// one.
+	Author string
+
+	// Component as it was at this revision.
+	Component *v1alpha2.Component
+}
+
+// ComponentRevisions returns the revisions of a Component from oldest to
+// newest.
+func ComponentRevisions(ctx context.Context, c client.Reader, namespace, component string) ([]ComponentRevision, error) {
+	l := &appsv1.ControllerRevisionList{}
+	if err := c.List(ctx, l, client.InNamespace(namespace),
+		client.MatchingLabels{oam.ControllerRevisionComponentLabel: component}); err != nil {
+		return nil, errors.Wrap(err, errListRevisions)
+	}
+	revs := make([]ComponentRevision, 0, len(l.Items))
+	for i := range l.Items {
+		rev := &l.Items[i]
+		comp, err := util.UnpackRevisionData(rev)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtUnpackRevision, rev.GetName())
+		}
+		cause, ok := rev.GetAnnotations()[oam.AnnotationChangeCause]
+		if !ok {
+			cause = comp.GetAnnotations()[oam.AnnotationChangeCause]
+		}
+		var reused *metav1.Time
+		if t, err := time.Parse(time.RFC3339, rev.GetAnnotations()[oam.AnnotationReusedAt]); err == nil {
+			reused = &metav1.Time{Time: t}
+		}
+		revs = append(revs, ComponentRevision{
+			Name:        rev.GetName(),
+			Revision:    rev.Revision,
+			Created:     rev.GetCreationTimestamp(),
+			Reused:      reused,
+			ChangeCause: cause,
+			Author:      rev.GetAnnotations()[oam.AnnotationAuthor],
+			Component:   comp,
+		})
+	}
+	sort.Slice(revs, func(i, j int) bool { return revs[i].Revision < revs[j].Revision })
+	return revs, nil
+}
+
+// Find the revision with the supplied name or number.
+func Find(revs []ComponentRevision, nameOrNumber string) (ComponentRevision, error) {
+	for _, rev := range revs {
+		if rev.Name == nameOrNumber || strconv.FormatInt(rev.Revision, 10) == nameOrNumber {
+			return rev, nil
+		}
+	}
+	return ComponentRevision{}, errors.Errorf(errFmtNoRevision, nameOrNumber)
+}
+
+// Diff returns the changes to the workload and parameters of a Component from
+// one revision to another, one line per changed field as described by
+// jsondiff.Diff.
+func Diff(from, to ComponentRevision) ([]string, error) {
+	f, err := comparable(from)
+	if err != nil {
+		return nil, err
+	}
+	t, err := comparable(to)
+	if err != nil {
+		return nil, err
+	}
+	return jsondiff.Diff(f, t), nil
+}
+
+// comparable returns the workload and parameters of a revision decoded from
+// JSON, so that they are compared field by field.
+func comparable(rev ComponentRevision) (map[string]interface{}, error) {
+	var workload interface{}
+	raw, err := rev.Component.Spec.Workload.MarshalJSON()
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtUnmarshal, "workload", rev.Name)
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &workload); err != nil {
+			return nil, errors.Wrapf(err, errFmtUnmarshal, "workload", rev.Name)
+		}
+	}
+	var parameters interface{}
+	raw, err = json.Marshal(rev.Component.Spec.Parameters)
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtUnmarshal, "parameters", rev.Name)
+	}
+	if err := json.Unmarshal(raw, &parameters); err != nil {
+		return nil, errors.Wrapf(err, errFmtUnmarshal, "parameters", rev.Name)
+	}
+	return map[string]interface{}{"workload": workload, "parameters": parameters}, nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+)
+
+func component(image string, parameters ...v1alpha2.ComponentParameter) *v1alpha2.Component {
+	return &v1alpha2.Component{Spec: v1alpha2.ComponentSpec{
+		Workload:   runtime.RawExtension{Raw: []byte(`{"apiVersion":"apps/v1","kind":"Deployment","spec":{"image":"` + image + `"}}`)},
+		Parameters: parameters,
+	}}
+}
+
+func revision(name string, number int64, annotations map[string]string, comp *v1alpha2.Component) appsv1.ControllerRevision {
+	raw, _ := json.Marshal(comp)
+	return appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations},
+		Revision:   number,
+		Data:       runtime.RawExtension{Raw: raw},
+	}
+}
+
+func TestComponentRevisions(t *testing.T) {
+	annotated := component("nginx:1.19")
+	annotated.SetAnnotations(map[string]string{oam.AnnotationChangeCause: "bump nginx"})
+	c := &test.MockClient{
+		MockList: func(_ context.Context, list runtime.Object, _ ...client.ListOption) error {
+			list.(*appsv1.ControllerRevisionList).Items = []appsv1.ControllerRevision{
+				revision("web-v2", 2, nil, annotated),
+				revision("web-v1", 1, map[string]string{oam.AnnotationChangeCause: "initial", oam.AnnotationAuthor: "alice",
+					oam.AnnotationReusedAt: "2020-10-01T12:00:00Z"}, component("nginx:1.18")),
+			}
+			return nil
+		},
+	}
+
+	revs, err := ComponentRevisions(context.Background(), c, "ns", "web")
+	if err != nil {
+		t.Fatalf("ComponentRevisions(...): %s", err)
+	}
+	type summary struct {
+		Name        string
+		Revision    int64
+		ChangeCause string
+		Author      string
+		Reused      string
+	}
+	got := make([]summary, 0, len(revs))
+	for _, rev := range revs {
+		var reused string
+		if rev.Reused != nil {
+			reused = rev.Reused.UTC().Format(time.RFC3339)
+		}
+		got = append(got, summary{rev.Name, rev.Revision, rev.ChangeCause, rev.Author, reused})
+	}
+	want := []summary{{"web-v1", 1, "initial", "alice", "2020-10-01T12:00:00Z"}, {"web-v2", 2, "bump nginx", "", ""}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ComponentRevisions(...): -want, +got:\n%s", diff)
+	}
+
+	for _, nameOrNumber := range []string{"2", "web-v2"} {
+		rev, err := Find(revs, nameOrNumber)
+		if err != nil {
+			t.Fatalf("Find(%q): %s", nameOrNumber, err)
+		}
+		if rev.Name != "web-v2" {
+			t.Errorf("Find(%q): want web-v2, got %s", nameOrNumber, rev.Name)
+		}
+	}
+	if _, err := Find(revs, "3"); cmp.Diff(errors.Errorf(errFmtNoRevision, "3"), err, test.EquateErrors()) != "" {
+		t.Errorf("Find(\"3\"): want error %q, got %v", errors.Errorf(errFmtNoRevision, "3"), err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	from := ComponentRevision{Name: "web-v1", Component: component("nginx:1.18")}
+	to := ComponentRevision{Name: "web-v2", Component: component("nginx:1.19",
+		v1alpha2.ComponentParameter{Name: "image", FieldPaths: []string{"spec.image"}})}
+
+	got, err := Diff(from, to)
+	if err != nil {
+		t.Fatalf("Diff(...): %s", err)
+	}
+	want := []string{
+		`+spec.parameters: [{"fieldPaths":["spec.image"],"name":"image"}]`,
+		`~spec.workload.spec.image: "nginx:1.18" -> "nginx:1.19"`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Diff(...): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jsondiff describes the differences between two objects decoded from
+// JSON.
+package jsondiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// maxValueLength is the longest a value is rendered in a diff before it is
+// truncated.
+const maxValueLength = 128
+
+// Diff returns a compact description of the changes between two specs
+// decoded from JSON, one line per changed field: "+path: value" for added
+// fields, "-path" for removed fields and "~path: old -> new" for changed
+// fields.
+func Diff(oldSpec, newSpec interface{}) []string {
+	var lines []string
+	diff("spec", oldSpec, newSpec, &lines)
+	return lines
+}
+
+func diff(path string, o, n interface{}, lines *[]string) {
+	if reflect.DeepEqual(o, n) {
+		return
+	}
+	switch {
+	case o == nil:
+		*lines = append(*lines, fmt.Sprintf("+%s: %s", path, render(n)))
+		return
+	case n == nil:
+		*lines = append(*lines, "-"+path)
+		return
+	}
+	om, oIsMap := o.(map[string]interface{})
+	nm, nIsMap := n.(map[string]interface{})
+	if oIsMap && nIsMap {
+		keys := make([]string, 0, len(om)+len(nm))
+		for k := range om {
+			keys = append(keys, k)
+		}
+		for k := range nm {
+			if _, ok := om[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			diff(path+"."+k, om[k], nm[k], lines)
+		}
+		return
+	}
+	ol, oIsList := o.([]interface{})
+	nl, nIsList := n.([]interface{})
+	if oIsList && nIsList {
+		for i := 0; i < len(ol) || i < len(nl); i++ {
+			var ov, nv interface{}
+			if i < len(ol) {
+				ov = ol[i]
+			}
+			if i < len(nl) {
+				nv = nl[i]
+			}
+			diff(fmt.Sprintf("%s[%d]", path, i), ov, nv, lines)
+		}
+		return
+	}
+	*lines = append(*lines, fmt.Sprintf("~%s: %s -> %s", path, render(o), render(n)))
+}
+
+func render(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	s := string(b)
+	if len(s) > maxValueLength {
+		s = s[:maxValueLength] + "..."
+	}
+	return s
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsondiff
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiff(t *testing.T) {
+	cases := map[string]struct {
+		oldSpec string
+		newSpec string
+		want    []string
+	}{
+		"Unchanged": {
+			oldSpec: `{"a":1}`,
+			newSpec: `{"a":1}`,
+		},
+		"Created": {
+			oldSpec: `null`,
+			newSpec: `{"a":1}`,
+			want:    []string{`+spec: {"a":1}`},
+		},
+		"Deleted": {
+			oldSpec: `{"a":1}`,
+			newSpec: `null`,
+			want:    []string{"-spec"},
+		},
+		"Fields": {
+			oldSpec: `{"a":1,"b":{"c":"x","d":true}}`,
+			newSpec: `{"b":{"c":"y","d":true},"e":[1]}`,
+			want:    []string{"-spec.a", `~spec.b.c: "x" -> "y"`, "+spec.e: [1]"},
+		},
+		"List": {
+			oldSpec: `{"components":[{"componentName":"a"},{"componentName":"b"}]}`,
+			newSpec: `{"components":[{"componentName":"a","traits":[]}]}`,
+			want:    []string{"+spec.components[0].traits: []", "-spec.components[1]"},
+		},
+		"TypeChanged": {
+			oldSpec: `{"a":"1"}`,
+			newSpec: `{"a":{"b":1}}`,
+			want:    []string{`~spec.a: "1" -> {"b":1}`},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var o, n interface{}
+			if err := json.Unmarshal([]byte(tc.oldSpec), &o); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tc.newSpec), &n); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, Diff(o, n)); diff != "" {
+				t.Errorf("Diff(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
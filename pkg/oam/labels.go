@@ -30,8 +30,29 @@ const (
 
 	// WorkloadTypeLabel indicates the type of the workloadDefinition
 	WorkloadTypeLabel = "workload.oam.dev/type"
+	// LabelPodSpecable indicates whether a workloadDefinition describes a
+	// workload that has a K8s podSpec
+	LabelPodSpecable = "workload.oam.dev/podspecable"
 	// TraitTypeLabel indicates the type of the traitDefinition
 	TraitTypeLabel = "trait.oam.dev/type"
+	// LabelInjectedByTraitPolicy records the name of the TraitPolicy a trait
+	// was attached by
+	LabelInjectedByTraitPolicy = "trait.oam.dev/injected-by"
+)
+
+// Label key strings.
+// The component and AppConfig controllers add these labels to the
+// ControllerRevisions they create.
+const (
+	// ControllerRevisionComponentLabel indicates which component the revision
+	// belongs to. This label is to filter revisions by client api.
+	ControllerRevisionComponentLabel = "controller.oam.dev/component"
+	// ControllerRevisionHashLabel records the hash of the content of a
+	// component revision.
+	ControllerRevisionHashLabel = "controller.oam.dev/hash"
+	// ControllerRevisionAppConfigLabel indicates which ApplicationConfiguration
+	// the revision belongs to.
+	ControllerRevisionAppConfigLabel = "controller.oam.dev/appconfig"
 )
 
 const (
@@ -44,4 +65,32 @@ const (
 const (
 	// AnnotationAppGeneration records the generation of AppConfig
 	AnnotationAppGeneration = "app.oam.dev/generation"
+	// AnnotationForceDelete allows a Component or definition to be deleted
+	// even though ApplicationConfigurations still use it, when set to "true"
+	AnnotationForceDelete = "app.oam.dev/force-delete"
+	// AnnotationRetain keeps a workload or trait with the Retain deletion
+	// policy after it was removed from its AppConfig, until the annotation is
+	// removed
+	AnnotationRetain = "app.oam.dev/retain"
+	// AnnotationOrphaned marks a workload or trait that was deliberately
+	// released by its AppConfig with the Orphan deletion policy
+	AnnotationOrphaned = "app.oam.dev/orphaned"
+	// AnnotationAdopt allows an AppConfig to adopt existing workloads and
+	// traits that are not controlled by anyone, when set to "true" on the
+	// AppConfig or on the Component of the workload
+	AnnotationAdopt = "app.oam.dev/adopt"
+	// AnnotationRollbackTo rolls an AppConfig back to the revision of its
+	// history with the name or number of the annotation's value. Components
+	// are pinned to the component revisions of the revision, as named by the
+	// RolledBack event, until their revisionName is replaced by componentName
+	AnnotationRollbackTo = "app.oam.dev/rollback-to"
+	// AnnotationChangeCause records why a Component was changed, and is
+	// shown in the history of its revisions
+	AnnotationChangeCause = "kubernetes.io/change-cause"
+	// AnnotationAuthor records the user who last changed the spec of a
+	// Component, and is set by the Component mutating webhook
+	AnnotationAuthor = "app.oam.dev/author"
+	// AnnotationReusedAt records when a revision of a Component last became
+	// its latest revision again, because the Component was reverted to it
+	AnnotationReusedAt = "app.oam.dev/reused-at"
 )
@@ -0,0 +1,458 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package orphan finds OAM workloads and traits that outlived their
+// ApplicationConfiguration, and scopes that still reference deleted
+// workloads, and cleans them up.
+package orphan
+
+import (
+	"context"
+	"fmt"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/discoverymapper"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
+)
+
+const (
+	errListDefinitions    = "cannot list definitions"
+	errFmtGetGVK          = "cannot get the kind of definition %q"
+	errFmtList            = "cannot list %s"
+	errFmtGetAppConfig    = "cannot get ApplicationConfiguration %q"
+	errFmtGetWorkloadRefs = "cannot get the workloadRefs of %s %q at %s"
+	errFmtGetWorkload     = "cannot get %s %q referenced by %s %q"
+	errFmtDelete          = "cannot delete %s %q"
+	errFmtGetScope        = "cannot get %s %q"
+	errFmtDereference     = "cannot remove dangling workloadRefs from %s %q"
+
+	reasonFmtNoAppConfig   = "ApplicationConfiguration %q does not exist"
+	reasonFmtNotApplied    = "no longer part of ApplicationConfiguration %q"
+	reasonFmtNotControlled = "not controlled by ApplicationConfiguration %q"
+	reasonWorkloadGone     = "the referenced workload does not exist"
+)
+
+// A Type of finding.
+type Type string
+
+// Types of findings.
+const (
+	// TypeOrphanedResource is a workload or trait whose ApplicationConfiguration
+	// no longer exists, or no longer manages it.
+	TypeOrphanedResource Type = "OrphanedResource"
+
+	// TypeDanglingWorkloadRef is a workloadRef of a scope to a workload that
+	// no longer exists.
+	TypeDanglingWorkloadRef Type = "DanglingWorkloadRef"
+)
+
+// A Finding is an orphaned resource or a dangling workloadRef.
+type Finding struct {
+	Type      Type
+	Namespace string
+
+	// Resource is the orphaned resource, or the scope that references a
+	// workload that no longer exists.
+	Resource runtimev1alpha1.TypedReference
+
+	// WorkloadRef is the dangling workloadRef of a scope.
+	WorkloadRef *runtimev1alpha1.TypedReference
+
+	// Reason the resource was found.
+	Reason string
+}
+
+func (f Finding) String() string {
+	if f.WorkloadRef != nil {
+		return fmt.Sprintf("%s: %s %s/%s references %s %q: %s", f.Type, f.Resource.Kind, f.Namespace, f.Resource.Name,
+			f.WorkloadRef.Kind, f.WorkloadRef.Name, f.Reason)
+	}
+	return fmt.Sprintf("%s: %s %s/%s: %s", f.Type, f.Resource.Kind, f.Namespace, f.Resource.Name, f.Reason)
+}
+
+// A Finder finds orphaned OAM resources and dangling scope references.
+type Finder struct {
+	client client.Client
+	dm     discoverymapper.DiscoveryMapper
+}
+
+// NewFinder returns a Finder that scans the kinds of the registered workload,
+// trait and scope definitions, both cluster and namespaced.
+func NewFinder(c client.Client, dm discoverymapper.DiscoveryMapper) *Finder {
+	return &Finder{client: c, dm: dm}
+}
+
+// Find the orphaned resources and dangling workloadRefs in the supplied
+// namespace, or in all namespaces if it is empty. Workloads and traits are
+// found by the app.oam.dev/name label the ApplicationConfiguration controller
+// adds to them; resources controlled by something other than an
+// ApplicationConfiguration, e.g. the children of a workload, are left to
+// Kubernetes garbage collection, and retained resources are kept on purpose.
+func (f *Finder) Find(ctx context.Context, namespace string) ([]Finding, error) {
+	orphans, err := f.findOrphans(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	dangling, err := f.findDanglingRefs(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return append(orphans, dangling...), nil
+}
+
+func (f *Finder) findOrphans(ctx context.Context, namespace string) ([]Finding, error) {
+	wdl := &v1alpha2.WorkloadDefinitionList{}
+	if err := f.client.List(ctx, wdl); err != nil {
+		return nil, errors.Wrap(err, errListDefinitions)
+	}
+	tdl := &v1alpha2.TraitDefinitionList{}
+	if err := f.client.List(ctx, tdl); err != nil {
+		return nil, errors.Wrap(err, errListDefinitions)
+	}
+	nwdl := &v1alpha2.NamespacedWorkloadDefinitionList{}
+	if err := f.client.List(ctx, nwdl, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrap(err, errListDefinitions)
+	}
+	ntdl := &v1alpha2.NamespacedTraitDefinitionList{}
+	if err := f.client.List(ctx, ntdl, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrap(err, errListDefinitions)
+	}
+	refs := make([]v1alpha2.DefinitionReference, 0, len(wdl.Items)+len(tdl.Items)+len(nwdl.Items)+len(ntdl.Items))
+	for _, wd := range wdl.Items {
+		refs = append(refs, wd.Spec.Reference)
+	}
+	for _, td := range tdl.Items {
+		refs = append(refs, td.Spec.Reference)
+	}
+	for _, wd := range nwdl.Items {
+		refs = append(refs, wd.Spec.Reference)
+	}
+	for _, td := range ntdl.Items {
+		refs = append(refs, td.Spec.Reference)
+	}
+	gvks, err := f.kindsOf(ctx, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	acs := make(map[types.NamespacedName]*v1alpha2.ApplicationConfiguration)
+	var findings []Finding
+	for _, gvk := range gvks {
+		l := &unstructured.UnstructuredList{}
+		l.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := f.client.List(ctx, l, client.InNamespace(namespace), client.HasLabels{oam.LabelAppName}); err != nil {
+			return nil, errors.Wrapf(err, errFmtList, gvk.Kind)
+		}
+		for i := range l.Items {
+			u := &l.Items[i]
+			if _, ok := u.GetAnnotations()[oam.AnnotationRetain]; ok {
+				continue
+			}
+			nn := types.NamespacedName{Namespace: u.GetNamespace(), Name: u.GetLabels()[oam.LabelAppName]}
+			ac, ok := acs[nn]
+			if !ok {
+				ac = &v1alpha2.ApplicationConfiguration{}
+				if err := f.client.Get(ctx, nn, ac); err != nil {
+					if !apierrors.IsNotFound(err) {
+						return nil, errors.Wrapf(err, errFmtGetAppConfig, nn.Name)
+					}
+					ac = nil
+				}
+				acs[nn] = ac
+			}
+			if reason := orphaned(u, nn.Name, ac); reason != "" {
+				findings = append(findings, Finding{
+					Type:      TypeOrphanedResource,
+					Namespace: u.GetNamespace(),
+					Resource:  util.ReferenceTo(u),
+					Reason:    reason,
+				})
+			}
+		}
+	}
+	return findings, nil
+}
+
+// orphaned returns why a workload or trait labelled with the name of an
+// ApplicationConfiguration is orphaned, or nothing if it is not. The supplied
+// ApplicationConfiguration is nil if it does not exist.
+func orphaned(u *unstructured.Unstructured, name string, ac *v1alpha2.ApplicationConfiguration) string {
+	c := metav1.GetControllerOf(u)
+	if c != nil && c.Kind != v1alpha2.ApplicationConfigurationKind {
+		return ""
+	}
+	// released on purpose with the Orphan deletion policy, unless it was
+	// adopted again since
+	if _, ok := u.GetAnnotations()[oam.AnnotationOrphaned]; ok && c == nil {
+		return ""
+	}
+	if ac == nil {
+		return fmt.Sprintf(reasonFmtNoAppConfig, name)
+	}
+	if ac.GetDeletionTimestamp() != nil || references(ac, util.ReferenceTo(u)) {
+		return ""
+	}
+	if c == nil || c.UID != ac.GetUID() {
+		return fmt.Sprintf(reasonFmtNotControlled, name)
+	}
+	// the status may not list resources applied for a newer generation yet
+	if ac.Status.ObservedGeneration != ac.GetGeneration() {
+		return ""
+	}
+	return fmt.Sprintf(reasonFmtNotApplied, name)
+}
+
+// references tells whether the status of an ApplicationConfiguration
+// references the supplied resource.
+func references(ac *v1alpha2.ApplicationConfiguration, ref runtimev1alpha1.TypedReference) bool {
+	for _, ws := range ac.Status.Workloads {
+		if util.SameResource(ws.Reference, ref) {
+			return true
+		}
+		for _, ts := range ws.Traits {
+			if util.SameResource(ts.Reference, ref) {
+				return true
+			}
+		}
+	}
+	for _, hw := range ac.Status.HistoryWorkloads {
+		if util.SameResource(hw.Reference, ref) {
+			return true
+		}
+	}
+	for _, rr := range ac.Status.RetainedResources {
+		if util.SameResource(rr, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// findDanglingRefs scans the scopes of the kinds of the registered scope
+// definitions. A namespaced scope definition only applies to the scopes in its
+// namespace, where it overrides a cluster scope definition of the same kind.
+func (f *Finder) findDanglingRefs(ctx context.Context, namespace string) ([]Finding, error) {
+	sdl := &v1alpha2.ScopeDefinitionList{}
+	if err := f.client.List(ctx, sdl); err != nil {
+		return nil, errors.Wrap(err, errListDefinitions)
+	}
+	nsdl := &v1alpha2.NamespacedScopeDefinitionList{}
+	if err := f.client.List(ctx, nsdl, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrap(err, errListDefinitions)
+	}
+
+	var findings []Finding
+	overridden := make(map[schema.GroupKind]map[string]bool)
+	for _, sd := range nsdl.Items {
+		gvks, err := f.kindsOf(ctx, []v1alpha2.DefinitionReference{sd.Spec.Reference})
+		if err != nil {
+			return nil, err
+		}
+		for _, gvk := range gvks {
+			if overridden[gvk.GroupKind()] == nil {
+				overridden[gvk.GroupKind()] = make(map[string]bool)
+			}
+			overridden[gvk.GroupKind()][sd.GetNamespace()] = true
+			fds, err := f.danglingRefsOf(ctx, gvk, sd.Spec.WorkloadRefsPath, sd.GetNamespace(), nil)
+			if err != nil {
+				return nil, err
+			}
+			findings = append(findings, fds...)
+		}
+	}
+	for _, sd := range sdl.Items {
+		gvks, err := f.kindsOf(ctx, []v1alpha2.DefinitionReference{sd.Spec.Reference})
+		if err != nil {
+			return nil, err
+		}
+		for _, gvk := range gvks {
+			fds, err := f.danglingRefsOf(ctx, gvk, sd.Spec.WorkloadRefsPath, namespace, overridden[gvk.GroupKind()])
+			if err != nil {
+				return nil, err
+			}
+			findings = append(findings, fds...)
+		}
+	}
+	return findings, nil
+}
+
+// danglingRefsOf returns the dangling workloadRefs at the supplied path of the
+// scopes of the supplied kind, skipping the scopes in the supplied namespaces.
+func (f *Finder) danglingRefsOf(ctx context.Context, gvk schema.GroupVersionKind, path, namespace string, skip map[string]bool) ([]Finding, error) {
+	if path == "" {
+		return nil, nil
+	}
+	l := &unstructured.UnstructuredList{}
+	l.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err := f.client.List(ctx, l, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrapf(err, errFmtList, gvk.Kind)
+	}
+	var findings []Finding
+	for i := range l.Items {
+		s := &l.Items[i]
+		if skip[s.GetNamespace()] {
+			continue
+		}
+		refs, err := workloadRefs(s, path)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range refs {
+			w := util.ObjectOf(s.GetNamespace(), ref)
+			err := f.client.Get(ctx, types.NamespacedName{Namespace: w.GetNamespace(), Name: w.GetName()}, w)
+			if err == nil {
+				continue
+			}
+			if !apierrors.IsNotFound(err) {
+				return nil, errors.Wrapf(err, errFmtGetWorkload, ref.Kind, ref.Name, s.GetKind(), s.GetName())
+			}
+			ref := ref
+			findings = append(findings, Finding{
+				Type:        TypeDanglingWorkloadRef,
+				Namespace:   s.GetNamespace(),
+				Resource:    util.ReferenceTo(s),
+				WorkloadRef: &ref,
+				Reason:      reasonWorkloadGone,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// kindsOf returns the kinds the supplied definitions refer to. Definitions of
+// kinds that are not served by the API server are skipped.
+func (f *Finder) kindsOf(ctx context.Context, refs []v1alpha2.DefinitionReference) ([]schema.GroupVersionKind, error) {
+	seen := make(map[schema.GroupKind]bool)
+	gvks := make([]schema.GroupVersionKind, 0, len(refs))
+	for _, ref := range refs {
+		gvk, err := util.GetGVKFromDefinition(ctx, f.client, f.dm, ref)
+		if meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtGetGVK, ref.Name)
+		}
+		if seen[gvk.GroupKind()] {
+			continue
+		}
+		seen[gvk.GroupKind()] = true
+		gvks = append(gvks, gvk)
+	}
+	return gvks, nil
+}
+
+// Clean up the supplied findings. Orphaned resources are deleted, and
+// dangling workloadRefs are removed from their scopes. Nothing is persisted
+// if dryRun is true, but the API server still validates the changes.
+func (f *Finder) Clean(ctx context.Context, findings []Finding, dryRun bool) error {
+	type scope struct {
+		namespace string
+		ref       runtimev1alpha1.TypedReference
+	}
+	dangling := make(map[scope][]runtimev1alpha1.TypedReference)
+	var scopes []scope
+	for _, fd := range findings {
+		switch fd.Type {
+		case TypeOrphanedResource:
+			u := util.ObjectOf(fd.Namespace, fd.Resource)
+			opts := []client.DeleteOption{client.PropagationPolicy(metav1.DeletePropagationBackground)}
+			if dryRun {
+				opts = append(opts, client.DryRunAll)
+			}
+			if err := f.client.Delete(ctx, u, opts...); resource.IgnoreNotFound(err) != nil {
+				return errors.Wrapf(err, errFmtDelete, fd.Resource.Kind, fd.Resource.Name)
+			}
+		case TypeDanglingWorkloadRef:
+			s := scope{namespace: fd.Namespace, ref: fd.Resource}
+			if _, ok := dangling[s]; !ok {
+				scopes = append(scopes, s)
+			}
+			dangling[s] = append(dangling[s], *fd.WorkloadRef)
+		}
+	}
+
+	for _, s := range scopes {
+		if err := f.dereference(ctx, s.namespace, s.ref, dangling[s], dryRun); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dereference removes the supplied workloadRefs from a scope.
+func (f *Finder) dereference(ctx context.Context, namespace string, ref runtimev1alpha1.TypedReference,
+	remove []runtimev1alpha1.TypedReference, dryRun bool) error {
+	s := util.ObjectOf(namespace, ref)
+	if err := f.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, s); err != nil {
+		return errors.Wrapf(resource.IgnoreNotFound(err), errFmtGetScope, ref.Kind, ref.Name)
+	}
+	sd, err := util.FetchScopeDefinition(ctx, f.client, f.dm, namespace, s)
+	if err != nil {
+		return errors.Wrapf(err, errFmtDereference, ref.Kind, ref.Name)
+	}
+	path := sd.Spec.WorkloadRefsPath
+	refs, err := workloadRefs(s, path)
+	if err != nil {
+		return err
+	}
+	kept := make([]interface{}, 0, len(refs))
+	for _, r := range refs {
+		gone := false
+		for _, rm := range remove {
+			if util.SameResource(r, rm) {
+				gone = true
+				break
+			}
+		}
+		if !gone {
+			kept = append(kept, map[string]interface{}{"apiVersion": r.APIVersion, "kind": r.Kind, "name": r.Name})
+		}
+	}
+	if len(kept) == len(refs) {
+		return nil
+	}
+	if err := fieldpath.Pave(s.UnstructuredContent()).SetValue(path, kept); err != nil {
+		return errors.Wrapf(err, errFmtDereference, ref.Kind, ref.Name)
+	}
+	var opts []client.UpdateOption
+	if dryRun {
+		opts = append(opts, client.DryRunAll)
+	}
+	return errors.Wrapf(f.client.Update(ctx, s, opts...), errFmtDereference, ref.Kind, ref.Name)
+}
+
+// workloadRefs returns the workloadRefs of a scope at the supplied path.
+func workloadRefs(s *unstructured.Unstructured, path string) ([]runtimev1alpha1.TypedReference, error) {
+	var refs []runtimev1alpha1.TypedReference
+	err := fieldpath.Pave(s.UnstructuredContent()).GetValueInto(path, &refs)
+	if fieldpath.IsNotFound(err) {
+		return nil, nil
+	}
+	return refs, errors.Wrapf(err, errFmtGetWorkloadRefs, s.GetKind(), s.GetName(), path)
+}
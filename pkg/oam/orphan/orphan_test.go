@@ -0,0 +1,326 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orphan
+
+import (
+	"context"
+	"testing"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/mock"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
+)
+
+var (
+	deployment = runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment"}
+	scaler     = runtimev1alpha1.TypedReference{APIVersion: "core.oam.dev/v1alpha2", Kind: "ManualScalerTrait"}
+	scope      = runtimev1alpha1.TypedReference{APIVersion: "core.oam.dev/v1alpha2", Kind: "HealthScope"}
+)
+
+func named(ref runtimev1alpha1.TypedReference, name string) runtimev1alpha1.TypedReference {
+	ref.Name = name
+	return ref
+}
+
+// labelled returns a resource created for the named ApplicationConfiguration
+// and controlled by the supplied controller, if any.
+func labelled(ref runtimev1alpha1.TypedReference, appConfig string, controller *metav1.OwnerReference) *unstructured.Unstructured {
+	u := util.ObjectOf("ns", ref)
+	u.SetLabels(map[string]string{oam.LabelAppName: appConfig})
+	if controller != nil {
+		u.SetOwnerReferences([]metav1.OwnerReference{*controller})
+	}
+	return u
+}
+
+func controlledBy(kind string, uid types.UID) *metav1.OwnerReference {
+	return &metav1.OwnerReference{Kind: kind, Name: "owner", UID: uid, Controller: pointer.BoolPtr(true)}
+}
+
+// released marks a resource as released with the Orphan deletion policy.
+func released(u *unstructured.Unstructured) *unstructured.Unstructured {
+	u.SetAnnotations(map[string]string{oam.AnnotationOrphaned: "true"})
+	return u
+}
+
+func TestOrphaned(t *testing.T) {
+	web := named(deployment, "web")
+	ac := &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "app", UID: "ac", Generation: 2}}
+	ac.Status.ObservedGeneration = 2
+	ac.Status.Workloads = []v1alpha2.WorkloadStatus{{Reference: web}}
+	ahead := ac.DeepCopy()
+	ahead.Generation = 3
+	deleting := ac.DeepCopy()
+	deleting.DeletionTimestamp = &metav1.Time{}
+
+	cases := map[string]struct {
+		u    *unstructured.Unstructured
+		ac   *v1alpha2.ApplicationConfiguration
+		want string
+	}{
+		"AppConfigGone": {
+			u:    labelled(web, "app", nil),
+			want: `ApplicationConfiguration "app" does not exist`,
+		},
+		"ControlledByWorkload": {
+			u: labelled(web, "app", controlledBy("ContainerizedWorkload", "cw")),
+		},
+		"Applied": {
+			u:  labelled(web, "app", controlledBy(v1alpha2.ApplicationConfigurationKind, "ac")),
+			ac: ac,
+		},
+		"Orphaned": {
+			u:    labelled(named(deployment, "old"), "app", nil),
+			ac:   ac,
+			want: `not controlled by ApplicationConfiguration "app"`,
+		},
+		"Recreated": {
+			u:    labelled(named(deployment, "old"), "app", controlledBy(v1alpha2.ApplicationConfigurationKind, "previous")),
+			ac:   ac,
+			want: `not controlled by ApplicationConfiguration "app"`,
+		},
+		"NotCollected": {
+			u:    labelled(named(deployment, "old"), "app", controlledBy(v1alpha2.ApplicationConfigurationKind, "ac")),
+			ac:   ac,
+			want: `no longer part of ApplicationConfiguration "app"`,
+		},
+		"NewerGeneration": {
+			u:  labelled(named(deployment, "new"), "app", controlledBy(v1alpha2.ApplicationConfigurationKind, "ac")),
+			ac: ahead,
+		},
+		"ReleasedWithOrphanPolicy": {
+			u:  released(labelled(named(deployment, "db"), "app", nil)),
+			ac: ac,
+		},
+		"ReleasedWithOrphanPolicyAndAppConfigGone": {
+			u: released(labelled(named(deployment, "db"), "app", nil)),
+		},
+		"ReleasedWithOrphanPolicyAndAdoptedAgain": {
+			u:    released(labelled(named(deployment, "db"), "app", controlledBy(v1alpha2.ApplicationConfigurationKind, "ac"))),
+			ac:   ac,
+			want: `no longer part of ApplicationConfiguration "app"`,
+		},
+		"AppConfigDeleting": {
+			u:  labelled(named(deployment, "old"), "app", nil),
+			ac: deleting,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := orphaned(tc.u, "app", tc.ac); got != tc.want {
+				t.Errorf("orphaned(...): want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+var (
+	workloadDefinition = v1alpha2.WorkloadDefinitionSpec{
+		Reference: v1alpha2.DefinitionReference{Name: "deployments.apps", Version: "v1"}}
+	traitDefinition = v1alpha2.TraitDefinitionSpec{
+		Reference: v1alpha2.DefinitionReference{Name: "manualscalertraits.core.oam.dev", Version: "v1alpha2"}}
+)
+
+// cluster is a fake of the resources of a cluster.
+type cluster struct {
+	appConfigs []*v1alpha2.ApplicationConfiguration
+	objects    []*unstructured.Unstructured
+
+	// namespaced workloads and traits are defined in namespace ns, and so are
+	// scopes, which are defined in the cluster too
+	namespaced bool
+
+	deleted []client.DeleteOptions
+	updated []*unstructured.Unstructured
+}
+
+func (c *cluster) client() *test.MockClient {
+	return &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+			switch o := obj.(type) {
+			case *v1alpha2.ApplicationConfiguration:
+				for _, ac := range c.appConfigs {
+					if ac.GetName() == key.Name {
+						ac.DeepCopyInto(o)
+						return nil
+					}
+				}
+			case *v1alpha2.ScopeDefinition:
+				o.Spec.WorkloadRefsPath = "spec.workloadRefs"
+				return nil
+			case *unstructured.Unstructured:
+				for _, u := range c.objects {
+					if u.GetKind() == o.GetKind() && u.GetName() == key.Name {
+						u.DeepCopyInto(o)
+						return nil
+					}
+				}
+			}
+			return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
+		},
+		MockList: func(_ context.Context, list runtime.Object, _ ...client.ListOption) error {
+			switch l := list.(type) {
+			case *v1alpha2.WorkloadDefinitionList:
+				if !c.namespaced {
+					l.Items = []v1alpha2.WorkloadDefinition{{Spec: workloadDefinition}}
+				}
+			case *v1alpha2.TraitDefinitionList:
+				if !c.namespaced {
+					l.Items = []v1alpha2.TraitDefinition{{Spec: traitDefinition}}
+				}
+			case *v1alpha2.NamespacedWorkloadDefinitionList:
+				if c.namespaced {
+					l.Items = []v1alpha2.NamespacedWorkloadDefinition{{ObjectMeta: metav1.ObjectMeta{Namespace: "ns"}, Spec: workloadDefinition}}
+				}
+			case *v1alpha2.NamespacedTraitDefinitionList:
+				if c.namespaced {
+					l.Items = []v1alpha2.NamespacedTraitDefinition{{ObjectMeta: metav1.ObjectMeta{Namespace: "ns"}, Spec: traitDefinition}}
+				}
+			case *v1alpha2.NamespacedScopeDefinitionList:
+				if c.namespaced {
+					l.Items = []v1alpha2.NamespacedScopeDefinition{{ObjectMeta: metav1.ObjectMeta{Namespace: "ns"}, Spec: v1alpha2.ScopeDefinitionSpec{
+						Reference:        v1alpha2.DefinitionReference{Name: "healthscopes.core.oam.dev", Version: "v1alpha2"},
+						WorkloadRefsPath: "spec.workloadRefs"}}}
+				}
+			case *v1alpha2.ScopeDefinitionList:
+				l.Items = []v1alpha2.ScopeDefinition{{Spec: v1alpha2.ScopeDefinitionSpec{
+					Reference:        v1alpha2.DefinitionReference{Name: "healthscopes.core.oam.dev", Version: "v1alpha2"},
+					WorkloadRefsPath: "spec.workloadRefs"}}}
+			case *unstructured.UnstructuredList:
+				for _, u := range c.objects {
+					if u.GetKind()+"List" == l.GetKind() {
+						l.Items = append(l.Items, *u.DeepCopy())
+					}
+				}
+			}
+			return nil
+		},
+		MockDelete: func(_ context.Context, _ runtime.Object, opts ...client.DeleteOption) error {
+			c.deleted = append(c.deleted, *(&client.DeleteOptions{}).ApplyOptions(opts))
+			return nil
+		},
+		MockUpdate: func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+			c.updated = append(c.updated, obj.(*unstructured.Unstructured))
+			return nil
+		},
+	}
+}
+
+func mapper() *mock.DiscoveryMapper {
+	dm := mock.NewMockDiscoveryMapper()
+	kinds := map[string]string{"deployments": "Deployment", "manualscalertraits": "ManualScalerTrait", "healthscopes": "HealthScope"}
+	dm.MockKindsFor = func(gvr schema.GroupVersionResource) ([]schema.GroupVersionKind, error) {
+		return []schema.GroupVersionKind{gvr.GroupVersion().WithKind(kinds[gvr.Resource])}, nil
+	}
+	dm.MockRESTMapping = mock.NewMockRESTMapping("healthscopes")
+	return dm
+}
+
+func healthScope(name string, refs ...runtimev1alpha1.TypedReference) *unstructured.Unstructured {
+	s := util.ObjectOf("ns", named(scope, name))
+	workloadRefs := make([]interface{}, 0, len(refs))
+	for _, ref := range refs {
+		workloadRefs = append(workloadRefs, map[string]interface{}{"apiVersion": ref.APIVersion, "kind": ref.Kind, "name": ref.Name})
+	}
+	_ = unstructured.SetNestedSlice(s.Object, workloadRefs, "spec", "workloadRefs")
+	return s
+}
+
+func TestFind(t *testing.T) {
+	ac := &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "app", UID: "ac"}}
+	ac.Status.Workloads = []v1alpha2.WorkloadStatus{{Reference: named(deployment, "web")}}
+	retained := labelled(named(deployment, "retained"), "gone", nil)
+	retained.SetAnnotations(map[string]string{oam.AnnotationRetain: "true"})
+
+	deleted := named(deployment, "deleted")
+	want := []Finding{
+		{Type: TypeOrphanedResource, Namespace: "ns", Resource: named(deployment, "orphan"),
+			Reason: `ApplicationConfiguration "gone" does not exist`},
+		{Type: TypeOrphanedResource, Namespace: "ns", Resource: named(scaler, "stale"),
+			Reason: `no longer part of ApplicationConfiguration "app"`},
+		{Type: TypeDanglingWorkloadRef, Namespace: "ns", Resource: named(scope, "health"), WorkloadRef: &deleted,
+			Reason: reasonWorkloadGone},
+	}
+
+	for _, namespaced := range []bool{false, true} {
+		c := &cluster{
+			appConfigs: []*v1alpha2.ApplicationConfiguration{ac},
+			objects: []*unstructured.Unstructured{
+				labelled(named(deployment, "web"), "app", controlledBy(v1alpha2.ApplicationConfigurationKind, "ac")),
+				labelled(named(deployment, "orphan"), "gone", nil),
+				labelled(named(deployment, "child"), "gone", controlledBy("ContainerizedWorkload", "cw")),
+				retained,
+				labelled(named(scaler, "stale"), "app", controlledBy(v1alpha2.ApplicationConfigurationKind, "ac")),
+				healthScope("health", named(deployment, "web"), deleted),
+			},
+			namespaced: namespaced,
+		}
+		got, err := NewFinder(c.client(), mapper()).Find(context.Background(), "ns")
+		if err != nil {
+			t.Fatalf("Find(...) with namespaced definitions %t: %s", namespaced, err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Find(...) with namespaced definitions %t: -want, +got:\n%s", namespaced, diff)
+		}
+	}
+}
+
+func TestClean(t *testing.T) {
+	deleted := named(deployment, "deleted")
+	findings := []Finding{
+		{Type: TypeOrphanedResource, Namespace: "ns", Resource: named(deployment, "orphan")},
+		{Type: TypeDanglingWorkloadRef, Namespace: "ns", Resource: named(scope, "health"), WorkloadRef: &deleted},
+	}
+
+	for _, dryRun := range []bool{false, true} {
+		c := &cluster{objects: []*unstructured.Unstructured{
+			healthScope("health", named(deployment, "web"), deleted),
+		}}
+		if err := NewFinder(c.client(), mapper()).Clean(context.Background(), findings, dryRun); err != nil {
+			t.Fatalf("Clean(..., %t): %s", dryRun, err)
+		}
+		if len(c.deleted) != 1 {
+			t.Fatalf("Clean(..., %t): want 1 deletion, got %d", dryRun, len(c.deleted))
+		}
+		if got := len(c.deleted[0].DryRun) > 0; got != dryRun {
+			t.Errorf("Clean(..., %t): deletion was a dry run: %t", dryRun, got)
+		}
+		if len(c.updated) != 1 {
+			t.Fatalf("Clean(..., %t): want 1 update, got %d", dryRun, len(c.updated))
+		}
+		refs, err := workloadRefs(c.updated[0], "spec.workloadRefs")
+		if err != nil {
+			t.Fatalf("workloadRefs(...): %s", err)
+		}
+		if diff := cmp.Diff([]runtimev1alpha1.TypedReference{named(deployment, "web")}, refs); diff != "" {
+			t.Errorf("Clean(..., %t): -want workloadRefs, +got workloadRefs:\n%s", dryRun, diff)
+		}
+	}
+}
@@ -19,9 +19,11 @@ import (
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
@@ -70,6 +72,12 @@ const (
 	errFmtControllerRevisionData = "cannot get valid component data from controllerRevision %q"
 	errFmtGetComponent           = "cannot get component %q"
 	errFmtInvalidRevisionType    = "invalid type of revision %s, type should not be %v"
+
+	errFmtConflictTraitType  = "trait %q conflicts with trait %q"
+	errFmtConflictWorkloadFP = "traits %q and %q both manage workload field %q"
+
+	errFmtNoStorageVersion = "CustomResourceDefinition %q has no storage version"
+	errFmtVersionNotServed = "version %q is not served by CustomResourceDefinition %q"
 )
 
 // A ConditionedObject is an Object type with condition field
@@ -154,58 +162,114 @@ func GetDummyWorkloadDefinition(u *unstructured.Unstructured) *v1alpha2.Workload
 	}
 }
 
-// FetchScopeDefinition fetch corresponding scopeDefinition given a scope
+// FetchScopeDefinition fetch corresponding scopeDefinition given a scope of
+// the namespace
 func FetchScopeDefinition(ctx context.Context, r client.Reader, dm discoverymapper.DiscoveryMapper,
-	scope *unstructured.Unstructured) (*v1alpha2.ScopeDefinition, error) {
+	namespace string, scope *unstructured.Unstructured) (*v1alpha2.ScopeDefinition, error) {
 	// The name of the scopeDefinition CR is the CRD name of the scope
 	// TODO(wonderflow): we haven't support scope definition label type yet.
 	spName, err := GetDefinitionName(dm, scope, "")
 	if err != nil {
 		return nil, err
 	}
-	nn := GenNamespacedDefinitionName(spName)
-	// Fetch the corresponding scopeDefinition CR
-	scopeDefinition := &v1alpha2.ScopeDefinition{}
-	if err := r.Get(ctx, nn, scopeDefinition); err != nil {
-		return nil, err
-	}
-	return scopeDefinition, nil
+	return GetScopeDefinition(ctx, r, namespace, spName)
 }
 
-// FetchTraitDefinition fetch corresponding traitDefinition given a trait
+// FetchTraitDefinition fetch corresponding traitDefinition given a trait of
+// the namespace
 func FetchTraitDefinition(ctx context.Context, r client.Reader, dm discoverymapper.DiscoveryMapper,
-	trait *unstructured.Unstructured) (*v1alpha2.TraitDefinition, error) {
+	namespace string, trait *unstructured.Unstructured) (*v1alpha2.TraitDefinition, error) {
 	// The name of the traitDefinition CR is the CRD name of the trait
 	trName, err := GetDefinitionName(dm, trait, oam.TraitTypeLabel)
 	if err != nil {
 		return nil, err
 	}
-	nn := GenNamespacedDefinitionName(trName)
-	// Fetch the corresponding traitDefinition CR
-	traitDefinition := &v1alpha2.TraitDefinition{}
-	if err := r.Get(ctx, nn, traitDefinition); err != nil {
-		return nil, err
-	}
-	return traitDefinition, nil
+	return GetTraitDefinition(ctx, r, namespace, trName)
 }
 
-// FetchWorkloadDefinition fetch corresponding workloadDefinition given a workload
+// FetchWorkloadDefinition fetch corresponding workloadDefinition given a
+// workload of the namespace
 func FetchWorkloadDefinition(ctx context.Context, r client.Reader, dm discoverymapper.DiscoveryMapper,
-	workload *unstructured.Unstructured) (*v1alpha2.WorkloadDefinition, error) {
+	namespace string, workload *unstructured.Unstructured) (*v1alpha2.WorkloadDefinition, error) {
 	// The name of the workloadDefinition CR is the CRD name of the component
 	wldName, err := GetDefinitionName(dm, workload, oam.WorkloadTypeLabel)
 	if err != nil {
 		return nil, err
 	}
-	nn := GenNamespacedDefinitionName(wldName)
-	// Fetch the corresponding workloadDefinition CR
+	return GetWorkloadDefinition(ctx, r, namespace, wldName)
+}
+
+// GetWorkloadDefinition gets the workloadDefinition of the name for the
+// namespace. A NamespacedWorkloadDefinition of the namespace shadows the
+// cluster scoped WorkloadDefinition of the same name.
+func GetWorkloadDefinition(ctx context.Context, r client.Reader, namespace, name string) (*v1alpha2.WorkloadDefinition, error) {
+	nd := &v1alpha2.NamespacedWorkloadDefinition{}
+	found, err := getNamespacedDefinition(ctx, r, namespace, name, nd)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return &v1alpha2.WorkloadDefinition{ObjectMeta: nd.ObjectMeta, Spec: nd.Spec}, nil
+	}
 	workloadDefinition := &v1alpha2.WorkloadDefinition{}
-	if err := r.Get(ctx, nn, workloadDefinition); err != nil {
+	if err := r.Get(ctx, GenNamespacedDefinitionName(name), workloadDefinition); err != nil {
 		return nil, err
 	}
 	return workloadDefinition, nil
 }
 
+// GetTraitDefinition gets the traitDefinition of the name for the namespace.
+// A NamespacedTraitDefinition of the namespace shadows the cluster scoped
+// TraitDefinition of the same name.
+func GetTraitDefinition(ctx context.Context, r client.Reader, namespace, name string) (*v1alpha2.TraitDefinition, error) {
+	nd := &v1alpha2.NamespacedTraitDefinition{}
+	found, err := getNamespacedDefinition(ctx, r, namespace, name, nd)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return &v1alpha2.TraitDefinition{ObjectMeta: nd.ObjectMeta, Spec: nd.Spec}, nil
+	}
+	traitDefinition := &v1alpha2.TraitDefinition{}
+	if err := r.Get(ctx, GenNamespacedDefinitionName(name), traitDefinition); err != nil {
+		return nil, err
+	}
+	return traitDefinition, nil
+}
+
+// GetScopeDefinition gets the scopeDefinition of the name for the namespace.
+// A NamespacedScopeDefinition of the namespace shadows the cluster scoped
+// ScopeDefinition of the same name.
+func GetScopeDefinition(ctx context.Context, r client.Reader, namespace, name string) (*v1alpha2.ScopeDefinition, error) {
+	nd := &v1alpha2.NamespacedScopeDefinition{}
+	found, err := getNamespacedDefinition(ctx, r, namespace, name, nd)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return &v1alpha2.ScopeDefinition{ObjectMeta: nd.ObjectMeta, Spec: nd.Spec}, nil
+	}
+	scopeDefinition := &v1alpha2.ScopeDefinition{}
+	if err := r.Get(ctx, GenNamespacedDefinitionName(name), scopeDefinition); err != nil {
+		return nil, err
+	}
+	return scopeDefinition, nil
+}
+
+// getNamespacedDefinition gets the namespaced definition of the name in the
+// namespace, if there is one. Nothing is looked up without a namespace, or
+// once the namespaced definition CRDs turn out not to be installed.
+func getNamespacedDefinition(ctx context.Context, r client.Reader, namespace, name string, obj runtime.Object) (bool, error) {
+	if namespace == "" {
+		return false, nil
+	}
+	err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, obj)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 // GenNamespacedDefinitionName generate definition name with customized namespace
 func GenNamespacedDefinitionName(dn string) types.NamespacedName {
 	if dns := os.Getenv(DefinitionNamespaceEnv); dns != "" {
@@ -218,7 +282,7 @@ func GenNamespacedDefinitionName(dn string) types.NamespacedName {
 func FetchWorkloadChildResources(ctx context.Context, mLog logr.Logger, r client.Reader,
 	dm discoverymapper.DiscoveryMapper, workload *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
 	// Fetch the corresponding workloadDefinition CR
-	workloadDefinition, err := FetchWorkloadDefinition(ctx, r, dm, workload)
+	workloadDefinition, err := FetchWorkloadDefinition(ctx, r, dm, workload.GetNamespace(), workload)
 	if err != nil {
 		// No definition will won't block app from running
 		if apierrors.IsNotFound(err) {
@@ -315,11 +379,29 @@ func GetDefinitionName(dm discoverymapper.DiscoveryMapper, u *unstructured.Unstr
 	return mapping.Resource.Resource + "." + groupVersion.Group, nil
 }
 
-// GetGVKFromDefinition help get Group Version Kind from DefinitionReference
-func GetGVKFromDefinition(dm discoverymapper.DiscoveryMapper, definitionRef v1alpha2.DefinitionReference) (schema.GroupVersionKind, error) {
+// GetGVKFromDefinition help get Group Version Kind from DefinitionReference.
+// The version of the reference is used when it's set, otherwise the storage
+// version of the referenced CRD. References to resources that are not CRDs,
+// e.g. deployments.apps, fall back to the preferred version of the API server.
+func GetGVKFromDefinition(ctx context.Context, r client.Reader, dm discoverymapper.DiscoveryMapper,
+	definitionRef v1alpha2.DefinitionReference) (schema.GroupVersionKind, error) {
 	var gvk schema.GroupVersionKind
 	groupResource := schema.ParseGroupResource(definitionRef.Name)
 	gvr := schema.GroupVersionResource{Group: groupResource.Group, Resource: groupResource.Resource, Version: definitionRef.Version}
+	if gvr.Version == "" {
+		crd := &crdv1.CustomResourceDefinition{}
+		err := r.Get(ctx, types.NamespacedName{Name: definitionRef.Name}, crd)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return gvk, err
+		}
+		if err == nil {
+			version, err := GetCRDVersion(crd, "")
+			if err != nil {
+				return gvk, err
+			}
+			return schema.GroupVersionKind{Group: crd.Spec.Group, Version: version, Kind: crd.Spec.Names.Kind}, nil
+		}
+	}
 	kinds, err := dm.KindsFor(gvr)
 	if err != nil {
 		return gvk, err
@@ -332,6 +414,21 @@ func GetGVKFromDefinition(dm discoverymapper.DiscoveryMapper, definitionRef v1al
 	return kinds[0], nil
 }
 
+// GetCRDVersion returns the version of the CRD a definition refers to. That's
+// the supplied version if the CRD serves it, or the storage version of the CRD
+// if no version is supplied.
+func GetCRDVersion(crd *crdv1.CustomResourceDefinition, version string) (string, error) {
+	for _, v := range crd.Spec.Versions {
+		if (version == "" && v.Storage) || (version != "" && version == v.Name && v.Served) {
+			return v.Name, nil
+		}
+	}
+	if version == "" {
+		return "", errors.Errorf(errFmtNoStorageVersion, crd.GetName())
+	}
+	return "", errors.Errorf(errFmtVersionNotServed, version, crd.GetName())
+}
+
 // Object2Unstructured convert an object to an unstructured struct
 func Object2Unstructured(obj interface{}) (*unstructured.Unstructured, error) {
 	objMap, err := Object2Map(obj)
@@ -369,23 +466,73 @@ func GenTraitName(componentName string, ct *v1alpha2.ComponentTrait, traitType s
 // avoid bad words.
 func ComputeHash(trait *v1alpha2.ComponentTrait) string {
 	componentTraitHasher := fnv.New32a()
-	DeepHashObject(componentTraitHasher, *trait)
+	// the deletion policy is left out of the hash, so that neither setting it
+	// nor the field itself, which was added after trait names were first
+	// generated from this hash, renames traits
+	t := *trait
+	t.DeletionPolicy = ""
+	printed := hashPrinter.Sprintf("%#v", t)
+	_, _ = componentTraitHasher.Write([]byte(strings.TrimSuffix(printed, traitDeletionPolicyField+"}") + "}"))
 
 	return rand.SafeEncodeString(fmt.Sprint(componentTraitHasher.Sum32()))
 }
 
+// ComputeComponentHash computes the hash of the spec of a Component, which is
+// the same for Components of the same content. The revision retention policy
+// is not part of the content. The hash will be safe encoded to avoid bad
+// words.
+func ComputeComponentHash(comp *v1alpha2.Component) string {
+	hasher := fnv.New64a()
+	DeepHashObject(hasher, RevisionSpec(comp.Spec))
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum64()))
+}
+
+// RevisionSpec returns the part of the spec of a Component that its revisions
+// are made of, i.e. without its revision retention policy.
+func RevisionSpec(spec v1alpha2.ComponentSpec) v1alpha2.ComponentSpec {
+	spec.RevisionRetention = nil
+	return spec
+}
+
+// traitDeletionPolicyField is how hashPrinter prints an empty deletion policy
+// of a ComponentTrait.
+const traitDeletionPolicyField = " DeletionPolicy:(v1alpha2.DeletionPolicy)"
+
+var hashPrinter = spew.ConfigState{
+	Indent:         " ",
+	SortKeys:       true,
+	DisableMethods: true,
+	SpewKeys:       true,
+}
+
 // DeepHashObject writes specified object to hash using the spew library
 // which follows pointers and prints actual values of the nested objects
 // ensuring the hash does not change when a pointer changes.
 func DeepHashObject(hasher hash.Hash, objectToWrite interface{}) {
 	hasher.Reset()
-	printer := spew.ConfigState{
-		Indent:         " ",
-		SortKeys:       true,
-		DisableMethods: true,
-		SpewKeys:       true,
+	_, _ = hashPrinter.Fprintf(hasher, "%#v", objectToWrite)
+}
+
+// ConstructRevisionName will generate revisionName from componentName
+// will be <componentName>-v<RevisionNumber>, for example: comp-v1
+func ConstructRevisionName(componentName string, revision int64) string {
+	return strings.Join([]string{componentName, fmt.Sprintf("v%d", revision)}, "-")
+}
+
+// ExtractComponentName will extract componentName from revisionName
+func ExtractComponentName(revisionName string) string {
+	splits := strings.Split(revisionName, "-")
+	return strings.Join(splits[0:len(splits)-1], "-")
+}
+
+// ComponentNameOf returns the name of the component an
+// ApplicationConfigurationComponent refers to, either directly or through
+// one of its revisions.
+func ComponentNameOf(acc v1alpha2.ApplicationConfigurationComponent) string {
+	if acc.RevisionName != "" {
+		return ExtractComponentName(acc.RevisionName)
 	}
-	_, _ = printer.Fprintf(hasher, "%#v", objectToWrite)
+	return acc.ComponentName
 }
 
 // GetComponent will get Component and RevisionName by AppConfigComponent
@@ -429,6 +576,20 @@ func UnpackRevisionData(rev *appsv1.ControllerRevision) (*v1alpha2.Component, er
 	return &comp, err
 }
 
+// UnpackAppConfigRevisionData will unpack revision.Data to ApplicationConfiguration
+func UnpackAppConfigRevisionData(rev *appsv1.ControllerRevision) (*v1alpha2.ApplicationConfiguration, error) {
+	if rev.Data.Object != nil {
+		ac, ok := rev.Data.Object.(*v1alpha2.ApplicationConfiguration)
+		if !ok {
+			return nil, fmt.Errorf(errFmtInvalidRevisionType, rev.Name, reflect.TypeOf(rev.Data.Object))
+		}
+		return ac, nil
+	}
+	var ac v1alpha2.ApplicationConfiguration
+	err := json.Unmarshal(rev.Data.Raw, &ac)
+	return &ac, err
+}
+
 // AddLabels will merge labels with existing labels. If any conflict keys, use new value to override existing value.
 func AddLabels(o *unstructured.Unstructured, labels map[string]string) {
 	o.SetLabels(MergeMapOverrideWithDst(o.GetLabels(), labels))
@@ -455,3 +616,52 @@ func MergeMapOverrideWithDst(src, dst map[string]string) map[string]string {
 	}
 	return r
 }
+
+// TraitsConflict returns why a trait of TraitDefinition a cannot be applied to
+// the same workload as a trait of TraitDefinition b. Either definition may
+// declare the conflict. It returns an empty string if the traits do not
+// conflict.
+func TraitsConflict(a, b *v1alpha2.TraitDefinition) string {
+	for _, c := range a.Spec.ConflictsWith {
+		if c.TraitType != "" && c.TraitType == b.GetName() {
+			return fmt.Sprintf(errFmtConflictTraitType, a.GetName(), b.GetName())
+		}
+	}
+	for _, c := range b.Spec.ConflictsWith {
+		if c.TraitType != "" && c.TraitType == a.GetName() {
+			return fmt.Sprintf(errFmtConflictTraitType, b.GetName(), a.GetName())
+		}
+	}
+	for _, ca := range a.Spec.ConflictsWith {
+		if ca.WorkloadFieldPath == "" {
+			continue
+		}
+		for _, cb := range b.Spec.ConflictsWith {
+			if ca.WorkloadFieldPath == cb.WorkloadFieldPath {
+				return fmt.Sprintf(errFmtConflictWorkloadFP, a.GetName(), b.GetName(), ca.WorkloadFieldPath)
+			}
+		}
+	}
+	return ""
+}
+
+// SameResource tells whether two references refer to the same resource.
+func SameResource(a, b cpv1alpha1.TypedReference) bool {
+	return a.APIVersion == b.APIVersion && a.Kind == b.Kind && a.Name == b.Name
+}
+
+// ReferenceTo returns a reference to the supplied resource.
+func ReferenceTo(u *unstructured.Unstructured) cpv1alpha1.TypedReference {
+	return cpv1alpha1.TypedReference{APIVersion: u.GetAPIVersion(), Kind: u.GetKind(), Name: u.GetName()}
+}
+
+// ObjectOf returns an otherwise empty object identifying the referenced
+// resource in the namespace, e.g. to get or delete it.
+func ObjectOf(namespace string, ref cpv1alpha1.TypedReference) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(ref.APIVersion)
+	u.SetKind(ref.Kind)
+	u.SetNamespace(namespace)
+	u.SetName(ref.Name)
+	return u
+}
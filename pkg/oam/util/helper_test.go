@@ -17,6 +17,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -344,7 +346,7 @@ func TestScopeRelatedUtils(t *testing.T) {
 		tclient := test.MockClient{
 			MockGet: test.NewMockGetFn(nil, tc.fields.getFunc),
 		}
-		got, err := util.FetchScopeDefinition(ctx, &tclient, mock.NewMockDiscoveryMapper(), unstructuredScope)
+		got, err := util.FetchScopeDefinition(ctx, &tclient, mock.NewMockDiscoveryMapper(), "", unstructuredScope)
 		t.Log(fmt.Sprint("Running test: ", name))
 		assert.Equal(t, tc.want.err, err)
 		assert.Equal(t, tc.want.spd, got)
@@ -437,7 +439,7 @@ func TestTraitHelper(t *testing.T) {
 		tclient := test.MockClient{
 			MockGet: test.NewMockGetFn(nil, tc.fields.getFunc),
 		}
-		got, err := util.FetchTraitDefinition(ctx, &tclient, mock.NewMockDiscoveryMapper(), unstructuredTrait)
+		got, err := util.FetchTraitDefinition(ctx, &tclient, mock.NewMockDiscoveryMapper(), "", unstructuredTrait)
 		t.Log(fmt.Sprint("Running test: ", name))
 		assert.Equal(t, tc.want.err, err)
 		assert.Equal(t, tc.want.td, got)
@@ -524,7 +526,7 @@ func TestUtils(t *testing.T) {
 		tclient := test.MockClient{
 			MockGet: test.NewMockGetFn(nil, tc.fields.getFunc),
 		}
-		got, err := util.FetchWorkloadDefinition(ctx, &tclient, mock.NewMockDiscoveryMapper(), unstructuredWorkload)
+		got, err := util.FetchWorkloadDefinition(ctx, &tclient, mock.NewMockDiscoveryMapper(), "", unstructuredWorkload)
 		t.Log(fmt.Sprint("Running test: ", name))
 
 		assert.Equal(t, tc.want.err, err)
@@ -704,8 +706,14 @@ func TestChildResources(t *testing.T) {
 		},
 	}
 	for name, tc := range cases {
+		getFunc := tc.fields.getFunc
 		tclient := test.MockClient{
-			MockGet:  test.NewMockGetFn(nil, tc.fields.getFunc),
+			MockGet: test.NewMockGetFn(nil, func(obj runtime.Object) error {
+				if _, ok := obj.(*v1alpha2.NamespacedWorkloadDefinition); ok {
+					return kerrors.NewNotFound(schema.GroupResource{}, "")
+				}
+				return getFunc(obj)
+			}),
 			MockList: test.NewMockListFn(nil, tc.fields.listFunc),
 		}
 		got, err := util.FetchWorkloadChildResources(ctx, log, &tclient, mock.NewMockDiscoveryMapper(), unstructuredWorkload)
@@ -776,23 +784,93 @@ func TestUnstructured(t *testing.T) {
 }
 
 func TestGetGVKFromDef(t *testing.T) {
+	ctx := context.Background()
 	mapper := mock.NewMockDiscoveryMapper()
 	mapper.MockKindsFor = mock.NewMockKindsFor("Abc", "v1", "v2")
-	gvk, err := util.GetGVKFromDefinition(mapper, v1alpha2.DefinitionReference{Name: "abcs.example.com"})
-	assert.NoError(t, err)
-	assert.Equal(t, schema.GroupVersionKind{
-		Group:   "example.com",
-		Version: "v1",
-		Kind:    "Abc",
-	}, gvk)
-
-	gvk, err = util.GetGVKFromDefinition(mapper, v1alpha2.DefinitionReference{Name: "abcs.example.com", Version: "v2"})
-	assert.NoError(t, err)
-	assert.Equal(t, schema.GroupVersionKind{
-		Group:   "example.com",
-		Version: "v2",
-		Kind:    "Abc",
-	}, gvk)
+	crd := crdv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "abcs.example.com"},
+		Spec: crdv1.CustomResourceDefinitionSpec{
+			Group: "example.com",
+			Names: crdv1.CustomResourceDefinitionNames{Kind: "Abc"},
+			Versions: []crdv1.CustomResourceDefinitionVersion{
+				{Name: "v1", Served: true},
+				{Name: "v2", Served: true, Storage: true},
+			},
+		},
+	}
+	noCRD := &test.MockClient{MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, ""))}
+	withCRD := &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj runtime.Object) error {
+		crd.DeepCopyInto(obj.(*crdv1.CustomResourceDefinition))
+		return nil
+	}}
+
+	cases := map[string]struct {
+		c   client.Reader
+		ref v1alpha2.DefinitionReference
+		exp schema.GroupVersionKind
+	}{
+		"PreferredVersion": {
+			c:   noCRD,
+			ref: v1alpha2.DefinitionReference{Name: "abcs.example.com"},
+			exp: schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Abc"},
+		},
+		"RequestedVersion": {
+			c:   noCRD,
+			ref: v1alpha2.DefinitionReference{Name: "abcs.example.com", Version: "v2"},
+			exp: schema.GroupVersionKind{Group: "example.com", Version: "v2", Kind: "Abc"},
+		},
+		"StorageVersion": {
+			c:   withCRD,
+			ref: v1alpha2.DefinitionReference{Name: "abcs.example.com"},
+			exp: schema.GroupVersionKind{Group: "example.com", Version: "v2", Kind: "Abc"},
+		},
+		"RequestedVersionOfCRD": {
+			c:   withCRD,
+			ref: v1alpha2.DefinitionReference{Name: "abcs.example.com", Version: "v1"},
+			exp: schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Abc"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			gvk, err := util.GetGVKFromDefinition(ctx, tc.c, mapper, tc.ref)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.exp, gvk)
+		})
+	}
+}
+
+func TestGetCRDVersion(t *testing.T) {
+	crd := &crdv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "abcs.example.com"},
+		Spec: crdv1.CustomResourceDefinitionSpec{
+			Versions: []crdv1.CustomResourceDefinitionVersion{
+				{Name: "v1", Served: true},
+				{Name: "v2", Served: true, Storage: true},
+				{Name: "v3"},
+			},
+		},
+	}
+	cases := map[string]struct {
+		version string
+		exp     string
+		err     error
+	}{
+		"Storage":   {exp: "v2"},
+		"Served":    {version: "v1", exp: "v1"},
+		"NotServed": {version: "v3", err: errors.New(`version "v3" is not served by CustomResourceDefinition "abcs.example.com"`)},
+		"Unknown":   {version: "v4", err: errors.New(`version "v4" is not served by CustomResourceDefinition "abcs.example.com"`)},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := util.GetCRDVersion(crd, tc.version)
+			if tc.err != nil {
+				assert.EqualError(t, err, tc.err.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.exp, got)
+		})
+	}
 }
 
 func TestGenTraitName(t *testing.T) {
@@ -881,6 +959,11 @@ func TestComputeHash(t *testing.T) {
 			template: &v1alpha2.ComponentTrait{},
 			exp:      "67b8949f8d",
 		},
+		{
+			name:     "deletion policy is not hashed",
+			template: &v1alpha2.ComponentTrait{DeletionPolicy: v1alpha2.DeletionPolicyOrphan},
+			exp:      "67b8949f8d",
+		},
 		{
 			name: "simple",
 			template: &v1alpha2.ComponentTrait{
@@ -1317,3 +1400,109 @@ func TestNamespacedDefinition(t *testing.T) {
 	assert.Equal(t, nn.Namespace, ns)
 	assert.Equal(t, nn.Name, n)
 }
+
+func TestTraitsConflict(t *testing.T) {
+	def := func(name string, conflicts ...v1alpha2.TraitConflict) *v1alpha2.TraitDefinition {
+		return &v1alpha2.TraitDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1alpha2.TraitDefinitionSpec{ConflictsWith: conflicts},
+		}
+	}
+	replicas := v1alpha2.TraitConflict{WorkloadFieldPath: "spec.replicas"}
+	cases := map[string]struct {
+		a    *v1alpha2.TraitDefinition
+		b    *v1alpha2.TraitDefinition
+		want string
+	}{
+		"NoConflict": {
+			a: def("manualscalertraits.core.oam.dev", replicas),
+			b: def("routes.standard.oam.dev"),
+		},
+		"ConflictingType": {
+			a:    def("routes.standard.oam.dev"),
+			b:    def("ingresses.standard.oam.dev", v1alpha2.TraitConflict{TraitType: "routes.standard.oam.dev"}),
+			want: `trait "ingresses.standard.oam.dev" conflicts with trait "routes.standard.oam.dev"`,
+		},
+		"SameWorkloadField": {
+			a:    def("manualscalertraits.core.oam.dev", replicas),
+			b:    def("autoscalers.standard.oam.dev", v1alpha2.TraitConflict{TraitType: "other"}, replicas),
+			want: `traits "manualscalertraits.core.oam.dev" and "autoscalers.standard.oam.dev" both manage workload field "spec.replicas"`,
+		},
+		"OneSidedWorkloadField": {
+			a: def("manualscalertraits.core.oam.dev", replicas),
+			b: def("autoscalers.standard.oam.dev", v1alpha2.TraitConflict{WorkloadFieldPath: "spec.template"}),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, util.TraitsConflict(tc.a, tc.b))
+		})
+	}
+}
+
+func TestGetDefinitionShadowing(t *testing.T) {
+	notFound := kerrors.NewNotFound(schema.GroupResource{}, "")
+	clusterDef := v1alpha2.TraitDefinitionSpec{WorkloadRefPath: "spec.cluster"}
+	namespacedDef := v1alpha2.TraitDefinitionSpec{WorkloadRefPath: "spec.namespaced"}
+	getFn := func(namespaced bool) test.MockGetFn {
+		return func(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+			switch o := obj.(type) {
+			case *v1alpha2.NamespacedTraitDefinition:
+				if !namespaced || key.Namespace != "tenant" {
+					return notFound
+				}
+				o.Spec = namespacedDef
+			case *v1alpha2.TraitDefinition:
+				o.Spec = clusterDef
+			}
+			return nil
+		}
+	}
+	cases := map[string]struct {
+		namespace  string
+		namespaced bool
+		want       v1alpha2.TraitDefinitionSpec
+	}{
+		"Shadowed":          {namespace: "tenant", namespaced: true, want: namespacedDef},
+		"NotShadowed":       {namespace: "tenant", want: clusterDef},
+		"OtherNamespace":    {namespace: "other", namespaced: true, want: clusterDef},
+		"ClusterOnlyLookup": {namespaced: true, want: clusterDef},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := util.GetTraitDefinition(context.Background(), &test.MockClient{MockGet: getFn(tc.namespaced)}, tc.namespace, "scaler")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got.Spec)
+		})
+	}
+}
+
+func TestResourceReferences(t *testing.T) {
+	ref := v1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"}
+	u := util.ObjectOf("ns", ref)
+	assert.Equal(t, "ns", u.GetNamespace())
+	assert.True(t, util.SameResource(ref, util.ReferenceTo(u)))
+
+	other := ref
+	other.APIVersion = "extensions/v1beta1"
+	assert.False(t, util.SameResource(ref, other))
+}
+
+func TestConstructExtract(t *testing.T) {
+	tests := []string{"tam1", "test-comp", "xx", "tt-x-x-c"}
+	revisionNum := []int64{1, 5, 10, 100000}
+	for idx, componentName := range tests {
+		t.Run(fmt.Sprintf("tests %d for component[%s]", idx, componentName), func(t *testing.T) {
+			revisionName := util.ConstructRevisionName(componentName, revisionNum[idx])
+			got := util.ExtractComponentName(revisionName)
+			if got != componentName {
+				t.Errorf("want to get %s from %s but got %s", componentName, revisionName, got)
+			}
+		})
+	}
+}
+
+func TestComponentNameOf(t *testing.T) {
+	assert.Equal(t, "web", util.ComponentNameOf(v1alpha2.ApplicationConfigurationComponent{ComponentName: "web"}))
+	assert.Equal(t, "web-app", util.ComponentNameOf(v1alpha2.ApplicationConfigurationComponent{RevisionName: "web-app-v3"}))
+}
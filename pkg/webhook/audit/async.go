@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// DefaultBufferSize is the number of records an AsyncSink holds before it
+// starts dropping them.
+const DefaultBufferSize = 1000
+
+const errBufferFull = "audit record buffer is full, dropping record"
+
+var _ manager.Runnable = &AsyncSink{}
+
+// An AsyncSink queues records and writes them to another sink in the
+// background, so that a slow sink, e.g. one that updates a ConfigMap, doesn't
+// add latency to admission requests. It must be started, e.g. by adding it
+// to a manager, for queued records to be written.
+type AsyncSink struct {
+	sink    Sink
+	records chan Record
+}
+
+// NewAsyncSink returns an AsyncSink writing to the supplied sink, that queues
+// up to size records.
+func NewAsyncSink(s Sink, size int) *AsyncSink {
+	return &AsyncSink{sink: s, records: make(chan Record, size)}
+}
+
+// Write queues the supplied record without waiting for it to be written. The
+// record is dropped if the queue is full.
+func (a *AsyncSink) Write(_ context.Context, r Record) error {
+	select {
+	case a.records <- r:
+		return nil
+	default:
+		return errors.New(errBufferFull)
+	}
+}
+
+// Start writes queued records until the stop channel is closed, then writes
+// the records still queued.
+func (a *AsyncSink) Start(stop <-chan struct{}) error {
+	for {
+		select {
+		case r := <-a.records:
+			a.write(r)
+		case <-stop:
+			for {
+				select {
+				case r := <-a.records:
+					a.write(r)
+				default:
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// NeedLeaderElection is false, every replica serves admission requests.
+func (a *AsyncSink) NeedLeaderElection() bool {
+	return false
+}
+
+func (a *AsyncSink) write(r Record) {
+	// the admission request the record was built from is long gone
+	if err := a.sink.Write(context.Background(), r); err != nil {
+		klog.Info("cannot write audit record ", " name: ", r.Name, " errMsg: ", err.Error())
+	}
+}
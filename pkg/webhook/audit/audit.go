@@ -0,0 +1,160 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit records who changed OAM resources through the admission
+// webhook, and how.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/jsondiff"
+)
+
+// maxDiffLines is the most changes a record lists.
+const maxDiffLines = 50
+
+// ResultAdmitted is the result of a change this webhook admitted. An admitted
+// change may still be rejected afterwards, e.g. by another admission webhook
+// or by a quota, so it is not necessarily persisted.
+const ResultAdmitted = "admitted"
+
+// A Record describes a change to a resource admitted by the webhook.
+type Record struct {
+	Time       time.Time                  `json:"time"`
+	Result     string                     `json:"result"`
+	RequestID  types.UID                  `json:"requestID"`
+	Operation  admissionv1beta1.Operation `json:"operation"`
+	APIVersion string                     `json:"apiVersion"`
+	Kind       string                     `json:"kind"`
+	Namespace  string                     `json:"namespace"`
+	Name       string                     `json:"name"`
+	UID        types.UID                  `json:"uid,omitempty"`
+	User       string                     `json:"user"`
+	Groups     []string                   `json:"groups,omitempty"`
+
+	// Diff lists the changes to the spec of the resource, one per line.
+	Diff []string `json:"diff,omitempty"`
+}
+
+// A Sink stores audit records.
+type Sink interface {
+	Write(ctx context.Context, r Record) error
+}
+
+// A SinkFn is a function that satisfies the Sink interface.
+type SinkFn func(ctx context.Context, r Record) error
+
+// Write the supplied record.
+func (fn SinkFn) Write(ctx context.Context, r Record) error {
+	return fn(ctx, r)
+}
+
+// An Auditor turns admission requests into audit records and writes them to
+// a sink. A nil Auditor audits nothing.
+type Auditor struct {
+	sink Sink
+	now  func() time.Time
+}
+
+// NewAuditor returns an Auditor writing to the supplied sink.
+func NewAuditor(s Sink) *Auditor {
+	return &Auditor{sink: s, now: time.Now}
+}
+
+// Audit records the admitted request. Failing to record it is logged rather
+// than returned, so auditing never blocks a change.
+func (a *Auditor) Audit(ctx context.Context, req admission.Request) {
+	if a == nil || (req.DryRun != nil && *req.DryRun) {
+		return
+	}
+	r, err := a.newRecord(req)
+	if err != nil {
+		klog.Info("cannot build audit record ", " name: ", req.Name, " errMsg: ", err.Error())
+		return
+	}
+	if err := a.sink.Write(ctx, r); err != nil {
+		klog.Info("cannot write audit record ", " name: ", req.Name, " errMsg: ", err.Error())
+	}
+}
+
+func (a *Auditor) newRecord(req admission.Request) (Record, error) {
+	r := Record{
+		Time:       a.now(),
+		Result:     ResultAdmitted,
+		RequestID:  req.UID,
+		Operation:  req.Operation,
+		APIVersion: schema.GroupVersion{Group: req.Kind.Group, Version: req.Kind.Version}.String(),
+		Kind:       req.Kind.Kind,
+		Namespace:  req.Namespace,
+		Name:       req.Name,
+		User:       req.UserInfo.Username,
+		Groups:     req.UserInfo.Groups,
+	}
+	oldObj, err := unmarshal(req.OldObject.Raw)
+	if err != nil {
+		return r, err
+	}
+	newObj, err := unmarshal(req.Object.Raw)
+	if err != nil {
+		return r, err
+	}
+	for _, o := range []map[string]interface{}{newObj, oldObj} {
+		if m, ok := o["metadata"].(map[string]interface{}); ok {
+			if r.Name == "" {
+				r.Name, _ = m["name"].(string)
+			}
+			if uid, _ := m["uid"].(string); r.UID == "" {
+				r.UID = types.UID(uid)
+			}
+		}
+	}
+	r.Diff = jsondiff.Diff(oldObj["spec"], newObj["spec"])
+	if len(r.Diff) > maxDiffLines {
+		r.Diff = append(r.Diff[:maxDiffLines], fmt.Sprintf("... %d more", len(r.Diff)-maxDiffLines))
+	}
+	return r, nil
+}
+
+func unmarshal(raw []byte) (map[string]interface{}, error) {
+	obj := map[string]interface{}{}
+	if len(raw) == 0 {
+		return obj, nil
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// String renders the record as a single line of text.
+func (r Record) String() string {
+	s := fmt.Sprintf("%s %s %s %s/%s by %s", r.Result, r.Operation, r.Kind, r.Namespace, r.Name, r.User)
+	if len(r.Diff) > 0 {
+		s += ": " + strings.Join(r.Diff, "; ")
+	}
+	return s
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestAudit(t *testing.T) {
+	now := time.Now()
+	dryRun := true
+	oldObj := []byte(`{"metadata":{"name":"example","uid":"u1"},"spec":{"replicas":1}}`)
+	newObj := []byte(`{"metadata":{"name":"example","uid":"u1"},"spec":{"replicas":2}}`)
+	user := authenticationv1.UserInfo{Username: "alice", Groups: []string{"dev"}}
+	kind := metav1.GroupVersionKind{Group: "core.oam.dev", Version: "v1alpha2", Kind: "Component"}
+
+	cases := map[string]struct {
+		req  admissionv1beta1.AdmissionRequest
+		want []Record
+	}{
+		"Update": {
+			req: admissionv1beta1.AdmissionRequest{
+				UID:       "r1",
+				Kind:      kind,
+				Namespace: "ns",
+				Name:      "example",
+				Operation: admissionv1beta1.Update,
+				UserInfo:  user,
+				Object:    runtime.RawExtension{Raw: newObj},
+				OldObject: runtime.RawExtension{Raw: oldObj},
+			},
+			want: []Record{{
+				Time:       now,
+				Result:     ResultAdmitted,
+				RequestID:  "r1",
+				Operation:  admissionv1beta1.Update,
+				APIVersion: "core.oam.dev/v1alpha2",
+				Kind:       "Component",
+				Namespace:  "ns",
+				Name:       "example",
+				UID:        "u1",
+				User:       "alice",
+				Groups:     []string{"dev"},
+				Diff:       []string{"~spec.replicas: 1 -> 2"},
+			}},
+		},
+		"Create": {
+			req: admissionv1beta1.AdmissionRequest{
+				UID:       "r2",
+				Kind:      kind,
+				Namespace: "ns",
+				Operation: admissionv1beta1.Create,
+				UserInfo:  user,
+				Object:    runtime.RawExtension{Raw: newObj},
+			},
+			want: []Record{{
+				Time:       now,
+				Result:     ResultAdmitted,
+				RequestID:  "r2",
+				Operation:  admissionv1beta1.Create,
+				APIVersion: "core.oam.dev/v1alpha2",
+				Kind:       "Component",
+				Namespace:  "ns",
+				Name:       "example",
+				UID:        "u1",
+				User:       "alice",
+				Groups:     []string{"dev"},
+				Diff:       []string{`+spec: {"replicas":2}`},
+			}},
+		},
+		"DryRun": {
+			req: admissionv1beta1.AdmissionRequest{
+				Operation: admissionv1beta1.Delete,
+				DryRun:    &dryRun,
+				OldObject: runtime.RawExtension{Raw: oldObj},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got []Record
+			a := NewAuditor(SinkFn(func(_ context.Context, r Record) error {
+				got = append(got, r)
+				return nil
+			}))
+			a.now = func() time.Time { return now }
+			a.Audit(context.Background(), admission.Request{AdmissionRequest: tc.req})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Audit(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+
+	// a nil auditor must be safe to use
+	var a *Auditor
+	a.Audit(context.Background(), admission.Request{})
+}
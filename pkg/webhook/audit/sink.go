@@ -0,0 +1,163 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConfigMapName is the name of the ConfigMap audit records of a namespace
+	// are kept in by the ConfigMap sink.
+	ConfigMapName = "oam-audit-trail"
+
+	// AnnotationNextRecord records the sequence number the next audit record
+	// written to the ConfigMap will have.
+	AnnotationNextRecord = "audit.oam.dev/next-record"
+
+	// reasonAudit is the reason of events emitted by the event sink.
+	reasonAudit = "Audit"
+
+	// maxEventMessageLength is the longest message the API server accepts for
+	// an event.
+	maxEventMessageLength = 1024
+)
+
+const (
+	errMarshalRecord    = "cannot marshal audit record"
+	errNoNamespace      = "cannot keep audit records of cluster scoped resources in a ConfigMap"
+	errFmtSaveConfigMap = "cannot save audit record to ConfigMap in namespace %q"
+)
+
+// NewWriterSink returns a Sink that writes each record as a line of JSON to
+// the supplied writer, e.g. a log file.
+func NewWriterSink(w io.Writer) Sink {
+	var mu sync.Mutex
+	return SinkFn(func(_ context.Context, r Record) error {
+		b, err := json.Marshal(r)
+		if err != nil {
+			return errors.Wrap(err, errMarshalRecord)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		_, err = w.Write(append(b, '\n'))
+		return err
+	})
+}
+
+// NewEventSink returns a Sink that emits each record as a Kubernetes event of
+// the audited resource.
+func NewEventSink(rec record.EventRecorder) Sink {
+	return SinkFn(func(_ context.Context, r Record) error {
+		ref := &corev1.ObjectReference{
+			APIVersion: r.APIVersion,
+			Kind:       r.Kind,
+			Namespace:  r.Namespace,
+			Name:       r.Name,
+			UID:        r.UID,
+		}
+		msg := r.String()
+		if len(msg) > maxEventMessageLength {
+			msg = msg[:maxEventMessageLength-3] + "..."
+		}
+		rec.Event(ref, corev1.EventTypeNormal, reasonAudit, msg)
+		return nil
+	})
+}
+
+// NewConfigMapSink returns a Sink that keeps the latest records of each
+// namespace in a ConfigMap of that namespace, keyed by sequence number. Once
+// limit records are kept, writing a new one drops the oldest.
+func NewConfigMapSink(c client.Client, limit int) Sink {
+	return SinkFn(func(ctx context.Context, r Record) error {
+		if r.Namespace == "" {
+			return errors.New(errNoNamespace)
+		}
+		b, err := json.Marshal(r)
+		if err != nil {
+			return errors.Wrap(err, errMarshalRecord)
+		}
+		// conflicts are only detected on unwrapped errors
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			return appendRecord(ctx, c, r.Namespace, string(b), limit)
+		})
+		return errors.Wrapf(err, errFmtSaveConfigMap, r.Namespace)
+	})
+}
+
+func appendRecord(ctx context.Context, c client.Client, namespace, record string, limit int) error {
+	cm := &corev1.ConfigMap{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ConfigMapName}, cm)
+	if kerrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   namespace,
+				Name:        ConfigMapName,
+				Annotations: map[string]string{AnnotationNextRecord: "1"},
+			},
+			Data: map[string]string{recordKey(0): record},
+		}
+		err = c.Create(ctx, cm)
+		if kerrors.IsAlreadyExists(err) {
+			// someone else created it first, retry as an update
+			return kerrors.NewConflict(corev1.Resource("configmaps"), ConfigMapName, err)
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	// a missing or malformed sequence number starts the sequence over
+	next, _ := strconv.Atoi(cm.GetAnnotations()[AnnotationNextRecord])
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[recordKey(next)] = record
+	for seq := next - limit; seq >= 0; seq-- {
+		if _, ok := cm.Data[recordKey(seq)]; !ok {
+			break
+		}
+		delete(cm.Data, recordKey(seq))
+	}
+	meta := cm.GetAnnotations()
+	if meta == nil {
+		meta = map[string]string{}
+	}
+	meta[AnnotationNextRecord] = strconv.Itoa(next + 1)
+	cm.SetAnnotations(meta)
+	return c.Update(ctx, cm)
+}
+
+// recordKey pads the sequence number so that keys sort in write order.
+func recordKey(seq int) string {
+	return fmt.Sprintf("%012d", seq)
+}
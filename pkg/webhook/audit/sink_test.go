@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestWriterSink(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := Record{Name: "example", Diff: []string{"-spec"}}
+	if err := NewWriterSink(buf).Write(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+	got := Record{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(r, got); diff != "" {
+		t.Errorf("NewWriterSink(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestAsyncSink(t *testing.T) {
+	written := make(chan Record)
+	a := NewAsyncSink(SinkFn(func(_ context.Context, r Record) error {
+		written <- r
+		return nil
+	}), 1)
+
+	// writing doesn't wait for the record to be written
+	first := Record{Name: "first"}
+	if err := a.Write(context.Background(), first); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Write(context.Background(), Record{Name: "dropped"}); err == nil {
+		t.Errorf("a.Write(...): want error writing to a full buffer, got nil")
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() { done <- a.Start(stop) }()
+	if diff := cmp.Diff(first, <-written); diff != "" {
+		t.Errorf("a.Start(...): -want, +got:\n%s", diff)
+	}
+
+	// queued records are written when stopping
+	last := Record{Name: "last"}
+	if err := a.Write(context.Background(), last); err != nil {
+		t.Fatal(err)
+	}
+	close(stop)
+	if diff := cmp.Diff(last, <-written); diff != "" {
+		t.Errorf("a.Start(...): -want, +got:\n%s", diff)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("a.Start(...): %s", err)
+	}
+}
+
+func TestEventSink(t *testing.T) {
+	rec := record.NewFakeRecorder(1)
+	r := Record{Result: ResultAdmitted, Operation: "DELETE", Kind: "Component", Namespace: "ns", Name: "example", User: "alice", Diff: []string{"-spec"}}
+	if err := NewEventSink(rec).Write(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+	want := "Normal Audit admitted DELETE Component ns/example by alice: -spec"
+	if got := <-rec.Events; got != want {
+		t.Errorf("NewEventSink(...): want %q, got %q", want, got)
+	}
+}
+
+func TestConfigMapSink(t *testing.T) {
+	var stored *corev1.ConfigMap
+	c := &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+			if stored == nil {
+				return kerrors.NewNotFound(corev1.Resource("configmaps"), key.Name)
+			}
+			stored.DeepCopyInto(obj.(*corev1.ConfigMap))
+			return nil
+		},
+		MockCreate: func(_ context.Context, obj runtime.Object, _ ...client.CreateOption) error {
+			stored = obj.(*corev1.ConfigMap).DeepCopy()
+			return nil
+		},
+		MockUpdate: func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+			stored = obj.(*corev1.ConfigMap).DeepCopy()
+			return nil
+		},
+	}
+	sink := NewConfigMapSink(c, 2)
+	for _, name := range []string{"a", "b", "c"} {
+		if err := sink.Write(context.Background(), Record{Namespace: "ns", Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var names []string
+	for _, k := range []string{recordKey(1), recordKey(2)} {
+		r := Record{}
+		if err := json.Unmarshal([]byte(stored.Data[k]), &r); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, r.Name)
+	}
+	if diff := cmp.Diff([]string{"b", "c"}, names); diff != "" {
+		t.Errorf("NewConfigMapSink(...): -want, +got:\n%s", diff)
+	}
+	if len(stored.Data) != 2 {
+		t.Errorf("NewConfigMapSink(...): want 2 records, got %d", len(stored.Data))
+	}
+	if got := stored.GetAnnotations()[AnnotationNextRecord]; got != "3" {
+		t.Errorf("NewConfigMapSink(...): want next record 3, got %s", got)
+	}
+	if stored.GetNamespace() != "ns" || stored.GetName() != ConfigMapName {
+		t.Errorf("NewConfigMapSink(...): unexpected ConfigMap %s/%s", stored.GetNamespace(), stored.GetName())
+	}
+}
@@ -0,0 +1,517 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package certificate provisions a self-signed CA and serving certificate for
+// the admission webhook server, and rotates them before they expire.
+package certificate
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	admissionv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+// Keys of the data stored in the certificate Secret.
+const (
+	CACertKey  = "ca.crt"
+	CAKeyKey   = "ca.key"
+	TLSCertKey = corev1.TLSCertKey
+	TLSKeyKey  = corev1.TLSPrivateKeyKey
+
+	// PreviousCACertKey is the key of the CA certificate that was rotated
+	// out. It stays trusted by the webhook configurations until every replica
+	// had the chance to serve a certificate signed by the new CA.
+	PreviousCACertKey = "ca.crt.previous"
+)
+
+// AnnotationCARotatedAt records on the certificate Secret when its CA was
+// last rotated.
+const AnnotationCARotatedAt = "webhook.oam.dev/ca-rotated-at"
+
+const (
+	caValidity      = 10 * 365 * 24 * time.Hour
+	servingValidity = 365 * 24 * time.Hour
+	rsaKeySize      = 2048
+
+	defaultRotateBefore  = 30 * 24 * time.Hour
+	defaultCheckInterval = 1 * time.Hour
+
+	errGetSecret            = "cannot get webhook certificate secret"
+	errCreateSecret         = "cannot create webhook certificate secret"
+	errUpdateSecret         = "cannot update webhook certificate secret"
+	errGenerateCA           = "cannot generate webhook CA certificate"
+	errGenerateServing      = "cannot generate webhook serving certificate"
+	errWriteCerts           = "cannot write webhook certificate files"
+	errWatchWebhookConfigs  = "cannot watch webhook configurations"
+	errFmtPatchMutating     = "cannot patch caBundle into MutatingWebhookConfiguration %q"
+	errFmtPatchValidating   = "cannot patch caBundle into ValidatingWebhookConfiguration %q"
+	errFmtDecodeCertificate = "cannot decode PEM certificate %q"
+)
+
+// Options configures how webhook certificates are provisioned.
+type Options struct {
+	// CertDir is the directory the webhook server reads tls.crt and tls.key from.
+	CertDir string
+
+	// SecretName and SecretNamespace locate the Secret the CA and serving
+	// certificate are stored in.
+	SecretName      string
+	SecretNamespace string
+
+	// ServiceName is the name of the Service in front of the webhook server,
+	// it must be in SecretNamespace.
+	ServiceName string
+
+	// WebhookConfigurationName is the name of the MutatingWebhookConfiguration
+	// and ValidatingWebhookConfiguration whose caBundle will be patched.
+	WebhookConfigurationName string
+
+	// RotateBefore is how long before expiry a certificate will be rotated.
+	RotateBefore time.Duration
+
+	// CheckInterval is how often certificates are checked for rotation.
+	CheckInterval time.Duration
+}
+
+// A Provisioner generates, stores, rotates and distributes the certificates
+// used by the admission webhook server.
+type Provisioner struct {
+	client client.Client
+	cache  cache.Cache
+	opts   Options
+	log    logging.Logger
+	now    func() time.Time
+}
+
+var _ manager.Runnable = &Provisioner{}
+var _ manager.LeaderElectionRunnable = &Provisioner{}
+var _ inject.Cache = &Provisioner{}
+
+// NewProvisioner returns a Provisioner. The client should not be backed by
+// the manager's cache, since certificates must be provisioned before the
+// manager is started.
+func NewProvisioner(c client.Client, opts Options, l logging.Logger) *Provisioner {
+	if opts.RotateBefore == 0 {
+		opts.RotateBefore = defaultRotateBefore
+	}
+	if opts.CheckInterval == 0 {
+		opts.CheckInterval = defaultCheckInterval
+	}
+	return &Provisioner{client: c, opts: opts, log: l, now: time.Now}
+}
+
+// InjectCache supplies the cache the webhook configurations are watched with.
+// The manager injects its cache when the Provisioner is added to it.
+func (p *Provisioner) InjectCache(c cache.Cache) error {
+	p.cache = c
+	return nil
+}
+
+// Start checks the certificates periodically until the stop channel is closed.
+// The caBundle is patched again as soon as the webhook configurations are
+// created or updated, e.g. by a helm upgrade that resets it.
+func (p *Provisioner) Start(stop <-chan struct{}) error {
+	changed := make(chan struct{}, 1)
+	if p.cache != nil {
+		if err := p.watchWebhookConfigurations(changed); err != nil {
+			return err
+		}
+	}
+	ticker := time.NewTicker(p.opts.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+			if err := p.Provision(context.Background()); err != nil {
+				p.log.Info("Cannot provision webhook certificates", "error", err)
+			}
+		case <-changed:
+			if err := p.Trust(context.Background()); err != nil {
+				p.log.Info("Cannot patch caBundle into webhook configurations", "error", err)
+			}
+		}
+	}
+}
+
+// watchWebhookConfigurations signals the changed channel whenever the webhook
+// configurations are created or updated.
+func (p *Provisioner) watchWebhookConfigurations(changed chan<- struct{}) error {
+	notify := func(obj interface{}) {
+		if o, ok := obj.(metav1.Object); !ok || o.GetName() != p.opts.WebhookConfigurationName {
+			return
+		}
+		select {
+		case changed <- struct{}{}:
+		default:
+			// a patch is pending already
+		}
+	}
+	handler := toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    notify,
+		UpdateFunc: func(_, obj interface{}) { notify(obj) },
+	}
+	for _, obj := range []runtime.Object{
+		&admissionv1beta1.MutatingWebhookConfiguration{},
+		&admissionv1beta1.ValidatingWebhookConfiguration{},
+	} {
+		i, err := p.cache.GetInformer(context.Background(), obj)
+		if err != nil {
+			return errors.Wrap(err, errWatchWebhookConfigs)
+		}
+		i.AddEventHandler(handler)
+	}
+	return nil
+}
+
+// NeedLeaderElection returns false, every replica serves webhooks and needs
+// the certificates on its own disk.
+func (p *Provisioner) NeedLeaderElection() bool {
+	return false
+}
+
+// Provision makes sure a valid CA and serving certificate are stored in the
+// Secret, written to the cert dir and trusted by the webhook configurations.
+func (p *Provisioner) Provision(ctx context.Context) error {
+	secret, err := p.ensureSecret(ctx)
+	if err != nil {
+		return err
+	}
+	// trust a new CA before serving a certificate it signed
+	if err := p.patchCABundle(ctx, caBundle(secret)); err != nil {
+		return err
+	}
+	return errors.Wrap(p.writeCerts(secret), errWriteCerts)
+}
+
+// Trust makes the webhook configurations trust the CAs stored in the Secret,
+// without rotating any certificate.
+func (p *Provisioner) Trust(ctx context.Context) error {
+	secret := &corev1.Secret{}
+	nn := types.NamespacedName{Namespace: p.opts.SecretNamespace, Name: p.opts.SecretName}
+	if err := p.client.Get(ctx, nn, secret); err != nil {
+		return errors.Wrap(err, errGetSecret)
+	}
+	return p.patchCABundle(ctx, caBundle(secret))
+}
+
+func (p *Provisioner) ensureSecret(ctx context.Context) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	nn := types.NamespacedName{Namespace: p.opts.SecretNamespace, Name: p.opts.SecretName}
+	err := p.client.Get(ctx, nn, secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, errGetSecret)
+	}
+	if apierrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: p.opts.SecretNamespace, Name: p.opts.SecretName},
+			Type:       corev1.SecretTypeTLS,
+		}
+		if err := p.renew(secret); err != nil {
+			return nil, err
+		}
+		p.log.Info("Creating webhook certificate secret", "secret", nn.String())
+		if err := p.client.Create(ctx, secret); err != nil {
+			if !apierrors.IsAlreadyExists(err) {
+				return nil, errors.Wrap(err, errCreateSecret)
+			}
+			// another replica created it first, use theirs
+			if err := p.client.Get(ctx, nn, secret); err != nil {
+				return nil, errors.Wrap(err, errGetSecret)
+			}
+		}
+		return secret, nil
+	}
+
+	switch {
+	case p.needsRotation(secret.Data[CACertKey]) || p.needsRotation(secret.Data[TLSCertKey]):
+		if err := p.renew(secret); err != nil {
+			return nil, err
+		}
+		p.log.Info("Rotating webhook certificates", "secret", nn.String())
+	case p.previousCAExpired(secret):
+		delete(secret.Data, PreviousCACertKey)
+		meta.RemoveAnnotations(secret, AnnotationCARotatedAt)
+		p.log.Info("Dropping the previous webhook CA", "secret", nn.String())
+	default:
+		return secret, nil
+	}
+	if err := p.client.Update(ctx, secret); err != nil {
+		if !apierrors.IsConflict(err) {
+			return nil, errors.Wrap(err, errUpdateSecret)
+		}
+		// another replica rotated it first, use theirs
+		if err := p.client.Get(ctx, nn, secret); err != nil {
+			return nil, errors.Wrap(err, errGetSecret)
+		}
+	}
+	return secret, nil
+}
+
+// renew regenerates the serving certificate of the secret, and the CA as well
+// if it is missing or about to expire. A CA that is rotated out is kept as the
+// previous CA, so that serving certificates it signed stay trusted for a while.
+func (p *Provisioner) renew(secret *corev1.Secret) error {
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	now := p.now()
+	caCertPEM, caKeyPEM := secret.Data[CACertKey], secret.Data[CAKeyKey]
+	if p.needsRotation(caCertPEM) || len(caKeyPEM) == 0 {
+		if _, err := parseCertificate(caCertPEM); err == nil {
+			secret.Data[PreviousCACertKey] = caCertPEM
+			meta.AddAnnotations(secret, map[string]string{AnnotationCARotatedAt: now.Format(time.RFC3339)})
+		}
+		var err error
+		caCertPEM, caKeyPEM, err = GenerateCA(now)
+		if err != nil {
+			return errors.Wrap(err, errGenerateCA)
+		}
+	}
+	certPEM, keyPEM, err := GenerateServingCert(caCertPEM, caKeyPEM, DNSNames(p.opts.ServiceName, p.opts.SecretNamespace), now)
+	if err != nil {
+		return errors.Wrap(err, errGenerateServing)
+	}
+	secret.Data[CACertKey] = caCertPEM
+	secret.Data[CAKeyKey] = caKeyPEM
+	secret.Data[TLSCertKey] = certPEM
+	secret.Data[TLSKeyKey] = keyPEM
+	return nil
+}
+
+// previousCAExpired returns true if the secret has a previous CA that no longer
+// needs to be trusted. Every replica checks its certificates once per check
+// interval, so after two of them they all serve a certificate signed by the
+// current CA.
+func (p *Provisioner) previousCAExpired(secret *corev1.Secret) bool {
+	if _, ok := secret.Data[PreviousCACertKey]; !ok {
+		return false
+	}
+	rotatedAt, err := time.Parse(time.RFC3339, secret.GetAnnotations()[AnnotationCARotatedAt])
+	if err != nil {
+		return true
+	}
+	return !p.now().Before(rotatedAt.Add(2 * p.opts.CheckInterval))
+}
+
+// caBundle returns the CAs the webhook configurations should trust.
+func caBundle(secret *corev1.Secret) []byte {
+	return append(append([]byte{}, secret.Data[CACertKey]...), secret.Data[PreviousCACertKey]...)
+}
+
+// needsRotation returns true if the PEM encoded certificate is missing,
+// malformed or will expire within the rotation window.
+func (p *Provisioner) needsRotation(certPEM []byte) bool {
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return true
+	}
+	return p.now().Add(p.opts.RotateBefore).After(cert.NotAfter)
+}
+
+func (p *Provisioner) writeCerts(secret *corev1.Secret) error {
+	if err := os.MkdirAll(p.opts.CertDir, 0700); err != nil {
+		return err
+	}
+	// the webhook server reloads the key pair on every write, write the key
+	// first so that the last reload always sees a matching pair.
+	for _, key := range []string{TLSKeyKey, TLSCertKey} {
+		path := filepath.Join(p.opts.CertDir, key)
+		if current, err := ioutil.ReadFile(filepath.Clean(path)); err == nil && bytes.Equal(current, secret.Data[key]) {
+			continue
+		}
+		if err := ioutil.WriteFile(path, secret.Data[key], 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// patchCABundle makes the webhook configurations trust the CA. Webhook
+// configurations that do not exist (yet) are skipped and patched on the next
+// check.
+func (p *Provisioner) patchCABundle(ctx context.Context, caBundle []byte) error {
+	name := p.opts.WebhookConfigurationName
+	mwc := &admissionv1beta1.MutatingWebhookConfiguration{}
+	if err := p.client.Get(ctx, types.NamespacedName{Name: name}, mwc); resource.IgnoreNotFound(err) != nil {
+		return errors.Wrapf(err, errFmtPatchMutating, name)
+	} else if err == nil {
+		changed := false
+		for i := range mwc.Webhooks {
+			if !bytes.Equal(mwc.Webhooks[i].ClientConfig.CABundle, caBundle) {
+				mwc.Webhooks[i].ClientConfig.CABundle = caBundle
+				changed = true
+			}
+		}
+		if changed {
+			if err := p.client.Update(ctx, mwc); err != nil {
+				return errors.Wrapf(err, errFmtPatchMutating, name)
+			}
+			p.log.Debug("Patched caBundle", "mutatingwebhookconfiguration", name)
+		}
+	}
+
+	vwc := &admissionv1beta1.ValidatingWebhookConfiguration{}
+	if err := p.client.Get(ctx, types.NamespacedName{Name: name}, vwc); resource.IgnoreNotFound(err) != nil {
+		return errors.Wrapf(err, errFmtPatchValidating, name)
+	} else if err == nil {
+		changed := false
+		for i := range vwc.Webhooks {
+			if !bytes.Equal(vwc.Webhooks[i].ClientConfig.CABundle, caBundle) {
+				vwc.Webhooks[i].ClientConfig.CABundle = caBundle
+				changed = true
+			}
+		}
+		if changed {
+			if err := p.client.Update(ctx, vwc); err != nil {
+				return errors.Wrapf(err, errFmtPatchValidating, name)
+			}
+			p.log.Debug("Patched caBundle", "validatingwebhookconfiguration", name)
+		}
+	}
+	return nil
+}
+
+// DNSNames returns the DNS names a Service is reachable at from the API server.
+func DNSNames(service, namespace string) []string {
+	return []string{
+		service,
+		fmt.Sprintf("%s.%s", service, namespace),
+		fmt.Sprintf("%s.%s.svc", service, namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", service, namespace),
+	}
+}
+
+// GenerateCA generates a self-signed CA certificate and its private key, both
+// PEM encoded.
+func GenerateCA(now time.Time) ([]byte, []byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, rsaKeySize)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "oam-kubernetes-runtime-webhook-ca"},
+		NotBefore:             now.Add(-1 * time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encodeCertificate(der), encodeKey(key), nil
+}
+
+// GenerateServingCert generates a serving certificate for the supplied DNS
+// names signed by the supplied CA, both PEM encoded.
+func GenerateServingCert(caCertPEM, caKeyPEM []byte, dnsNames []string, now time.Time) ([]byte, []byte, error) {
+	caCert, err := parseCertificate(caCertPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+	caKey, err := parseKey(caKeyPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := rsa.GenerateKey(rand.Reader, rsaKeySize)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	notAfter := now.Add(servingValidity)
+	if notAfter.After(caCert.NotAfter) {
+		notAfter = caCert.NotAfter
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    now.Add(-1 * time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encodeCertificate(der), encodeKey(key), nil
+}
+
+func newSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+func encodeCertificate(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func encodeKey(key *rsa.PrivateKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func parseCertificate(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, errors.Errorf(errFmtDecodeCertificate, "CERTIFICATE")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func parseKey(keyPEM []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.Errorf(errFmtDecodeCertificate, "RSA PRIVATE KEY")
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
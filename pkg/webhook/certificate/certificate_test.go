@@ -0,0 +1,325 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"context"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/stretchr/testify/assert"
+	admissionv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestGenerateServingCert(t *testing.T) {
+	now := time.Now()
+	caCertPEM, caKeyPEM, err := GenerateCA(now)
+	assert.NoError(t, err)
+	certPEM, _, err := GenerateServingCert(caCertPEM, caKeyPEM, DNSNames("webhook", "oam-system"), now)
+	assert.NoError(t, err)
+
+	caCert, err := parseCertificate(caCertPEM)
+	assert.NoError(t, err)
+	cert, err := parseCertificate(certPEM)
+	assert.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	_, err = cert.Verify(x509.VerifyOptions{
+		DNSName:     "webhook.oam-system.svc",
+		Roots:       roots,
+		CurrentTime: now,
+	})
+	assert.NoError(t, err)
+}
+
+func TestNeedsRotation(t *testing.T) {
+	now := time.Now()
+	caCertPEM, _, err := GenerateCA(now)
+	assert.NoError(t, err)
+
+	cases := map[string]struct {
+		now  time.Time
+		cert []byte
+		want bool
+	}{
+		"Missing": {
+			now:  now,
+			cert: nil,
+			want: true,
+		},
+		"Valid": {
+			now:  now,
+			cert: caCertPEM,
+			want: false,
+		},
+		"AboutToExpire": {
+			now:  now.Add(caValidity - 24*time.Hour),
+			cert: caCertPEM,
+			want: true,
+		},
+	}
+	for name, tc := range cases {
+		p := NewProvisioner(nil, Options{}, logging.NewNopLogger())
+		p.now = func() time.Time { return tc.now }
+		assert.Equal(t, tc.want, p.needsRotation(tc.cert), name)
+	}
+}
+
+func TestProvision(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webhook-certs")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var created *corev1.Secret
+	var mutating, validating [][]byte
+	c := &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+			switch o := obj.(type) {
+			case *corev1.Secret:
+				return kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, key.Name)
+			case *admissionv1beta1.MutatingWebhookConfiguration:
+				o.Webhooks = make([]admissionv1beta1.MutatingWebhook, 2)
+			case *admissionv1beta1.ValidatingWebhookConfiguration:
+				o.Webhooks = make([]admissionv1beta1.ValidatingWebhook, 2)
+			}
+			return nil
+		},
+		MockCreate: func(_ context.Context, obj runtime.Object, _ ...client.CreateOption) error {
+			created = obj.(*corev1.Secret)
+			return nil
+		},
+		MockUpdate: func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+			switch o := obj.(type) {
+			case *admissionv1beta1.MutatingWebhookConfiguration:
+				for _, w := range o.Webhooks {
+					mutating = append(mutating, w.ClientConfig.CABundle)
+				}
+			case *admissionv1beta1.ValidatingWebhookConfiguration:
+				for _, w := range o.Webhooks {
+					validating = append(validating, w.ClientConfig.CABundle)
+				}
+			}
+			return nil
+		},
+	}
+	p := NewProvisioner(c, Options{
+		CertDir:                  dir,
+		SecretName:               "webhook-server-cert",
+		SecretNamespace:          "oam-system",
+		ServiceName:              "oam-kubernetes-runtime-webhook",
+		WebhookConfigurationName: "oam-kubernetes-runtime",
+	}, logging.NewNopLogger())
+
+	assert.NoError(t, p.Provision(context.Background()))
+	assert.NotNil(t, created)
+	ca := created.Data[CACertKey]
+	assert.NotEmpty(t, ca)
+	assert.Equal(t, [][]byte{ca, ca}, mutating)
+	assert.Equal(t, [][]byte{ca, ca}, validating)
+
+	for _, key := range []string{TLSCertKey, TLSKeyKey} {
+		written, err := ioutil.ReadFile(filepath.Join(dir, key))
+		assert.NoError(t, err)
+		assert.Equal(t, created.Data[key], written, key)
+	}
+}
+
+func TestProvisionCARotation(t *testing.T) {
+	now := time.Now()
+	oldCA, oldKey, err := GenerateCA(now.Add(-caValidity + 24*time.Hour))
+	assert.NoError(t, err)
+	ca, caKey, err := GenerateCA(now)
+	assert.NoError(t, err)
+	dnsNames := DNSNames("oam-kubernetes-runtime-webhook", "oam-system")
+	oldCert, oldCertKey, err := GenerateServingCert(oldCA, oldKey, dnsNames, now)
+	assert.NoError(t, err)
+	cert, certKey, err := GenerateServingCert(ca, caKey, dnsNames, now)
+	assert.NoError(t, err)
+	rotated := func(at time.Time) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{AnnotationCARotatedAt: at.Format(time.RFC3339)}},
+			Data: map[string][]byte{CACertKey: ca, CAKeyKey: caKey, TLSCertKey: cert, TLSKeyKey: certKey,
+				PreviousCACertKey: oldCA},
+		}
+	}
+
+	cases := map[string]struct {
+		secret       *corev1.Secret
+		wantUpdated  bool
+		wantPrevious []byte
+	}{
+		"RotateCA": {
+			secret: &corev1.Secret{Data: map[string][]byte{
+				CACertKey: oldCA, CAKeyKey: oldKey, TLSCertKey: oldCert, TLSKeyKey: oldCertKey}},
+			wantUpdated:  true,
+			wantPrevious: oldCA,
+		},
+		"KeepPreviousCA": {
+			secret:       rotated(now.Add(-time.Hour)),
+			wantPrevious: oldCA,
+		},
+		"DropPreviousCA": {
+			secret:      rotated(now.Add(-3 * time.Hour)),
+			wantUpdated: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "webhook-certs")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			var updated *corev1.Secret
+			var bundles [][]byte
+			c := &test.MockClient{
+				MockGet: func(_ context.Context, _ client.ObjectKey, obj runtime.Object) error {
+					switch o := obj.(type) {
+					case *corev1.Secret:
+						tc.secret.DeepCopyInto(o)
+					case *admissionv1beta1.MutatingWebhookConfiguration:
+						o.Webhooks = make([]admissionv1beta1.MutatingWebhook, 1)
+					case *admissionv1beta1.ValidatingWebhookConfiguration:
+						return kerrors.NewNotFound(schema.GroupResource{}, "")
+					}
+					return nil
+				},
+				MockUpdate: func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+					switch o := obj.(type) {
+					case *corev1.Secret:
+						updated = o
+					case *admissionv1beta1.MutatingWebhookConfiguration:
+						bundles = append(bundles, o.Webhooks[0].ClientConfig.CABundle)
+					}
+					return nil
+				},
+			}
+			p := NewProvisioner(c, Options{
+				CertDir:         dir,
+				SecretName:      "webhook-server-cert",
+				SecretNamespace: "oam-system",
+				ServiceName:     "oam-kubernetes-runtime-webhook",
+				CheckInterval:   time.Hour,
+			}, logging.NewNopLogger())
+
+			assert.NoError(t, p.Provision(context.Background()))
+			assert.Equal(t, tc.wantUpdated, updated != nil, "secret updated")
+			secret := tc.secret
+			if updated != nil {
+				secret = updated
+			}
+			assert.Equal(t, tc.wantPrevious, secret.Data[PreviousCACertKey])
+			want := append(append([]byte{}, secret.Data[CACertKey]...), tc.wantPrevious...)
+			assert.Equal(t, [][]byte{want}, bundles)
+			if tc.wantPrevious != nil {
+				assert.NotEqual(t, tc.wantPrevious, secret.Data[CACertKey])
+			}
+		})
+	}
+}
+
+// registeringInformers signals once an event handler is added to the
+// informer of MutatingWebhookConfigurations.
+type registeringInformers struct {
+	informertest.FakeInformers
+	registered chan struct{}
+}
+
+func (c *registeringInformers) GetInformer(ctx context.Context, obj runtime.Object) (cache.Informer, error) {
+	i, err := c.FakeInformers.GetInformer(ctx, obj)
+	if _, ok := obj.(*admissionv1beta1.MutatingWebhookConfiguration); ok && err == nil {
+		return &registeringInformer{Informer: i, registered: c.registered}, nil
+	}
+	return i, err
+}
+
+type registeringInformer struct {
+	cache.Informer
+	registered chan struct{}
+}
+
+func (i *registeringInformer) AddEventHandler(h toolscache.ResourceEventHandler) {
+	i.Informer.AddEventHandler(h)
+	close(i.registered)
+}
+
+func TestStartPatchesChangedWebhookConfigurations(t *testing.T) {
+	ca, caKey, err := GenerateCA(time.Now())
+	assert.NoError(t, err)
+	secret := &corev1.Secret{Data: map[string][]byte{CACertKey: ca, CAKeyKey: caKey}}
+
+	patched := make(chan []byte, 1)
+	c := &test.MockClient{
+		MockGet: func(_ context.Context, _ client.ObjectKey, obj runtime.Object) error {
+			switch o := obj.(type) {
+			case *corev1.Secret:
+				secret.DeepCopyInto(o)
+			case *admissionv1beta1.MutatingWebhookConfiguration:
+				// a helm upgrade reset the caBundle
+				o.Webhooks = make([]admissionv1beta1.MutatingWebhook, 1)
+			case *admissionv1beta1.ValidatingWebhookConfiguration:
+				return kerrors.NewNotFound(schema.GroupResource{}, "")
+			}
+			return nil
+		},
+		MockUpdate: func(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+			if o, ok := obj.(*admissionv1beta1.MutatingWebhookConfiguration); ok {
+				patched <- o.Webhooks[0].ClientConfig.CABundle
+			}
+			return nil
+		},
+	}
+	p := NewProvisioner(c, Options{
+		WebhookConfigurationName: "oam-kubernetes-runtime",
+		CheckInterval:            time.Hour,
+	}, logging.NewNopLogger())
+	informers := &registeringInformers{registered: make(chan struct{})}
+	assert.NoError(t, p.InjectCache(informers))
+	mutating, err := informers.FakeInformerFor(&admissionv1beta1.MutatingWebhookConfiguration{})
+	assert.NoError(t, err)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() { _ = p.Start(stop) }()
+	<-informers.registered
+
+	other := &admissionv1beta1.MutatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+	mutating.Update(other, other)
+	changed := &admissionv1beta1.MutatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "oam-kubernetes-runtime"}}
+	mutating.Update(changed, changed)
+	select {
+	case bundle := <-patched:
+		assert.Equal(t, ca, bundle)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start(...): the changed MutatingWebhookConfiguration was not patched")
+	}
+}
@@ -1,21 +1,25 @@
 package v1alpha2
 
 import (
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/webhook/audit"
 	"github.com/crossplane/oam-kubernetes-runtime/pkg/webhook/v1alpha2/applicationconfiguration"
 	"github.com/crossplane/oam-kubernetes-runtime/pkg/webhook/v1alpha2/component"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/webhook/v1alpha2/definition"
 
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
-// Add will be called in main and register all validation handlers
-func Add(mgr manager.Manager) error {
-	if err := applicationconfiguration.RegisterValidatingHandler(mgr); err != nil {
+// Add will be called in main and register all validation handlers, admitted
+// changes to ApplicationConfigurations and Components are recorded by the
+// auditor unless it is nil.
+func Add(mgr manager.Manager, auditor *audit.Auditor) error {
+	if err := applicationconfiguration.RegisterValidatingHandler(mgr, auditor); err != nil {
 		return err
 	}
 	applicationconfiguration.RegisterMutatingHandler(mgr)
 	if err := component.RegisterMutatingHandler(mgr); err != nil {
 		return err
 	}
-	component.RegisterValidatingHandler(mgr)
-	return nil
+	component.RegisterValidatingHandler(mgr, auditor)
+	return definition.RegisterValidatingHandler(mgr)
 }
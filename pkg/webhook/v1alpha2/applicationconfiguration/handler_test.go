@@ -101,6 +101,7 @@ var _ = Describe("ApplicationConfiguration Admission controller Test", func() {
 					Object:    runtime.RawExtension{Raw: util.JSONMarshal(appConfig)},
 				},
 			}
+			handler.(inject.Client).InjectClient(&test.MockClient{MockList: test.NewMockListFn(nil)})
 			resp := handler.Handle(context.TODO(), req)
 			Expect(resp.Allowed).Should(BeTrue())
 		})
@@ -123,6 +124,13 @@ var _ = Describe("ApplicationConfiguration Admission controller Test", func() {
 			mutatedTrait := baseTrait.DeepCopy()
 			mutatedTrait.SetNamespace(appConfig.GetNamespace())
 			mutatedTrait.SetLabels(util.MergeMapOverrideWithDst(label, map[string]string{oam.TraitTypeLabel: traitTypeName}))
+			// set up a definition that refers to a version other than the storage version
+			traitDefV2 := *traitDef.DeepCopy()
+			traitDefV2.Spec.Reference.Version = "v2"
+			crdV2 := *crd.DeepCopy()
+			crdV2.Spec.Versions = append(crdV2.Spec.Versions, crdv1.CustomResourceDefinitionVersion{Name: "v2", Served: true})
+			mutatedTraitV2 := mutatedTrait.DeepCopy()
+			mutatedTraitV2.SetAPIVersion("example.com/v2")
 			tests := map[string]struct {
 				client client.Client
 				trait  interface{}
@@ -171,6 +179,21 @@ var _ = Describe("ApplicationConfiguration Admission controller Test", func() {
 					trait:  traitWithType.DeepCopyObject(),
 					wanted: util.JSONMarshal(mutatedTrait),
 				},
+				"update gvk with definition version case": {
+					client: &test.MockClient{
+						MockGet: func(ctx context.Context, key types.NamespacedName, obj runtime.Object) error {
+							switch o := obj.(type) {
+							case *v1alpha2.TraitDefinition:
+								*o = traitDefV2
+							case *crdv1.CustomResourceDefinition:
+								*o = crdV2
+							}
+							return nil
+						},
+					},
+					trait:  traitWithType.DeepCopyObject(),
+					wanted: util.JSONMarshal(mutatedTraitV2),
+				},
 			}
 			for testCase, test := range tests {
 				By(fmt.Sprintf("start test : %s", testCase))
@@ -241,6 +264,7 @@ var _ = Describe("ApplicationConfiguration Admission controller Test", func() {
 		}
 
 		clientInstance := &test.MockClient{
+			MockList: test.NewMockListFn(nil),
 			MockGet: func(ctx context.Context, key types.NamespacedName, obj runtime.Object) error {
 				switch o := obj.(type) {
 				case *v1alpha2.Component:
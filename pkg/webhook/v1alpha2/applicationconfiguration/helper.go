@@ -20,12 +20,23 @@ const (
 	errFmtUnmarshalWorkload     = "cannot unmarshal workload of component %q"
 	errFmtUnmarshalTrait        = "cannot unmarshal trait of component %q"
 	errFmtGetWorkloadDefinition = "cannot get workload definition of component %q"
+	errFmtGetScopeDefinition    = "cannot get scope definition of scope %q in component %q"
+	errListAppConfigs           = "cannot list application configurations"
+	errListAppPolicies          = "cannot list app policies"
+	errListClusterAppPolicies   = "cannot list cluster app policies"
 )
 
 // ValidatingAppConfig is used for validating ApplicationConfiguration
 type ValidatingAppConfig struct {
 	appConfig       v1alpha2.ApplicationConfiguration
 	validatingComps []ValidatingComponent
+
+	// other ApplicationConfigurations in the same namespace, only fetched
+	// when a scope of this ApplicationConfiguration disallows component overlap
+	namespaceAppConfigs []v1alpha2.ApplicationConfiguration
+
+	// AppPolicies of the namespace and all ClusterAppPolicies
+	policies []appPolicy
 }
 
 // ValidatingComponent is used for validatiing ApplicationConfigurationComponent
@@ -38,6 +49,7 @@ type ValidatingComponent struct {
 	workloadDefinition v1alpha2.WorkloadDefinition
 	workloadContent    unstructured.Unstructured
 	validatingTraits   []ValidatingTrait
+	validatingScopes   []ValidatingScope
 }
 
 // ValidatingTrait is used for validating Trait
@@ -49,10 +61,19 @@ type ValidatingTrait struct {
 	traitContent    unstructured.Unstructured
 }
 
+// ValidatingScope is used for validating Scope
+type ValidatingScope struct {
+	componentScope v1alpha2.ComponentScope
+
+	// below data is convenient for validation
+	scopeDefinition v1alpha2.ScopeDefinition
+}
+
 // PrepareForValidation prepares data for validations to avoiding repetitive GET/unmarshal operations
 func (v *ValidatingAppConfig) PrepareForValidation(ctx context.Context, c client.Reader, dm discoverymapper.DiscoveryMapper, ac *v1alpha2.ApplicationConfiguration) error {
 	v.appConfig = *ac
 	v.validatingComps = make([]ValidatingComponent, 0, len(ac.Spec.Components))
+	checkOverlap := false
 	for _, acc := range ac.Spec.Components {
 		tmp := ValidatingComponent{}
 		tmp.appConfigComponent = acc
@@ -79,7 +100,7 @@ func (v *ValidatingAppConfig) PrepareForValidation(ctx context.Context, c client
 		tmp.workloadContent = wl
 
 		// get workload definition
-		wlDef, err := util.FetchWorkloadDefinition(ctx, c, dm, &wl)
+		wlDef, err := util.FetchWorkloadDefinition(ctx, c, dm, ac.Namespace, &wl)
 		if err != nil {
 			return errors.Wrapf(err, errFmtGetWorkloadDefinition, tmp.compName)
 		}
@@ -98,7 +119,7 @@ func (v *ValidatingAppConfig) PrepareForValidation(ctx context.Context, c client
 				Object: tContentObject,
 			}
 			// get trait definition
-			tDef, err := util.FetchTraitDefinition(ctx, c, dm, &tContent)
+			tDef, err := util.FetchTraitDefinition(ctx, c, dm, ac.Namespace, &tContent)
 			if err != nil {
 				return errors.Wrapf(err, errFmtGetTraitDefinition, tmp.compName)
 			}
@@ -106,8 +127,52 @@ func (v *ValidatingAppConfig) PrepareForValidation(ctx context.Context, c client
 			tmpT.traitDefinition = *tDef
 			tmp.validatingTraits = append(tmp.validatingTraits, tmpT)
 		}
+
+		tmp.validatingScopes = make([]ValidatingScope, 0, len(acc.Scopes))
+		for _, s := range acc.Scopes {
+			scope := unstructured.Unstructured{}
+			scope.SetAPIVersion(s.ScopeReference.APIVersion)
+			scope.SetKind(s.ScopeReference.Kind)
+			// get scope definition
+			sDef, err := util.FetchScopeDefinition(ctx, c, dm, ac.Namespace, &scope)
+			if err != nil {
+				return errors.Wrapf(err, errFmtGetScopeDefinition, s.ScopeReference.Name, tmp.compName)
+			}
+			if !sDef.Spec.AllowComponentOverlap {
+				checkOverlap = true
+			}
+			tmp.validatingScopes = append(tmp.validatingScopes, ValidatingScope{componentScope: s, scopeDefinition: *sDef})
+		}
 		v.validatingComps = append(v.validatingComps, tmp)
 	}
+
+	if checkOverlap {
+		acList := &v1alpha2.ApplicationConfigurationList{}
+		if err := c.List(ctx, acList, client.InNamespace(ac.Namespace)); err != nil {
+			return errors.Wrap(err, errListAppConfigs)
+		}
+		for _, item := range acList.Items {
+			if item.Name == ac.Name {
+				continue
+			}
+			v.namespaceAppConfigs = append(v.namespaceAppConfigs, item)
+		}
+	}
+
+	policies := &v1alpha2.AppPolicyList{}
+	if err := c.List(ctx, policies, client.InNamespace(ac.Namespace)); err != nil {
+		return errors.Wrap(err, errListAppPolicies)
+	}
+	for _, p := range policies.Items {
+		v.policies = append(v.policies, appPolicy{kind: v1alpha2.AppPolicyKind, name: p.Name, spec: p.Spec})
+	}
+	clusterPolicies := &v1alpha2.ClusterAppPolicyList{}
+	if err := c.List(ctx, clusterPolicies); err != nil {
+		return errors.Wrap(err, errListClusterAppPolicies)
+	}
+	for _, p := range clusterPolicies.Items {
+		v.policies = append(v.policies, appPolicy{kind: v1alpha2.ClusterAppPolicyKind, name: p.Name, spec: p.Spec})
+	}
 	return nil
 }
 
@@ -53,10 +53,15 @@ func (h *MutatingHandler) Handle(ctx context.Context, req admission.Request) adm
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 	// mutate the object
-	if err := h.Mutate(obj); err != nil {
+	if err := h.mutate(ctx, req.Namespace, obj); err != nil {
 		mutatelog.Error(err, "failed to mutate the applicationConfiguration", "name", obj.Name)
 		return admission.Errored(http.StatusBadRequest, err)
 	}
+	// attach the default traits of the namespace
+	if err := h.InjectTraits(ctx, req.Namespace, obj); err != nil {
+		mutatelog.Error(err, "failed to inject traits into the applicationConfiguration", "name", obj.Name)
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
 	mutatelog.Info("Print the mutated obj", "obj name", obj.Name, "mutated obj", spew.Sdump(obj.Spec))
 
 	marshalled, err := json.Marshal(obj)
@@ -74,6 +79,12 @@ func (h *MutatingHandler) Handle(ctx context.Context, req admission.Request) adm
 
 // Mutate sets all the default value for the Component
 func (h *MutatingHandler) Mutate(obj *v1alpha2.ApplicationConfiguration) error {
+	return h.mutate(context.TODO(), obj.GetNamespace(), obj)
+}
+
+// mutate sets all the default value for the ApplicationConfiguration of the
+// namespace, whose definitions shadow the cluster scoped ones
+func (h *MutatingHandler) mutate(ctx context.Context, namespace string, obj *v1alpha2.ApplicationConfiguration) error {
 	mutatelog.Info("mutate", "name", obj.Name)
 
 	for compIdx, comp := range obj.Spec.Components {
@@ -83,7 +94,7 @@ func (h *MutatingHandler) Mutate(obj *v1alpha2.ApplicationConfiguration) error {
 			if err := json.Unmarshal(tr.Trait.Raw, &content); err != nil {
 				return err
 			}
-			rawByte, mutated, err := h.mutateTrait(content, comp.ComponentName)
+			rawByte, mutated, err := h.mutateTrait(ctx, namespace, content, comp.ComponentName)
 			if err != nil {
 				return err
 			}
@@ -102,7 +113,8 @@ func (h *MutatingHandler) Mutate(obj *v1alpha2.ApplicationConfiguration) error {
 	return nil
 }
 
-func (h *MutatingHandler) mutateTrait(content map[string]interface{}, compName string) ([]byte, bool, error) {
+func (h *MutatingHandler) mutateTrait(ctx context.Context, namespace string, content map[string]interface{},
+	compName string) ([]byte, bool, error) {
 	if content[TraitTypeField] == nil {
 		return nil, false, nil
 	}
@@ -111,14 +123,18 @@ func (h *MutatingHandler) mutateTrait(content map[string]interface{}, compName s
 		return nil, false, fmt.Errorf("name of trait should be string instead of %s", reflect.TypeOf(content[TraitTypeField]))
 	}
 	mutatelog.Info("the trait refers to traitDefinition by name", "compName", compName, "trait name", traitType)
-	// Fetch the corresponding traitDefinition CR, the traitDefinition crd is cluster scoped
-	traitDefinition := &v1alpha2.TraitDefinition{}
-	if err := h.Client.Get(context.TODO(), types.NamespacedName{Name: traitType}, traitDefinition); err != nil {
+	// Fetch the corresponding traitDefinition CR, a namespaced one shadows the cluster scoped one
+	traitDefinition, err := util.GetTraitDefinition(ctx, h.Client, namespace, traitType)
+	if err != nil {
 		return nil, false, err
 	}
 	// fetch the CRDs definition
 	customResourceDefinition := &crdv1.CustomResourceDefinition{}
-	if err := h.Client.Get(context.TODO(), types.NamespacedName{Name: traitDefinition.Spec.Reference.Name}, customResourceDefinition); err != nil {
+	if err := h.Client.Get(ctx, types.NamespacedName{Name: traitDefinition.Spec.Reference.Name}, customResourceDefinition); err != nil {
+		return nil, false, err
+	}
+	version, err := util.GetCRDVersion(customResourceDefinition, traitDefinition.Spec.Reference.Version)
+	if err != nil {
 		return nil, false, err
 	}
 	// reconstruct the trait CR
@@ -135,7 +151,7 @@ func (h *MutatingHandler) mutateTrait(content map[string]interface{}, compName s
 	// find out the GVK from the CRD definition and set
 	apiVersion := metav1.GroupVersion{
 		Group:   customResourceDefinition.Spec.Group,
-		Version: customResourceDefinition.Spec.Versions[0].Name,
+		Version: version,
 	}.String()
 	trait.SetAPIVersion(apiVersion)
 	trait.SetKind(customResourceDefinition.Spec.Names.Kind)
@@ -0,0 +1,208 @@
+package applicationconfiguration
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"k8s.io/klog"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	appConfigController "github.com/crossplane/oam-kubernetes-runtime/pkg/controller/v1alpha2/applicationconfiguration"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
+)
+
+const (
+	errFmtPolicyViolated = "%s %q rule %q denies %s"
+
+	// wildcardIndex selects every element of an array in a policy field path.
+	wildcardIndex = "[*]"
+)
+
+// appPolicy is an AppPolicy or ClusterAppPolicy that applies to the validated
+// ApplicationConfiguration.
+type appPolicy struct {
+	kind string
+	name string
+	spec v1alpha2.AppPolicySpec
+}
+
+// policyTarget is an object a policy rule is evaluated against.
+type policyTarget struct {
+	// description of the target used in denial messages
+	description string
+	object      map[string]interface{}
+}
+
+// ValidateAppPoliciesFn validates the ApplicationConfiguration against the
+// rules of the AppPolicies of its namespace and of all ClusterAppPolicies.
+func ValidateAppPoliciesFn(_ context.Context, v ValidatingAppConfig) []error {
+	if len(v.policies) == 0 {
+		return nil
+	}
+	targets, err := policyTargets(v)
+	if err != nil {
+		return []error{err}
+	}
+	var allErrs []error
+	for _, p := range v.policies {
+		for _, rule := range p.spec.Rules {
+			for _, t := range targets[rule.Target] {
+				if !allHold(rule.Match, t.object) || len(rule.Deny) == 0 || !allHold(rule.Deny, t.object) {
+					continue
+				}
+				msg := fmt.Sprintf(errFmtPolicyViolated, p.kind, p.name, rule.Name, t.description)
+				if rule.Message != "" {
+					msg += ": " + rule.Message
+				}
+				klog.Info("policy violated ", " name: ", v.appConfig.Name, " errMsg: ", msg)
+				allErrs = append(allErrs, fmt.Errorf("%s", msg))
+			}
+		}
+	}
+	return allErrs
+}
+
+// policyTargets returns the objects each kind of policy rule is evaluated
+// against. Workloads are rendered with the parameter values of their
+// components, the way the ApplicationConfiguration controller renders them.
+func policyTargets(v ValidatingAppConfig) (map[v1alpha2.PolicyTarget][]policyTarget, error) {
+	acObj, err := util.Object2Map(v.appConfig)
+	if err != nil {
+		return nil, err
+	}
+	targets := map[v1alpha2.PolicyTarget][]policyTarget{
+		v1alpha2.PolicyTargetApplicationConfiguration: {{description: "the application configuration", object: acObj}},
+	}
+	for _, comp := range v.validatingComps {
+		accObj, err := util.Object2Map(comp.appConfigComponent)
+		if err != nil {
+			return nil, err
+		}
+		targets[v1alpha2.PolicyTargetComponent] = append(targets[v1alpha2.PolicyTargetComponent], policyTarget{
+			description: fmt.Sprintf("component %q", comp.compName),
+			object:      accObj,
+		})
+		workload, err := appConfigController.RenderWorkload(&comp.component, comp.appConfigComponent.ParameterValues)
+		if err != nil {
+			return nil, err
+		}
+		targets[v1alpha2.PolicyTargetWorkload] = append(targets[v1alpha2.PolicyTargetWorkload], policyTarget{
+			description: fmt.Sprintf("the workload of component %q", comp.compName),
+			object:      workload.Object,
+		})
+		for _, t := range comp.validatingTraits {
+			targets[v1alpha2.PolicyTargetTrait] = append(targets[v1alpha2.PolicyTargetTrait], policyTarget{
+				description: fmt.Sprintf("trait %q of component %q", t.traitContent.GetKind(), comp.compName),
+				object:      t.traitContent.Object,
+			})
+		}
+	}
+	return targets, nil
+}
+
+func allHold(conditions []v1alpha2.PolicyCondition, obj map[string]interface{}) bool {
+	for _, c := range conditions {
+		if !holds(c, fieldValues(obj, c.FieldPath)) {
+			return false
+		}
+	}
+	return true
+}
+
+// holds tells whether the condition holds for the values found at its field
+// path.
+func holds(c v1alpha2.PolicyCondition, values []interface{}) bool {
+	switch c.Operator {
+	case v1alpha2.PolicyOperatorExists:
+		return len(values) > 0
+	case v1alpha2.PolicyOperatorDoesNotExist:
+		return len(values) == 0
+	case v1alpha2.PolicyOperatorIn:
+		return anyIn(values, c.Values)
+	case v1alpha2.PolicyOperatorNotIn:
+		return !anyIn(values, c.Values)
+	case v1alpha2.PolicyOperatorGreaterThan, v1alpha2.PolicyOperatorLessThan:
+		if len(c.Values) != 1 {
+			return false
+		}
+		bound, err := strconv.ParseFloat(c.Values[0], 64)
+		if err != nil {
+			return false
+		}
+		for _, v := range values {
+			n, ok := toFloat(v)
+			if !ok {
+				continue
+			}
+			if (c.Operator == v1alpha2.PolicyOperatorGreaterThan && n > bound) ||
+				(c.Operator == v1alpha2.PolicyOperatorLessThan && n < bound) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func anyIn(values []interface{}, set []string) bool {
+	for _, v := range values {
+		s := fmt.Sprint(v)
+		for _, candidate := range set {
+			if s == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// fieldValues returns the values found at the field path of obj, expanding
+// every wildcard index to all elements of the array it indexes.
+func fieldValues(obj map[string]interface{}, path string) []interface{} {
+	i := strings.Index(path, wildcardIndex)
+	if i < 0 {
+		v, err := fieldpath.Pave(obj).GetValue(path)
+		if err != nil {
+			return nil
+		}
+		return []interface{}{v}
+	}
+	var list interface{} = obj
+	if prefix := path[:i]; prefix != "" {
+		v, err := fieldpath.Pave(obj).GetValue(prefix)
+		if err != nil {
+			return nil
+		}
+		list = v
+	}
+	items, ok := list.([]interface{})
+	if !ok {
+		return nil
+	}
+	rest := strings.TrimPrefix(path[i+len(wildcardIndex):], ".")
+	var values []interface{}
+	for _, item := range items {
+		if rest == "" {
+			values = append(values, item)
+			continue
+		}
+		if m, ok := item.(map[string]interface{}); ok {
+			values = append(values, fieldValues(m, rest)...)
+		}
+	}
+	return values
+}
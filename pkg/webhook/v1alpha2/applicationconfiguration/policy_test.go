@@ -0,0 +1,216 @@
+package applicationconfiguration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+)
+
+func TestFieldValues(t *testing.T) {
+	obj := map[string]interface{}{
+		"scopes": []interface{}{
+			map[string]interface{}{"scopeRef": map[string]interface{}{"kind": "HealthScope"}},
+			map[string]interface{}{"scopeRef": map[string]interface{}{"kind": "NetworkScope"}},
+			"not an object",
+		},
+		"spec": map[string]interface{}{"replicaCount": int64(3)},
+	}
+	cases := map[string]struct {
+		path string
+		want []interface{}
+	}{
+		"Plain":          {path: "spec.replicaCount", want: []interface{}{int64(3)}},
+		"Missing":        {path: "spec.replicas", want: nil},
+		"Wildcard":       {path: "scopes[*].scopeRef.kind", want: []interface{}{"HealthScope", "NetworkScope"}},
+		"WildcardMiss":   {path: "traits[*].kind", want: nil},
+		"WildcardNoList": {path: "spec[*]", want: nil},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, fieldValues(obj, tc.path))
+		})
+	}
+}
+
+func TestHolds(t *testing.T) {
+	cases := map[string]struct {
+		condition v1alpha2.PolicyCondition
+		values    []interface{}
+		want      bool
+	}{
+		"Exists":          {v1alpha2.PolicyCondition{Operator: v1alpha2.PolicyOperatorExists}, []interface{}{"a"}, true},
+		"NotExists":       {v1alpha2.PolicyCondition{Operator: v1alpha2.PolicyOperatorExists}, nil, false},
+		"DoesNotExist":    {v1alpha2.PolicyCondition{Operator: v1alpha2.PolicyOperatorDoesNotExist}, nil, true},
+		"In":              {v1alpha2.PolicyCondition{Operator: v1alpha2.PolicyOperatorIn, Values: []string{"a", "b"}}, []interface{}{"c", "b"}, true},
+		"NotIn":           {v1alpha2.PolicyCondition{Operator: v1alpha2.PolicyOperatorNotIn, Values: []string{"a"}}, []interface{}{"c"}, true},
+		"NotInNoValues":   {v1alpha2.PolicyCondition{Operator: v1alpha2.PolicyOperatorNotIn, Values: []string{"a"}}, nil, true},
+		"NumberIn":        {v1alpha2.PolicyCondition{Operator: v1alpha2.PolicyOperatorIn, Values: []string{"20"}}, []interface{}{float64(20)}, true},
+		"GreaterThan":     {v1alpha2.PolicyCondition{Operator: v1alpha2.PolicyOperatorGreaterThan, Values: []string{"20"}}, []interface{}{int64(21)}, true},
+		"NotGreaterThan":  {v1alpha2.PolicyCondition{Operator: v1alpha2.PolicyOperatorGreaterThan, Values: []string{"20"}}, []interface{}{int64(20)}, false},
+		"LessThan":        {v1alpha2.PolicyCondition{Operator: v1alpha2.PolicyOperatorLessThan, Values: []string{"1.5"}}, []interface{}{float64(1)}, true},
+		"NotANumber":      {v1alpha2.PolicyCondition{Operator: v1alpha2.PolicyOperatorLessThan, Values: []string{"1"}}, []interface{}{"0"}, false},
+		"BadBound":        {v1alpha2.PolicyCondition{Operator: v1alpha2.PolicyOperatorLessThan, Values: []string{"one"}}, []interface{}{int64(0)}, false},
+		"UnknownOperator": {v1alpha2.PolicyCondition{Operator: "Like"}, []interface{}{"a"}, false},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, holds(tc.condition, tc.values))
+		})
+	}
+}
+
+func TestValidateAppPoliciesFn(t *testing.T) {
+	trait := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "core.oam.dev/v1alpha2",
+		"kind":       "ManualScalerTrait",
+		"spec":       map[string]interface{}{"replicaCount": int64(30)},
+	}}
+	workload := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"spec":       map[string]interface{}{"replicas": int64(1)},
+	}}
+	raw, _ := json.Marshal(workload.Object)
+	component := v1alpha2.Component{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: v1alpha2.ComponentSpec{
+			Workload:   runtime.RawExtension{Raw: raw},
+			Parameters: []v1alpha2.ComponentParameter{{Name: "replicas", FieldPaths: []string{"spec.replicas"}}},
+		},
+	}
+	v := ValidatingAppConfig{
+		validatingComps: []ValidatingComponent{
+			{
+				compName: "web",
+				appConfigComponent: v1alpha2.ApplicationConfigurationComponent{
+					ComponentName: "web",
+					Scopes: []v1alpha2.ComponentScope{{ScopeReference: v1alpha1.TypedReference{
+						APIVersion: "core.oam.dev/v1alpha2", Kind: "HealthScope", Name: "health"}}},
+					ParameterValues: []v1alpha2.ComponentParameterValue{{Name: "replicas", Value: intstr.FromInt(10)}},
+				},
+				component:        component,
+				workloadContent:  workload,
+				validatingTraits: []ValidatingTrait{{traitContent: trait}},
+			},
+			{
+				compName:           "db",
+				appConfigComponent: v1alpha2.ApplicationConfigurationComponent{ComponentName: "db"},
+				component:          component,
+				workloadContent:    workload,
+			},
+		},
+	}
+	requireHealthScope := v1alpha2.AppPolicyRule{
+		Name:   "health-scope",
+		Target: v1alpha2.PolicyTargetComponent,
+		Deny: []v1alpha2.PolicyCondition{{
+			FieldPath: "scopes[*].scopeRef.kind",
+			Operator:  v1alpha2.PolicyOperatorNotIn,
+			Values:    []string{"HealthScope"},
+		}},
+		Message: "every component must be in a HealthScope",
+	}
+	maxReplicas := v1alpha2.AppPolicyRule{
+		Name:   "max-replicas",
+		Target: v1alpha2.PolicyTargetTrait,
+		Match: []v1alpha2.PolicyCondition{{
+			FieldPath: "kind",
+			Operator:  v1alpha2.PolicyOperatorIn,
+			Values:    []string{"ManualScalerTrait"},
+		}},
+		Deny: []v1alpha2.PolicyCondition{{
+			FieldPath: "spec.replicaCount",
+			Operator:  v1alpha2.PolicyOperatorGreaterThan,
+			Values:    []string{"20"},
+		}},
+	}
+	allowedWorkloads := v1alpha2.AppPolicyRule{
+		Name:   "allowed-workloads",
+		Target: v1alpha2.PolicyTargetWorkload,
+		Deny: []v1alpha2.PolicyCondition{{
+			FieldPath: "kind",
+			Operator:  v1alpha2.PolicyOperatorNotIn,
+			Values:    []string{"Deployment", "ContainerizedWorkload"},
+		}},
+	}
+	// the template of the component sets 1 replica, the web component sets 10
+	maxWorkloadReplicas := v1alpha2.AppPolicyRule{
+		Name:   "max-workload-replicas",
+		Target: v1alpha2.PolicyTargetWorkload,
+		Deny: []v1alpha2.PolicyCondition{{
+			FieldPath: "spec.replicas",
+			Operator:  v1alpha2.PolicyOperatorGreaterThan,
+			Values:    []string{"5"},
+		}},
+	}
+	unsupportedParam := v
+	unsupportedParam.validatingComps = []ValidatingComponent{v.validatingComps[0]}
+	unsupportedParam.validatingComps[0].appConfigComponent.ParameterValues = []v1alpha2.ComponentParameterValue{
+		{Name: "image", Value: intstr.FromString("nginx")}}
+
+	cases := map[string]struct {
+		v        *ValidatingAppConfig
+		policies []appPolicy
+		want     []error
+	}{
+		"NoPolicies": {},
+		"Violated": {
+			policies: []appPolicy{
+				{kind: v1alpha2.ClusterAppPolicyKind, name: "org", spec: v1alpha2.AppPolicySpec{
+					Rules: []v1alpha2.AppPolicyRule{requireHealthScope, allowedWorkloads}}},
+				{kind: v1alpha2.AppPolicyKind, name: "limits", spec: v1alpha2.AppPolicySpec{
+					Rules: []v1alpha2.AppPolicyRule{maxReplicas}}},
+			},
+			want: []error{
+				fmt.Errorf(errFmtPolicyViolated+": %s", v1alpha2.ClusterAppPolicyKind, "org", "health-scope",
+					`component "db"`, "every component must be in a HealthScope"),
+				fmt.Errorf(errFmtPolicyViolated, v1alpha2.AppPolicyKind, "limits", "max-replicas",
+					`trait "ManualScalerTrait" of component "web"`),
+			},
+		},
+		"ParameterValueViolated": {
+			policies: []appPolicy{
+				{kind: v1alpha2.AppPolicyKind, name: "limits", spec: v1alpha2.AppPolicySpec{
+					Rules: []v1alpha2.AppPolicyRule{maxWorkloadReplicas}}},
+			},
+			want: []error{
+				fmt.Errorf(errFmtPolicyViolated, v1alpha2.AppPolicyKind, "limits", "max-workload-replicas",
+					`the workload of component "web"`),
+			},
+		},
+		"UnsupportedParameter": {
+			v: &unsupportedParam,
+			policies: []appPolicy{
+				{kind: v1alpha2.AppPolicyKind, name: "limits", spec: v1alpha2.AppPolicySpec{
+					Rules: []v1alpha2.AppPolicyRule{maxWorkloadReplicas}}},
+			},
+			want: []error{errors.New(`cannot resolve parameter values for component "web": unsupported parameter "image"`)},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v := v
+			if tc.v != nil {
+				v = *tc.v
+			}
+			v.policies = tc.policies
+			got := ValidateAppPoliciesFn(ctx, v)
+			if len(got) != len(tc.want) {
+				t.Fatalf("ValidateAppPoliciesFn(...): want %v, got %v", tc.want, got)
+			}
+			for i := range got {
+				assert.EqualError(t, got[i], tc.want[i].Error())
+			}
+		})
+	}
+}
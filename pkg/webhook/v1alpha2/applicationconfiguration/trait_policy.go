@@ -0,0 +1,174 @@
+package applicationconfiguration
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
+)
+
+const (
+	errListTraitPolicies     = "cannot list trait policies"
+	errFmtUnmarshalPolicy    = "cannot unmarshal trait %d of trait policy %q"
+	errFmtPolicyTraitNoKind  = "trait %d of trait policy %q has no apiVersion or kind"
+	errFmtResolvePolicyTrait = "cannot resolve the definition of trait %d of trait policy %q"
+	errFmtMarshalPolicyTrait = "cannot marshal trait %d of trait policy %q"
+)
+
+// InjectTraits attaches the traits of the TraitPolicies of the namespace to
+// the components of the ApplicationConfiguration. A trait is only attached to
+// a component whose workload type matches the policy and that has no trait
+// of the same kind yet. Attached traits are labeled with the name of the
+// policy that attached them. Invalid traits of a policy are logged and
+// skipped, so that a broken policy doesn't block the ApplicationConfigurations
+// of the namespace.
+func (h *MutatingHandler) InjectTraits(ctx context.Context, namespace string, obj *v1alpha2.ApplicationConfiguration) error {
+	policies := &v1alpha2.TraitPolicyList{}
+	if err := h.Client.List(ctx, policies, client.InNamespace(namespace)); err != nil {
+		return errors.Wrap(err, errListTraitPolicies)
+	}
+	if len(policies.Items) == 0 {
+		return nil
+	}
+	// policies are applied in name order so that the first policy to attach a
+	// kind of trait wins deterministically
+	sort.Slice(policies.Items, func(i, j int) bool { return policies.Items[i].Name < policies.Items[j].Name })
+	traits := make([][]*unstructured.Unstructured, len(policies.Items))
+	for i, p := range policies.Items {
+		traits[i] = h.policyTraits(ctx, namespace, p)
+	}
+
+	for compIdx := range obj.Spec.Components {
+		acc := &obj.Spec.Components[compIdx]
+		workloadType, err := h.workloadType(ctx, *acc, namespace)
+		if err != nil {
+			return err
+		}
+		present, err := traitKinds(acc)
+		if err != nil {
+			return err
+		}
+		for pIdx, p := range policies.Items {
+			if !matchesWorkloadType(p.Spec.WorkloadTypes, workloadType) {
+				continue
+			}
+			for i, ct := range p.Spec.Traits {
+				if traits[pIdx][i] == nil {
+					continue
+				}
+				trait := traits[pIdx][i].DeepCopy()
+				gk := trait.GroupVersionKind().GroupKind()
+				if present[gk] {
+					continue
+				}
+				trait.SetLabels(util.MergeMapOverrideWithDst(trait.GetLabels(),
+					map[string]string{oam.LabelInjectedByTraitPolicy: p.Name}))
+				raw, err := json.Marshal(trait.Object)
+				if err != nil {
+					return errors.Wrapf(err, errFmtMarshalPolicyTrait, i, p.Name)
+				}
+				injected := *ct.DeepCopy()
+				injected.Trait = runtime.RawExtension{Raw: raw}
+				acc.Traits = append(acc.Traits, injected)
+				present[gk] = true
+				mutatelog.Info("inject trait", "policy", p.Name, "component", util.ComponentNameOf(*acc), "trait kind", gk.String())
+			}
+		}
+	}
+	return nil
+}
+
+// policyTraits returns the traits of the policy, in order. Traits that refer
+// to their definition by name are resolved the same way Mutate does. Traits
+// that are invalid are logged and returned as nil.
+func (h *MutatingHandler) policyTraits(ctx context.Context, namespace string, p v1alpha2.TraitPolicy) []*unstructured.Unstructured {
+	traits := make([]*unstructured.Unstructured, len(p.Spec.Traits))
+	for i, ct := range p.Spec.Traits {
+		trait, err := h.policyTrait(ctx, namespace, p.Name, i, ct)
+		if err != nil {
+			mutatelog.Error(err, "skip invalid trait of trait policy", "policy", p.Name, "trait", i)
+			continue
+		}
+		traits[i] = trait
+	}
+	return traits
+}
+
+func (h *MutatingHandler) policyTrait(ctx context.Context, namespace, policy string, i int,
+	ct v1alpha2.ComponentTrait) (*unstructured.Unstructured, error) {
+	var content map[string]interface{}
+	if err := json.Unmarshal(ct.Trait.Raw, &content); err != nil {
+		return nil, errors.Wrapf(err, errFmtUnmarshalPolicy, i, policy)
+	}
+	raw, mutated, err := h.mutateTrait(ctx, namespace, content, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtResolvePolicyTrait, i, policy)
+	}
+	if !mutated {
+		raw = ct.Trait.Raw
+	}
+	trait := &unstructured.Unstructured{}
+	if err := json.Unmarshal(raw, &trait.Object); err != nil {
+		return nil, errors.Wrapf(err, errFmtUnmarshalPolicy, i, policy)
+	}
+	if trait.GetKind() == "" {
+		return nil, errors.Errorf(errFmtPolicyTraitNoKind, i, policy)
+	}
+	return trait, nil
+}
+
+// workloadType returns the workload type label of the workload of the
+// component. A component that does not exist yet has no workload type.
+func (h *MutatingHandler) workloadType(ctx context.Context, acc v1alpha2.ApplicationConfigurationComponent,
+	namespace string) (string, error) {
+	comp, _, err := util.GetComponent(ctx, h.Client, acc, namespace)
+	if kerrors.IsNotFound(errors.Cause(err)) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, errFmtGetComponent, util.ComponentNameOf(acc))
+	}
+	var workload unstructured.Unstructured
+	if err := json.Unmarshal(comp.Spec.Workload.Raw, &workload.Object); err != nil {
+		// invalid workloads are reported by the validating webhook
+		return "", nil
+	}
+	return workload.GetLabels()[oam.WorkloadTypeLabel], nil
+}
+
+// traitKinds returns the kinds of the traits the component already has.
+// Traits that still refer to their definition by name have been converted by
+// Mutate at this point.
+func traitKinds(acc *v1alpha2.ApplicationConfigurationComponent) (map[schema.GroupKind]bool, error) {
+	kinds := make(map[schema.GroupKind]bool, len(acc.Traits))
+	for _, ct := range acc.Traits {
+		trait := &unstructured.Unstructured{}
+		if err := json.Unmarshal(ct.Trait.Raw, &trait.Object); err != nil {
+			return nil, errors.Wrapf(err, errFmtUnmarshalTrait, util.ComponentNameOf(*acc))
+		}
+		kinds[trait.GroupVersionKind().GroupKind()] = true
+	}
+	return kinds, nil
+}
+
+func matchesWorkloadType(types []string, workloadType string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if t == workloadType {
+			return true
+		}
+	}
+	return false
+}
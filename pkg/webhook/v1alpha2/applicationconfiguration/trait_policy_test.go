@@ -0,0 +1,116 @@
+package applicationconfiguration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/stretchr/testify/assert"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+)
+
+func TestInjectTraits(t *testing.T) {
+	scaler := `{"apiVersion":"core.oam.dev/v1alpha2","kind":"ManualScalerTrait","spec":{"replicaCount":1}}`
+	route := `{"apiVersion":"standard.oam.dev/v1alpha1","kind":"Route","spec":{"host":"example.com"}}`
+	scalerInjected := `{"apiVersion":"core.oam.dev/v1alpha2","kind":"ManualScalerTrait",` +
+		`"metadata":{"labels":{"trait.oam.dev/injected-by":"defaults"}},"spec":{"replicaCount":1}}`
+	routeInjected := `{"apiVersion":"standard.oam.dev/v1alpha1","kind":"Route",` +
+		`"metadata":{"labels":{"trait.oam.dev/injected-by":"web"}},"spec":{"host":"example.com"}}`
+	ownScaler := `{"apiVersion":"core.oam.dev/v1alpha2","kind":"ManualScalerTrait","spec":{"replicaCount":3}}`
+	autoscaler := `{"name":"autoscaler","properties":{"max":3}}`
+	autoscalerInjected := `{"apiVersion":"example.com/v1","kind":"Autoscaler","metadata":{"labels":` +
+		`{"trait.oam.dev/injected-by":"autoscale","trait.oam.dev/type":"autoscaler"}},"spec":{"max":3}}`
+
+	policies := []v1alpha2.TraitPolicy{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "autoscale"},
+			Spec: v1alpha2.TraitPolicySpec{
+				WorkloadTypes: []string{"webservice"},
+				Traits: []v1alpha2.ComponentTrait{
+					{Trait: runtime.RawExtension{Raw: []byte(`{"spec":{}}`)}},
+					{Trait: runtime.RawExtension{Raw: []byte(`{"name":"unknown"}`)}},
+					{Trait: runtime.RawExtension{Raw: []byte(`[]`)}},
+					{Trait: runtime.RawExtension{Raw: []byte(autoscaler)}},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "web"},
+			Spec: v1alpha2.TraitPolicySpec{
+				WorkloadTypes: []string{"webservice"},
+				Traits:        []v1alpha2.ComponentTrait{{Trait: runtime.RawExtension{Raw: []byte(route)}}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "defaults"},
+			Spec: v1alpha2.TraitPolicySpec{
+				Traits: []v1alpha2.ComponentTrait{{Trait: runtime.RawExtension{Raw: []byte(scaler)}}},
+			},
+		},
+	}
+	components := map[string]string{
+		"web":    `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"labels":{"workload.oam.dev/type":"webservice"}}}`,
+		"worker": `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"labels":{"workload.oam.dev/type":"worker"}}}`,
+	}
+	c := &test.MockClient{
+		MockList: func(_ context.Context, obj runtime.Object, opts ...client.ListOption) error {
+			lo := &client.ListOptions{}
+			lo.ApplyOptions(opts)
+			assert.Equal(t, "ns", lo.Namespace)
+			obj.(*v1alpha2.TraitPolicyList).Items = append([]v1alpha2.TraitPolicy{}, policies...)
+			return nil
+		},
+		MockGet: func(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+			switch o := obj.(type) {
+			case *v1alpha2.Component:
+				w, ok := components[key.Name]
+				if !ok {
+					return kerrors.NewNotFound(schema.GroupResource{Group: v1alpha2.Group, Resource: "components"}, key.Name)
+				}
+				o.Spec.Workload = runtime.RawExtension{Raw: []byte(w)}
+				return nil
+			case *v1alpha2.TraitDefinition:
+				if key.Name != "autoscaler" {
+					return kerrors.NewNotFound(schema.GroupResource{Group: v1alpha2.Group, Resource: "traitdefinitions"}, key.Name)
+				}
+				o.Spec.Reference = v1alpha2.DefinitionReference{Name: "autoscalers.example.com"}
+				return nil
+			case *crdv1.CustomResourceDefinition:
+				o.Spec.Group = "example.com"
+				o.Spec.Names.Kind = "Autoscaler"
+				o.Spec.Versions = []crdv1.CustomResourceDefinitionVersion{{Name: "v1", Served: true, Storage: true}}
+				return nil
+			default:
+				return kerrors.NewNotFound(schema.GroupResource{Group: v1alpha2.Group}, key.Name)
+			}
+		},
+	}
+
+	obj := &v1alpha2.ApplicationConfiguration{Spec: v1alpha2.ApplicationConfigurationSpec{
+		Components: []v1alpha2.ApplicationConfigurationComponent{
+			{ComponentName: "web"},
+			{ComponentName: "worker", Traits: []v1alpha2.ComponentTrait{{Trait: runtime.RawExtension{Raw: []byte(ownScaler)}}}},
+			{ComponentName: "missing"},
+		},
+	}}
+	h := &MutatingHandler{Client: c}
+	assert.NoError(t, h.InjectTraits(context.Background(), "ns", obj))
+
+	traits := func(i int) []string {
+		var got []string
+		for _, ct := range obj.Spec.Components[i].Traits {
+			got = append(got, string(ct.Trait.Raw))
+		}
+		return got
+	}
+	assert.Equal(t, []string{autoscalerInjected, scalerInjected, routeInjected}, traits(0))
+	assert.Equal(t, []string{ownScaler}, traits(1))
+	assert.Equal(t, []string{scalerInjected}, traits(2))
+}
@@ -7,9 +7,15 @@ import (
 	"strings"
 
 	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
 	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/discoverymapper"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/webhook/audit"
 
+	"github.com/pkg/errors"
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -28,6 +34,16 @@ const (
 
 	errFmtUnappliableTrait = "the trait %q cannot apply to workload %q of component %q (appliable: %q)"
 
+	errFmtUnappliableTraitCharacteristics = "the trait %q cannot apply to workload %q of component %q (appliable: %q): workload has %s"
+
+	errFmtInvalidCharacteristics = "invalid appliesToWorkloadsWith of trait %q"
+
+	errFmtConflictingTraits = "component %q has conflicting traits: %s"
+
+	errFmtScopeOverlap = "component %q cannot be in both scope %q and scope %q: ScopeDefinition %q does not allow component overlap"
+
+	errFmtScopeOverlapAppConfig = "component %q cannot join scope %q: it is in scope %q through ApplicationConfiguration %q and ScopeDefinition %q does not allow component overlap"
+
 	// WorkloadNamePath indicates field path of workload name
 	WorkloadNamePath = "metadata.name"
 )
@@ -56,6 +72,9 @@ type ValidatingHandler struct {
 	Decoder *admission.Decoder
 
 	Validators []AppConfigValidator
+
+	// Auditor records admitted changes, nil disables auditing
+	Auditor *audit.Auditor
 }
 
 var _ admission.Handler = &ValidatingHandler{}
@@ -73,7 +92,6 @@ func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) a
 				return admission.Errored(http.StatusBadRequest, err)
 			}
 		} else {
-			// TODO(wonderflow): we can audit delete or something else here.
 			klog.Info("deleting Application Configuration", req.Name)
 		}
 	default:
@@ -94,6 +112,7 @@ func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) a
 			}
 		}
 	}
+	h.Auditor.Audit(ctx, req)
 	return admission.ValidationResponse(true, "")
 }
 
@@ -183,7 +202,8 @@ func ValidateTraitAppliableToWorkloadFn(_ context.Context, v ValidatingAppConfig
 			klog.Info("validate trait is appliable to workload: ",
 				fmt.Sprintf("trait %q is allowed to apply to %s",
 					t.traitDefinition.GetName(), t.traitDefinition.Spec.AppliesToWorkloads))
-			if len(t.traitDefinition.Spec.AppliesToWorkloads) == 0 {
+			with := t.traitDefinition.Spec.AppliesToWorkloadsWith
+			if len(t.traitDefinition.Spec.AppliesToWorkloads) == 0 && with == nil {
 				// AppliesToWorkloads is empty, the trait can be applied to ANY workload
 				continue
 			}
@@ -200,10 +220,133 @@ func ValidateTraitAppliableToWorkloadFn(_ context.Context, v ValidatingAppConfig
 					continue ValidateApplyTo
 				}
 			}
-			allErrs = append(allErrs, fmt.Errorf(errFmtUnappliableTrait,
+			if with == nil {
+				allErrs = append(allErrs, fmt.Errorf(errFmtUnappliableTrait,
+					t.traitDefinition.GetName(),
+					c.workloadDefinition.GetName(),
+					c.compName, t.traitDefinition.Spec.AppliesToWorkloads))
+				continue
+			}
+			missing, err := missingCharacteristics(c.workloadDefinition, *with)
+			if err != nil {
+				allErrs = append(allErrs, errors.Wrapf(err, errFmtInvalidCharacteristics, t.traitDefinition.GetName()))
+				continue
+			}
+			if len(missing) == 0 {
+				continue
+			}
+			allErrs = append(allErrs, fmt.Errorf(errFmtUnappliableTraitCharacteristics,
 				t.traitDefinition.GetName(),
 				c.workloadDefinition.GetName(),
-				c.compName, t.traitDefinition.Spec.AppliesToWorkloads))
+				c.compName, t.traitDefinition.Spec.AppliesToWorkloads,
+				strings.Join(missing, ", ")))
+		}
+	}
+	return allErrs
+}
+
+// missingCharacteristics returns the characteristics the workloads of the
+// WorkloadDefinition lack.
+func missingCharacteristics(wd v1alpha2.WorkloadDefinition, with v1alpha2.WorkloadCharacteristics) ([]string, error) {
+	var missing []string
+	if with.PodSpecable && wd.Spec.PodSpecPath == "" && wd.GetLabels()[oam.LabelPodSpecable] != "true" {
+		missing = append(missing, "not podspecable")
+	}
+	for _, kind := range with.ChildResourceKinds {
+		if !hasChildResourceKind(wd, kind) {
+			missing = append(missing, fmt.Sprintf("no child resources of kind %q", kind))
+		}
+	}
+	if with.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(with.Selector)
+		if err != nil {
+			return nil, err
+		}
+		if !selector.Matches(labels.Set(wd.GetLabels())) {
+			missing = append(missing, fmt.Sprintf("labels not matching %q", selector.String()))
+		}
+	}
+	return missing, nil
+}
+
+// hasChildResourceKind tells whether the WorkloadDefinition declares child
+// resources of the kind, optionally qualified by its group as in
+// Deployment.apps.
+func hasChildResourceKind(wd v1alpha2.WorkloadDefinition, kind string) bool {
+	gk := schema.ParseGroupKind(kind)
+	for _, child := range wd.Spec.ChildResourceKinds {
+		if child.Kind != gk.Kind {
+			continue
+		}
+		if gk.Group == "" {
+			return true
+		}
+		gv, err := schema.ParseGroupVersion(child.APIVersion)
+		if err == nil && gv.Group == gk.Group {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTraitConflictsFn validates that no two traits of a component conflict
+// with each other according to the ConflictsWith of their TraitDefinitions.
+func ValidateTraitConflictsFn(_ context.Context, v ValidatingAppConfig) []error {
+	klog.Info("validate trait conflicts", "name", v.appConfig.Name)
+	var allErrs []error
+	for _, c := range v.validatingComps {
+		for i := range c.validatingTraits {
+			for j := 0; j < i; j++ {
+				msg := util.TraitsConflict(&c.validatingTraits[j].traitDefinition, &c.validatingTraits[i].traitDefinition)
+				if msg == "" {
+					continue
+				}
+				allErrs = append(allErrs, fmt.Errorf(errFmtConflictingTraits, c.compName, msg))
+			}
+		}
+	}
+	return allErrs
+}
+
+// ValidateComponentScopeOverlapFn validates that a component is not put into more than one scope
+// of a kind whose ScopeDefinition does not allow component overlap, within this ApplicationConfiguration
+// and across the other ApplicationConfigurations in the namespace.
+func ValidateComponentScopeOverlapFn(_ context.Context, v ValidatingAppConfig) []error {
+	klog.Info("validate component scope overlap", "name", v.appConfig.Name)
+	var allErrs []error
+	for _, c := range v.validatingComps {
+		compName := util.ComponentNameOf(c.appConfigComponent)
+		// scope definition name => name of the first scope of that kind
+		joined := make(map[string]string)
+		for _, s := range c.validatingScopes {
+			if s.scopeDefinition.Spec.AllowComponentOverlap {
+				continue
+			}
+			ref := s.componentScope.ScopeReference
+			defName := s.scopeDefinition.GetName()
+			if existing, ok := joined[defName]; ok {
+				if existing != ref.Name {
+					allErrs = append(allErrs, fmt.Errorf(errFmtScopeOverlap, c.compName, existing, ref.Name, defName))
+				}
+				continue
+			}
+			joined[defName] = ref.Name
+
+			gk := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind).GroupKind()
+			for _, ac := range v.namespaceAppConfigs {
+				for _, acc := range ac.Spec.Components {
+					if util.ComponentNameOf(acc) != compName {
+						continue
+					}
+					for _, os := range acc.Scopes {
+						oref := os.ScopeReference
+						if oref.Name != ref.Name && schema.FromAPIVersionAndKind(oref.APIVersion, oref.Kind).GroupKind() == gk {
+							allErrs = append(allErrs, fmt.Errorf(errFmtScopeOverlapAppConfig,
+								c.compName, ref.Name, oref.Name, ac.Name, defName))
+						}
+					}
+				}
+			}
 		}
 	}
 	return allErrs
@@ -226,19 +369,23 @@ func (h *ValidatingHandler) InjectDecoder(d *admission.Decoder) error {
 }
 
 // RegisterValidatingHandler will register application configuration validation to webhook
-func RegisterValidatingHandler(mgr manager.Manager) error {
+func RegisterValidatingHandler(mgr manager.Manager, auditor *audit.Auditor) error {
 	server := mgr.GetWebhookServer()
 	mapper, err := discoverymapper.New(mgr.GetConfig())
 	if err != nil {
 		return err
 	}
 	server.Register("/validating-core-oam-dev-v1alpha2-applicationconfigurations", &webhook.Admission{Handler: &ValidatingHandler{
-		Mapper: mapper,
+		Mapper:  mapper,
+		Auditor: auditor,
 		Validators: []AppConfigValidator{
 			AppConfigValidateFunc(ValidateTraitObjectFn),
 			AppConfigValidateFunc(ValidateRevisionNameFn),
 			AppConfigValidateFunc(ValidateWorkloadNameForVersioningFn),
 			AppConfigValidateFunc(ValidateTraitAppliableToWorkloadFn),
+			AppConfigValidateFunc(ValidateTraitConflictsFn),
+			AppConfigValidateFunc(ValidateComponentScopeOverlapFn),
+			AppConfigValidateFunc(ValidateAppPoliciesFn),
 			// TODO(wonderflow): Add more validation logic here.
 		},
 	}})
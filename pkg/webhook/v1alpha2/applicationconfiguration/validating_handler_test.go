@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -458,6 +460,69 @@ func TestValidateTraitAppliableToWorkloadFn(t *testing.T) {
 				"TestTrait", "bar", "example-comp",
 				[]string{"foo"})},
 		},
+		{
+			caseName: "validate succeed: workload has the characteristics",
+			validatingAppConfig: ValidatingAppConfig{
+				validatingComps: []ValidatingComponent{
+					{
+						compName: "example-comp",
+						workloadDefinition: v1alpha2.WorkloadDefinition{
+							ObjectMeta: v1.ObjectMeta{
+								Name:   "webservice",
+								Labels: map[string]string{oam.LabelPodSpecable: "true", "tier": "web"},
+							},
+							Spec: v1alpha2.WorkloadDefinitionSpec{
+								ChildResourceKinds: []v1alpha2.ChildResourceKind{{APIVersion: "apps/v1", Kind: "Deployment"}},
+							},
+						},
+						validatingTraits: []ValidatingTrait{
+							{traitDefinition: v1alpha2.TraitDefinition{
+								ObjectMeta: v1.ObjectMeta{Name: "sidecar"},
+								Spec: v1alpha2.TraitDefinitionSpec{
+									AppliesToWorkloads: []string{"foo"},
+									AppliesToWorkloadsWith: &v1alpha2.WorkloadCharacteristics{
+										PodSpecable:        true,
+										ChildResourceKinds: []string{"Deployment.apps"},
+										Selector:           &v1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}},
+									},
+								},
+							}},
+						},
+					},
+				},
+			},
+			want: nil,
+		},
+		{
+			caseName: "validate fail: workload lacks the characteristics",
+			validatingAppConfig: ValidatingAppConfig{
+				validatingComps: []ValidatingComponent{
+					{
+						compName: "example-comp",
+						workloadDefinition: v1alpha2.WorkloadDefinition{
+							ObjectMeta: v1.ObjectMeta{Name: "cronjob"},
+							Spec: v1alpha2.WorkloadDefinitionSpec{
+								ChildResourceKinds: []v1alpha2.ChildResourceKind{{APIVersion: "batch/v1", Kind: "Job"}},
+							},
+						},
+						validatingTraits: []ValidatingTrait{
+							{traitDefinition: v1alpha2.TraitDefinition{
+								ObjectMeta: v1.ObjectMeta{Name: "sidecar"},
+								Spec: v1alpha2.TraitDefinitionSpec{
+									AppliesToWorkloadsWith: &v1alpha2.WorkloadCharacteristics{
+										PodSpecable:        true,
+										ChildResourceKinds: []string{"Job.apps"},
+									},
+								},
+							}},
+						},
+					},
+				},
+			},
+			want: []error{fmt.Errorf(errFmtUnappliableTraitCharacteristics,
+				"sidecar", "cronjob", "example-comp", []string(nil),
+				`not podspecable, no child resources of kind "Job.apps"`)},
+		},
 	}
 
 	for _, tc := range tests {
@@ -465,3 +530,174 @@ func TestValidateTraitAppliableToWorkloadFn(t *testing.T) {
 		assert.Equal(t, tc.want, result, fmt.Sprintf("Test case: %q", tc.caseName))
 	}
 }
+
+func TestValidateTraitConflictsFn(t *testing.T) {
+	scaler := v1alpha2.TraitDefinition{
+		ObjectMeta: v1.ObjectMeta{Name: "manualscalertraits.core.oam.dev"},
+		Spec: v1alpha2.TraitDefinitionSpec{
+			ConflictsWith: []v1alpha2.TraitConflict{{WorkloadFieldPath: "spec.replicas"}},
+		},
+	}
+	autoscaler := v1alpha2.TraitDefinition{
+		ObjectMeta: v1.ObjectMeta{Name: "autoscalers.standard.oam.dev"},
+		Spec: v1alpha2.TraitDefinitionSpec{
+			ConflictsWith: []v1alpha2.TraitConflict{{WorkloadFieldPath: "spec.replicas"}},
+		},
+	}
+	route := v1alpha2.TraitDefinition{ObjectMeta: v1.ObjectMeta{Name: "routes.standard.oam.dev"}}
+
+	tests := []struct {
+		caseName            string
+		validatingAppConfig ValidatingAppConfig
+		want                []error
+	}{
+		{
+			caseName: "validate succeed: traits do not conflict",
+			validatingAppConfig: ValidatingAppConfig{
+				validatingComps: []ValidatingComponent{
+					{compName: "example-comp", validatingTraits: []ValidatingTrait{
+						{traitDefinition: scaler}, {traitDefinition: route}}},
+					{compName: "other-comp", validatingTraits: []ValidatingTrait{{traitDefinition: autoscaler}}},
+				},
+			},
+		},
+		{
+			caseName: "validate fail: traits manage the same workload field",
+			validatingAppConfig: ValidatingAppConfig{
+				validatingComps: []ValidatingComponent{
+					{compName: "example-comp", validatingTraits: []ValidatingTrait{
+						{traitDefinition: scaler}, {traitDefinition: route}, {traitDefinition: autoscaler}}},
+				},
+			},
+			want: []error{fmt.Errorf(errFmtConflictingTraits, "example-comp",
+				`traits "manualscalertraits.core.oam.dev" and "autoscalers.standard.oam.dev" both manage workload field "spec.replicas"`)},
+		},
+	}
+
+	for _, tc := range tests {
+		result := ValidateTraitConflictsFn(ctx, tc.validatingAppConfig)
+		assert.Equal(t, tc.want, result, fmt.Sprintf("Test case: %q", tc.caseName))
+	}
+}
+
+func TestValidateComponentScopeOverlapFn(t *testing.T) {
+	healthScope := func(name string) v1alpha2.ComponentScope {
+		return v1alpha2.ComponentScope{ScopeReference: v1alpha1.TypedReference{
+			APIVersion: "core.oam.dev/v1alpha2",
+			Kind:       "HealthScope",
+			Name:       name,
+		}}
+	}
+	scopeDef := func(allowOverlap bool) v1alpha2.ScopeDefinition {
+		return v1alpha2.ScopeDefinition{
+			ObjectMeta: v1.ObjectMeta{Name: "healthscopes.core.oam.dev"},
+			Spec:       v1alpha2.ScopeDefinitionSpec{AllowComponentOverlap: allowOverlap},
+		}
+	}
+
+	tests := []struct {
+		caseName            string
+		validatingAppConfig ValidatingAppConfig
+		want                []error
+	}{
+		{
+			caseName: "validate succeed: overlap allowed",
+			validatingAppConfig: ValidatingAppConfig{
+				validatingComps: []ValidatingComponent{
+					{
+						compName:           "example-comp",
+						appConfigComponent: v1alpha2.ApplicationConfigurationComponent{ComponentName: "example-comp"},
+						validatingScopes: []ValidatingScope{
+							{componentScope: healthScope("scope-a"), scopeDefinition: scopeDef(true)},
+							{componentScope: healthScope("scope-b"), scopeDefinition: scopeDef(true)},
+						},
+					},
+				},
+			},
+			want: nil,
+		},
+		{
+			caseName: "validate fail: two scopes of a non-overlapping kind in one appConfig",
+			validatingAppConfig: ValidatingAppConfig{
+				validatingComps: []ValidatingComponent{
+					{
+						compName:           "example-comp",
+						appConfigComponent: v1alpha2.ApplicationConfigurationComponent{ComponentName: "example-comp"},
+						validatingScopes: []ValidatingScope{
+							{componentScope: healthScope("scope-a"), scopeDefinition: scopeDef(false)},
+							{componentScope: healthScope("scope-b"), scopeDefinition: scopeDef(false)},
+						},
+					},
+				},
+			},
+			want: []error{fmt.Errorf(errFmtScopeOverlap,
+				"example-comp", "scope-a", "scope-b", "healthscopes.core.oam.dev")},
+		},
+		{
+			caseName: "validate fail: component in another scope of the kind through another appConfig",
+			validatingAppConfig: ValidatingAppConfig{
+				validatingComps: []ValidatingComponent{
+					{
+						compName:           "example-comp",
+						appConfigComponent: v1alpha2.ApplicationConfigurationComponent{ComponentName: "example-comp"},
+						validatingScopes: []ValidatingScope{
+							{componentScope: healthScope("scope-a"), scopeDefinition: scopeDef(false)},
+						},
+					},
+				},
+				namespaceAppConfigs: []v1alpha2.ApplicationConfiguration{
+					{
+						ObjectMeta: v1.ObjectMeta{Name: "other-app"},
+						Spec: v1alpha2.ApplicationConfigurationSpec{
+							Components: []v1alpha2.ApplicationConfigurationComponent{
+								{
+									RevisionName: "example-comp-v2",
+									Scopes:       []v1alpha2.ComponentScope{healthScope("scope-b")},
+								},
+								{
+									ComponentName: "another-comp",
+									Scopes:        []v1alpha2.ComponentScope{healthScope("scope-c")},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: []error{fmt.Errorf(errFmtScopeOverlapAppConfig,
+				"example-comp", "scope-a", "scope-b", "other-app", "healthscopes.core.oam.dev")},
+		},
+		{
+			caseName: "validate succeed: same scope in another appConfig",
+			validatingAppConfig: ValidatingAppConfig{
+				validatingComps: []ValidatingComponent{
+					{
+						compName:           "example-comp",
+						appConfigComponent: v1alpha2.ApplicationConfigurationComponent{ComponentName: "example-comp"},
+						validatingScopes: []ValidatingScope{
+							{componentScope: healthScope("scope-a"), scopeDefinition: scopeDef(false)},
+						},
+					},
+				},
+				namespaceAppConfigs: []v1alpha2.ApplicationConfiguration{
+					{
+						ObjectMeta: v1.ObjectMeta{Name: "other-app"},
+						Spec: v1alpha2.ApplicationConfigurationSpec{
+							Components: []v1alpha2.ApplicationConfigurationComponent{
+								{
+									ComponentName: "example-comp",
+									Scopes:        []v1alpha2.ComponentScope{healthScope("scope-a")},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		result := ValidateComponentScopeOverlapFn(ctx, tc.validatingAppConfig)
+		assert.Equal(t, tc.want, result, fmt.Sprintf("Test case: %q", tc.caseName))
+	}
+}
@@ -11,10 +11,13 @@ import (
 
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	utilpointer "k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -110,6 +113,55 @@ var _ = Describe("Component Admission controller Test", func() {
 			Expect(resp.Allowed).Should(BeTrue())
 		})
 
+		It("Test author annotation", func() {
+			component.Spec.Workload = runtime.RawExtension{Raw: util.JSONMarshal(baseWorkload)}
+			changed := component.DeepCopy()
+			changed.Spec.Parameters = nil
+
+			tests := map[string]struct {
+				operation  admissionv1beta1.Operation
+				old        *v1alpha2.Component
+				wantAuthor bool
+			}{
+				"create": {
+					operation:  admissionv1beta1.Create,
+					wantAuthor: true,
+				},
+				"update the spec": {
+					operation:  admissionv1beta1.Update,
+					old:        changed,
+					wantAuthor: true,
+				},
+				"update only the metadata": {
+					operation: admissionv1beta1.Update,
+					old:       component.DeepCopy(),
+				},
+			}
+			for testCase, test := range tests {
+				By(fmt.Sprintf("start test : %s", testCase))
+				req := admission.Request{
+					AdmissionRequest: admissionv1beta1.AdmissionRequest{
+						Operation: test.operation,
+						Resource:  reqResource,
+						Object:    runtime.RawExtension{Raw: util.JSONMarshal(component)},
+						UserInfo:  authenticationv1.UserInfo{Username: "alice"},
+					},
+				}
+				if test.old != nil {
+					req.OldObject = runtime.RawExtension{Raw: util.JSONMarshal(test.old)}
+				}
+				resp := handler.Handle(context.TODO(), req)
+				Expect(resp.Allowed).Should(BeTrue())
+				if !test.wantAuthor {
+					Expect(resp.Patches).Should(BeEmpty())
+					continue
+				}
+				Expect(resp.Patches).Should(HaveLen(1))
+				Expect(resp.Patches[0].Path).Should(Equal("/metadata/annotations"))
+				Expect(resp.Patches[0].Value).Should(Equal(map[string]interface{}{oam.AnnotationAuthor: "alice"}))
+			}
+		})
+
 		It("Test mutate function", func() {
 			// the workload that uses type to refer to the workloadDefinition
 			workloadWithType := unstructured.Unstructured{}
@@ -146,6 +198,8 @@ var _ = Describe("Component Admission controller Test", func() {
 					client: &test.MockClient{
 						MockGet: func(ctx context.Context, key types.NamespacedName, obj runtime.Object) error {
 							switch obj.(type) {
+							case *v1alpha2.NamespacedWorkloadDefinition:
+								return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
 							case *v1alpha2.WorkloadDefinition:
 								return fmt.Errorf("does not exist")
 							}
@@ -159,6 +213,8 @@ var _ = Describe("Component Admission controller Test", func() {
 					client: &test.MockClient{
 						MockGet: func(ctx context.Context, key types.NamespacedName, obj runtime.Object) error {
 							switch o := obj.(type) {
+							case *v1alpha2.NamespacedWorkloadDefinition:
+								return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
 							case *v1alpha2.WorkloadDefinition:
 								Expect(key.Name).Should(BeEquivalentTo(typeContent[TypeField]))
 								*o = workloadDef
@@ -274,4 +330,73 @@ var _ = Describe("Component Admission controller Test", func() {
 		Expect(resp.Allowed).Should(BeFalse())
 	})
 
+	It("Test validating handler on deletion", func() {
+		appConfigs := []v1alpha2.ApplicationConfiguration{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "by-name", Namespace: namespace},
+				Spec: v1alpha2.ApplicationConfigurationSpec{Components: []v1alpha2.ApplicationConfigurationComponent{
+					{ComponentName: componentName},
+				}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "by-revision", Namespace: namespace},
+				Spec: v1alpha2.ApplicationConfigurationSpec{Components: []v1alpha2.ApplicationConfigurationComponent{
+					{RevisionName: componentName + "-v2"},
+				}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: namespace},
+				Spec: v1alpha2.ApplicationConfigurationSpec{Components: []v1alpha2.ApplicationConfigurationComponent{
+					{ComponentName: componentName + "-other"},
+					{RevisionName: componentName + "-other-v1"},
+				}},
+			},
+		}
+		tests := map[string]struct {
+			appConfigs  []v1alpha2.ApplicationConfiguration
+			annotations map[string]string
+			pass        bool
+		}{
+			"not in use": {
+				appConfigs: appConfigs[2:],
+				pass:       true,
+			},
+			"in use": {
+				appConfigs: appConfigs,
+				pass:       false,
+			},
+			"in use but forced": {
+				appConfigs:  appConfigs,
+				annotations: map[string]string{oam.AnnotationForceDelete: "true"},
+				pass:        true,
+			},
+		}
+		for testCase, tc := range tests {
+			By(fmt.Sprintf("start test : %s", testCase))
+			appConfigs := tc.appConfigs
+			handler := &ValidatingHandler{Client: &test.MockClient{
+				MockList: func(_ context.Context, obj runtime.Object, _ ...client.ListOption) error {
+					obj.(*v1alpha2.ApplicationConfigurationList).Items = appConfigs
+					return nil
+				},
+			}}
+			handler.InjectDecoder(decoder)
+			comp := component.DeepCopy()
+			comp.SetAnnotations(tc.annotations)
+			req := admission.Request{
+				AdmissionRequest: admissionv1beta1.AdmissionRequest{
+					Operation: admissionv1beta1.Delete,
+					Resource:  reqResource,
+					OldObject: runtime.RawExtension{Raw: util.JSONMarshal(comp)},
+				},
+			}
+			resp := handler.Handle(context.TODO(), req)
+			Expect(resp.Allowed).Should(Equal(tc.pass))
+			if !tc.pass {
+				Expect(string(resp.Result.Reason)).Should(Equal(fmt.Sprintf(
+					"component %q is still used by ApplicationConfiguration by-name, by-revision, remove it from them "+
+						"or set annotation %q to \"true\" to force the deletion", componentName, oam.AnnotationForceDelete)))
+			}
+		}
+	})
 })
@@ -21,12 +21,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"reflect"
 
-	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/discoverymapper"
-
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -36,6 +36,7 @@ import (
 
 	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
 	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/discoverymapper"
 	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
 )
 
@@ -67,11 +68,17 @@ func (h *MutatingHandler) Handle(ctx context.Context, req admission.Request) adm
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 	// mutate the object
-	if err := h.Mutate(obj); err != nil {
+	if err := h.mutate(ctx, req.Namespace, obj); err != nil {
 		mutatelog.Error(err, "failed to mutate the component", "name", obj.Name)
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 	mutatelog.Info("Print the mutated obj", "obj name", obj.Name, "mutated obj", string(obj.Spec.Workload.Raw))
+	// record who changed the spec, so that the revision it results in can
+	// tell; this is done after the mutation so the annotation isn't copied
+	// to the workload
+	if author := req.UserInfo.Username; author != "" && h.specChanged(req, obj) {
+		meta.AddAnnotations(obj, map[string]string{oam.AnnotationAuthor: author})
+	}
 
 	marshalled, err := json.Marshal(obj)
 	if err != nil {
@@ -86,8 +93,41 @@ func (h *MutatingHandler) Handle(ctx context.Context, req admission.Request) adm
 	return resp
 }
 
+// specChanged tells whether the request creates the Component or changes its
+// spec.
+func (h *MutatingHandler) specChanged(req admission.Request, obj *v1alpha2.Component) bool {
+	if req.Operation != admissionv1beta1.Update {
+		return true
+	}
+	old := &v1alpha2.Component{}
+	if err := h.Decoder.DecodeRaw(req.OldObject, old); err != nil {
+		return true
+	}
+	return !reflect.DeepEqual(decoded(old.Spec), decoded(obj.Spec))
+}
+
+// decoded returns the spec of a Component decoded from JSON, so that specs are
+// compared regardless of how their raw workloads are formatted.
+func decoded(spec v1alpha2.ComponentSpec) interface{} {
+	var v interface{}
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return spec
+	}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return spec
+	}
+	return v
+}
+
 // Mutate sets all the default value for the Component
 func (h *MutatingHandler) Mutate(obj *v1alpha2.Component) error {
+	return h.mutate(context.TODO(), obj.GetNamespace(), obj)
+}
+
+// mutate sets all the default value for the Component of the namespace, whose
+// definitions shadow the cluster scoped ones
+func (h *MutatingHandler) mutate(ctx context.Context, namespace string, obj *v1alpha2.Component) error {
 	mutatelog.Info("mutate", "name", obj.Name)
 	var content map[string]interface{}
 	if err := json.Unmarshal(obj.Spec.Workload.Raw, &content); err != nil {
@@ -99,12 +139,12 @@ func (h *MutatingHandler) Mutate(obj *v1alpha2.Component) error {
 			return fmt.Errorf("workload content has an unknown type field")
 		}
 		mutatelog.Info("the component refers to workoadDefinition by type", "name", obj.Name, "workload type", workloadType)
-		// Fetch the corresponding workloadDefinition CR, the workloadDefinition crd is cluster scoped
-		workloadDefinition := &v1alpha2.WorkloadDefinition{}
-		if err := h.Client.Get(context.TODO(), types.NamespacedName{Name: workloadType}, workloadDefinition); err != nil {
+		// Fetch the corresponding workloadDefinition CR, a namespaced one shadows the cluster scoped one
+		workloadDefinition, err := util.GetWorkloadDefinition(ctx, h.Client, namespace, workloadType)
+		if err != nil {
 			return err
 		}
-		gvk, err := util.GetGVKFromDefinition(h.Mapper, workloadDefinition.Spec.Reference)
+		gvk, err := util.GetGVKFromDefinition(ctx, h.Client, h.Mapper, workloadDefinition.Spec.Reference)
 		if err != nil {
 			return err
 		}
@@ -20,29 +20,39 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
 	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/webhook/audit"
+)
+
+const (
+	errFmtComponentInUse = "component %q is still used by ApplicationConfiguration %s, remove it from them or set annotation %q to \"true\" to force the deletion"
 )
 
 // ValidatingHandler handles Component
 type ValidatingHandler struct {
-	// To use the client, you need to do the following:
-	// - uncomment it
-	// - import sigs.k8s.io/controller-runtime/pkg/client
-	// - uncomment the InjectClient method at the bottom of this file.
-	// Client  client.Client
+	Client client.Client
 
 	// Decoder decodes objects
 	Decoder *admission.Decoder
+
+	// Auditor records admitted changes, nil disables auditing
+	Auditor *audit.Auditor
 }
 
 // log is for logging in this package.
@@ -54,6 +64,18 @@ var _ admission.Handler = &ValidatingHandler{}
 func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
 	obj := &v1alpha2.Component{}
 
+	if req.AdmissionRequest.Operation == admissionv1beta1.Delete {
+		if err := h.Decoder.DecodeRaw(req.OldObject, obj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if err := ValidateComponentDeletion(ctx, h.Client, obj); err != nil {
+			validatelog.Info("delete failed", "name", obj.Name, "errMsg", err.Error())
+			return admission.Denied(err.Error())
+		}
+		h.Auditor.Audit(ctx, req)
+		return admission.Allowed("")
+	}
+
 	err := h.Decoder.Decode(req, obj)
 	if err != nil {
 		validatelog.Error(err, "decoder failed", "req operation", req.AdmissionRequest.Operation, "req",
@@ -74,6 +96,7 @@ func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) a
 		}
 	}
 
+	h.Auditor.Audit(ctx, req)
 	return admission.Allowed("")
 }
 
@@ -103,7 +126,36 @@ func ValidateComponentObject(obj *v1alpha2.Component) field.ErrorList {
 	return allErrs
 }
 
-/*
+// ValidateComponentDeletion refuses to delete a Component that is still
+// referenced by ApplicationConfigurations, unless the deletion is forced.
+func ValidateComponentDeletion(ctx context.Context, c client.Reader, obj *v1alpha2.Component) error {
+	if obj.GetAnnotations()[oam.AnnotationForceDelete] == "true" {
+		return nil
+	}
+	acs := &v1alpha2.ApplicationConfigurationList{}
+	if err := c.List(ctx, acs, client.InNamespace(obj.Namespace)); err != nil {
+		return err
+	}
+	var users []string
+	for _, ac := range acs.Items {
+		// AppConfigs being deleted, e.g. along with their namespace, must not
+		// keep their Components from being deleted too
+		if ac.GetDeletionTimestamp() != nil {
+			continue
+		}
+		for _, acc := range ac.Spec.Components {
+			if util.ComponentNameOf(acc) == obj.Name {
+				users = append(users, ac.Name)
+				break
+			}
+		}
+	}
+	if len(users) == 0 {
+		return nil
+	}
+	return fmt.Errorf(errFmtComponentInUse, obj.Name, strings.Join(users, ", "), oam.AnnotationForceDelete)
+}
+
 var _ inject.Client = &ValidatingHandler{}
 
 // InjectClient injects the client into the ComponentValidatingHandler
@@ -111,7 +163,7 @@ func (h *ValidatingHandler) InjectClient(c client.Client) error {
 	h.Client = c
 	return nil
 }
-*/
+
 var _ admission.DecoderInjector = &ValidatingHandler{}
 
 // InjectDecoder injects the decoder into the ComponentValidatingHandler
@@ -121,7 +173,7 @@ func (h *ValidatingHandler) InjectDecoder(d *admission.Decoder) error {
 }
 
 // RegisterValidatingHandler will regsiter component mutation handler to the webhook
-func RegisterValidatingHandler(mgr manager.Manager) {
+func RegisterValidatingHandler(mgr manager.Manager, auditor *audit.Auditor) {
 	server := mgr.GetWebhookServer()
-	server.Register("/validating-core-oam-dev-v1alpha2-components", &webhook.Admission{Handler: &ValidatingHandler{}})
+	server.Register("/validating-core-oam-dev-v1alpha2-components", &webhook.Admission{Handler: &ValidatingHandler{Auditor: auditor}})
 }
@@ -0,0 +1,107 @@
+package definition
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/discoverymapper"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
+)
+
+const (
+	errFmtNoResource     = "no CustomResourceDefinition or API resource named %q exists"
+	errFmtPathNotInField = "field %q does not exist in the schema of %q"
+	errFmtPathNotObject  = "field %q of %q is not an object"
+	errFmtPathNotArray   = "field %q of %q is not an array"
+)
+
+// fetchSchema returns the OpenAPI schema of the resource a definition refers
+// to. The schema is nil when the referenced resource exists but publishes no
+// schema, e.g. it's a built-in resource like deployments.apps rather than a
+// CRD, or it's a CRD without structural schema.
+func fetchSchema(ctx context.Context, c client.Reader, dm discoverymapper.DiscoveryMapper,
+	ref v1alpha2.DefinitionReference, fldPath *field.Path) (*crdv1.JSONSchemaProps, *field.Error) {
+	crd := &crdv1.CustomResourceDefinition{}
+	err := c.Get(ctx, types.NamespacedName{Name: ref.Name}, crd)
+	if kerrors.IsNotFound(err) {
+		if _, err := util.GetGVKFromDefinition(ctx, c, dm, ref); err != nil {
+			if meta.IsNoMatchError(err) {
+				return nil, field.Invalid(fldPath.Child("name"), ref.Name, fmt.Sprintf(errFmtNoResource, ref.Name))
+			}
+			return nil, field.InternalError(fldPath.Child("name"), err)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, field.InternalError(fldPath.Child("name"), err)
+	}
+
+	version, err := util.GetCRDVersion(crd, ref.Version)
+	if err != nil {
+		return nil, field.Invalid(fldPath.Child("version"), ref.Version, err.Error())
+	}
+	for _, v := range crd.Spec.Versions {
+		if v.Name == version && v.Schema != nil {
+			return v.Schema.OpenAPIV3Schema, nil
+		}
+	}
+	return nil, nil
+}
+
+// validateFieldPath checks that path, a field path like spec.template.spec,
+// could exist in objects that conform to schema. Parts of the schema that
+// preserve unknown fields accept any path below them.
+func validateFieldPath(schema *crdv1.JSONSchemaProps, path string, resource string) error {
+	segments, err := fieldpath.Parse(path)
+	if err != nil {
+		return err
+	}
+	current := schema
+	for i, s := range segments {
+		if current == nil || preservesUnknownFields(current) {
+			return nil
+		}
+		switch s.Type {
+		case fieldpath.SegmentField:
+			if current.Type != "" && current.Type != "object" {
+				return fmt.Errorf(errFmtPathNotObject, segments[:i].String(), resource)
+			}
+			if next, ok := current.Properties[s.Field]; ok {
+				current = &next
+				continue
+			}
+			if current.AdditionalProperties != nil && current.AdditionalProperties.Allows {
+				current = current.AdditionalProperties.Schema
+				continue
+			}
+			// an object without declared properties, e.g. metadata, places
+			// no constraint on its fields
+			if len(current.Properties) == 0 && current.AdditionalProperties == nil {
+				return nil
+			}
+			return fmt.Errorf(errFmtPathNotInField, segments[:i+1].String(), resource)
+		case fieldpath.SegmentIndex:
+			if current.Type != "" && current.Type != "array" {
+				return fmt.Errorf(errFmtPathNotArray, segments[:i].String(), resource)
+			}
+			if current.Items == nil {
+				return nil
+			}
+			current = current.Items.Schema
+		}
+	}
+	return nil
+}
+
+func preservesUnknownFields(schema *crdv1.JSONSchemaProps) bool {
+	return schema.XPreserveUnknownFields != nil && *schema.XPreserveUnknownFields
+}
@@ -0,0 +1,100 @@
+package definition
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	utilpointer "k8s.io/utils/pointer"
+)
+
+var testSchema = &crdv1.JSONSchemaProps{
+	Type: "object",
+	Properties: map[string]crdv1.JSONSchemaProps{
+		"metadata": {Type: "object"},
+		"spec": {
+			Type: "object",
+			Properties: map[string]crdv1.JSONSchemaProps{
+				"workloadRef": {
+					Type: "object",
+					Properties: map[string]crdv1.JSONSchemaProps{
+						"name": {Type: "string"},
+					},
+				},
+				"workloadRefs": {
+					Type:  "array",
+					Items: &crdv1.JSONSchemaPropsOrArray{Schema: &crdv1.JSONSchemaProps{Type: "object"}},
+				},
+				"template": {
+					Type:                   "object",
+					XPreserveUnknownFields: utilpointer.BoolPtr(true),
+				},
+				"labels": {
+					Type: "object",
+					AdditionalProperties: &crdv1.JSONSchemaPropsOrBool{
+						Allows: true,
+						Schema: &crdv1.JSONSchemaProps{Type: "string"},
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestValidateFieldPath(t *testing.T) {
+	cases := map[string]struct {
+		schema *crdv1.JSONSchemaProps
+		path   string
+		want   error
+	}{
+		"NoSchema": {
+			schema: nil,
+			path:   "spec.anything",
+		},
+		"ObjectField": {
+			schema: testSchema,
+			path:   "spec.workloadRef",
+		},
+		"ArrayField": {
+			schema: testSchema,
+			path:   "spec.workloadRefs",
+		},
+		"ArrayElement": {
+			schema: testSchema,
+			path:   "spec.workloadRefs[0].name",
+		},
+		"PreserveUnknownFields": {
+			schema: testSchema,
+			path:   "spec.template.spec",
+		},
+		"AdditionalProperties": {
+			schema: testSchema,
+			path:   "spec.labels.app",
+		},
+		"UndeclaredProperties": {
+			schema: testSchema,
+			path:   "metadata.annotations",
+		},
+		"MissingField": {
+			schema: testSchema,
+			path:   "spec.podSpec",
+			want:   fmt.Errorf(errFmtPathNotInField, "spec.podSpec", "foo.example.com"),
+		},
+		"NotAnObject": {
+			schema: testSchema,
+			path:   "spec.workloadRef.name.first",
+			want:   fmt.Errorf(errFmtPathNotObject, "spec.workloadRef.name", "foo.example.com"),
+		},
+		"NotAnArray": {
+			schema: testSchema,
+			path:   "spec.workloadRef[0]",
+			want:   fmt.Errorf(errFmtPathNotArray, "spec.workloadRef", "foo.example.com"),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, validateFieldPath(tc.schema, tc.path, "foo.example.com"))
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package definition
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/discoverymapper"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
+)
+
+const (
+	errFmtDefinitionInUse = "%s %q is still used by ApplicationConfiguration %s, remove them or set annotation %q to \"true\" to force the deletion"
+)
+
+var (
+	workloadDefinitionResource = v1alpha2.SchemeGroupVersion.WithResource("workloaddefinitions")
+	traitDefinitionResource    = v1alpha2.SchemeGroupVersion.WithResource("traitdefinitions")
+	scopeDefinitionResource    = v1alpha2.SchemeGroupVersion.WithResource("scopedefinitions")
+
+	namespacedWorkloadDefinitionResource = v1alpha2.SchemeGroupVersion.WithResource("namespacedworkloaddefinitions")
+	namespacedTraitDefinitionResource    = v1alpha2.SchemeGroupVersion.WithResource("namespacedtraitdefinitions")
+	namespacedScopeDefinitionResource    = v1alpha2.SchemeGroupVersion.WithResource("namespacedscopedefinitions")
+)
+
+// ValidatingHandler validates WorkloadDefinitions, TraitDefinitions and
+// ScopeDefinitions, as well as their namespaced counterparts, against the CRDs
+// they refer to.
+type ValidatingHandler struct {
+	Client client.Client
+	Mapper discoverymapper.DiscoveryMapper
+
+	// Decoder decodes objects
+	Decoder *admission.Decoder
+}
+
+var _ admission.Handler = &ValidatingHandler{}
+
+// Handle validates the definition on creation and update, and refuses to
+// delete it while it's in use.
+func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation == admissionv1beta1.Delete {
+		return h.handleDelete(ctx, req)
+	}
+	var allErrs field.ErrorList
+	switch schema.GroupVersionResource(req.Resource) {
+	case workloadDefinitionResource:
+		obj := &v1alpha2.WorkloadDefinition{}
+		if err := h.Decoder.Decode(req, obj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		allErrs = ValidateWorkloadDefinition(ctx, h.Client, h.Mapper, obj)
+	case traitDefinitionResource:
+		obj := &v1alpha2.TraitDefinition{}
+		if err := h.Decoder.Decode(req, obj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		allErrs = ValidateTraitDefinition(ctx, h.Client, h.Mapper, obj)
+	case scopeDefinitionResource:
+		obj := &v1alpha2.ScopeDefinition{}
+		if err := h.Decoder.Decode(req, obj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		allErrs = ValidateScopeDefinition(ctx, h.Client, h.Mapper, obj)
+	case namespacedWorkloadDefinitionResource:
+		obj := &v1alpha2.NamespacedWorkloadDefinition{}
+		if err := h.Decoder.Decode(req, obj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		allErrs = ValidateWorkloadDefinition(ctx, h.Client, h.Mapper,
+			&v1alpha2.WorkloadDefinition{ObjectMeta: obj.ObjectMeta, Spec: obj.Spec})
+	case namespacedTraitDefinitionResource:
+		obj := &v1alpha2.NamespacedTraitDefinition{}
+		if err := h.Decoder.Decode(req, obj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		allErrs = ValidateTraitDefinition(ctx, h.Client, h.Mapper,
+			&v1alpha2.TraitDefinition{ObjectMeta: obj.ObjectMeta, Spec: obj.Spec})
+	case namespacedScopeDefinitionResource:
+		obj := &v1alpha2.NamespacedScopeDefinition{}
+		if err := h.Decoder.Decode(req, obj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		allErrs = ValidateScopeDefinition(ctx, h.Client, h.Mapper,
+			&v1alpha2.ScopeDefinition{ObjectMeta: obj.ObjectMeta, Spec: obj.Spec})
+	default:
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf("unexpected resource %s", req.Resource.String()))
+	}
+	if len(allErrs) > 0 {
+		klog.Info("validation failed ", " name: ", req.Name, " resource: ", req.Resource.Resource,
+			" errMsg: ", allErrs.ToAggregate().Error())
+		return admission.Denied(allErrs.ToAggregate().Error())
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+func (h *ValidatingHandler) handleDelete(ctx context.Context, req admission.Request) admission.Response {
+	var obj DefinitionObject
+	switch schema.GroupVersionResource(req.Resource) {
+	case workloadDefinitionResource:
+		obj = &v1alpha2.WorkloadDefinition{}
+	case traitDefinitionResource:
+		obj = &v1alpha2.TraitDefinition{}
+	case scopeDefinitionResource:
+		obj = &v1alpha2.ScopeDefinition{}
+	case namespacedWorkloadDefinitionResource:
+		obj = &v1alpha2.NamespacedWorkloadDefinition{}
+	case namespacedTraitDefinitionResource:
+		obj = &v1alpha2.NamespacedTraitDefinition{}
+	case namespacedScopeDefinitionResource:
+		obj = &v1alpha2.NamespacedScopeDefinition{}
+	default:
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf("unexpected resource %s", req.Resource.String()))
+	}
+	if err := h.Decoder.DecodeRaw(req.OldObject, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := ValidateDefinitionDeletion(ctx, h.Client, h.Mapper, obj); err != nil {
+		klog.Info("deletion denied ", " name: ", req.Name, " resource: ", req.Resource.Resource, " errMsg: ", err.Error())
+		return admission.Denied(err.Error())
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+// A DefinitionObject is a WorkloadDefinition, TraitDefinition or
+// ScopeDefinition, or one of their namespaced counterparts.
+type DefinitionObject interface {
+	runtime.Object
+	metav1.Object
+	GetDefinitionReference() v1alpha2.DefinitionReference
+}
+
+// ValidateDefinitionDeletion refuses to delete a definition while
+// ApplicationConfigurations still use the workloads, traits or scopes it
+// defines, unless the deletion is forced. Only the ApplicationConfigurations
+// of its namespace can use a namespaced definition.
+func ValidateDefinitionDeletion(ctx context.Context, c client.Reader, dm discoverymapper.DiscoveryMapper,
+	obj DefinitionObject) error {
+	if obj.GetAnnotations()[oam.AnnotationForceDelete] == "true" {
+		return nil
+	}
+	gvk, err := util.GetGVKFromDefinition(ctx, c, dm, obj.GetDefinitionReference())
+	if meta.IsNoMatchError(err) {
+		// nothing can be using a resource that doesn't exist anymore
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	acs := &v1alpha2.ApplicationConfigurationList{}
+	if err := c.List(ctx, acs, client.InNamespace(obj.GetNamespace())); err != nil {
+		return err
+	}
+	var users []string
+	for _, ac := range acs.Items {
+		if ac.GetDeletionTimestamp() == nil && usesKind(obj, ac, gvk.GroupKind()) {
+			users = append(users, ac.Namespace+"/"+ac.Name)
+		}
+	}
+	if len(users) == 0 {
+		return nil
+	}
+	return fmt.Errorf(errFmtDefinitionInUse, kindOf(obj), obj.GetName(), strings.Join(users, ", "), oam.AnnotationForceDelete)
+}
+
+// usesKind tells whether the ApplicationConfiguration has a workload, trait or
+// scope, depending on the kind of definition, of the supplied kind.
+func usesKind(obj DefinitionObject, ac v1alpha2.ApplicationConfiguration, gk schema.GroupKind) bool {
+	matches := func(ref runtimev1alpha1.TypedReference) bool {
+		return schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind).GroupKind() == gk
+	}
+	switch obj.(type) {
+	case *v1alpha2.WorkloadDefinition, *v1alpha2.NamespacedWorkloadDefinition:
+		for _, w := range ac.Status.Workloads {
+			if matches(w.Reference) {
+				return true
+			}
+		}
+	case *v1alpha2.TraitDefinition, *v1alpha2.NamespacedTraitDefinition:
+		for _, w := range ac.Status.Workloads {
+			for _, t := range w.Traits {
+				if matches(t.Reference) {
+					return true
+				}
+			}
+		}
+	case *v1alpha2.ScopeDefinition, *v1alpha2.NamespacedScopeDefinition:
+		for _, acc := range ac.Spec.Components {
+			for _, s := range acc.Scopes {
+				if matches(s.ScopeReference) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func kindOf(obj DefinitionObject) string {
+	switch obj.(type) {
+	case *v1alpha2.WorkloadDefinition:
+		return v1alpha2.WorkloadDefinitionKind
+	case *v1alpha2.TraitDefinition:
+		return v1alpha2.TraitDefinitionKind
+	case *v1alpha2.NamespacedWorkloadDefinition:
+		return v1alpha2.NamespacedWorkloadDefinitionKind
+	case *v1alpha2.NamespacedTraitDefinition:
+		return v1alpha2.NamespacedTraitDefinitionKind
+	case *v1alpha2.NamespacedScopeDefinition:
+		return v1alpha2.NamespacedScopeDefinitionKind
+	default:
+		return v1alpha2.ScopeDefinitionKind
+	}
+}
+
+// ValidateWorkloadDefinition checks that the definitionRef of a
+// WorkloadDefinition refers to an existing resource, and that its podSpecPath
+// exists in the schema of that resource.
+func ValidateWorkloadDefinition(ctx context.Context, c client.Reader, dm discoverymapper.DiscoveryMapper,
+	wd *v1alpha2.WorkloadDefinition) field.ErrorList {
+	fldPath := field.NewPath("spec")
+	return validateDefinition(ctx, c, dm, wd.Spec.Reference, fldPath.Child("definitionRef"),
+		wd.Spec.PodSpecPath, fldPath.Child("podSpecPath"))
+}
+
+// ValidateTraitDefinition checks that the definitionRef of a TraitDefinition
+// refers to an existing resource, and that its workloadRefPath exists in the
+// schema of that resource.
+func ValidateTraitDefinition(ctx context.Context, c client.Reader, dm discoverymapper.DiscoveryMapper,
+	td *v1alpha2.TraitDefinition) field.ErrorList {
+	fldPath := field.NewPath("spec")
+	return validateDefinition(ctx, c, dm, td.Spec.Reference, fldPath.Child("definitionRef"),
+		td.Spec.WorkloadRefPath, fldPath.Child("workloadRefPath"))
+}
+
+// ValidateScopeDefinition checks that the definitionRef of a ScopeDefinition
+// refers to an existing resource, and that its workloadRefsPath exists in the
+// schema of that resource.
+func ValidateScopeDefinition(ctx context.Context, c client.Reader, dm discoverymapper.DiscoveryMapper,
+	sd *v1alpha2.ScopeDefinition) field.ErrorList {
+	fldPath := field.NewPath("spec")
+	return validateDefinition(ctx, c, dm, sd.Spec.Reference, fldPath.Child("definitionRef"),
+		sd.Spec.WorkloadRefsPath, fldPath.Child("workloadRefsPath"))
+}
+
+func validateDefinition(ctx context.Context, c client.Reader, dm discoverymapper.DiscoveryMapper,
+	ref v1alpha2.DefinitionReference, refPath *field.Path, path string, pathPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if ref.Name == "" {
+		return append(allErrs, field.Required(refPath.Child("name"), ""))
+	}
+	s, ferr := fetchSchema(ctx, c, dm, ref, refPath)
+	if ferr != nil {
+		return append(allErrs, ferr)
+	}
+	if path == "" {
+		return allErrs
+	}
+	if err := validateFieldPath(s, path, ref.Name); err != nil {
+		allErrs = append(allErrs, field.Invalid(pathPath, path, err.Error()))
+	}
+	return allErrs
+}
+
+var _ inject.Client = &ValidatingHandler{}
+
+// InjectClient injects the client into the ValidatingHandler
+func (h *ValidatingHandler) InjectClient(c client.Client) error {
+	h.Client = c
+	return nil
+}
+
+var _ admission.DecoderInjector = &ValidatingHandler{}
+
+// InjectDecoder injects the decoder into the ValidatingHandler
+func (h *ValidatingHandler) InjectDecoder(d *admission.Decoder) error {
+	h.Decoder = d
+	return nil
+}
+
+// RegisterValidatingHandler will register workload, trait and scope definition
+// validation to webhook
+func RegisterValidatingHandler(mgr manager.Manager) error {
+	server := mgr.GetWebhookServer()
+	mapper, err := discoverymapper.New(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	server.Register("/validating-core-oam-dev-v1alpha2-workloaddefinitions",
+		&webhook.Admission{Handler: &ValidatingHandler{Mapper: mapper}})
+	server.Register("/validating-core-oam-dev-v1alpha2-traitdefinitions",
+		&webhook.Admission{Handler: &ValidatingHandler{Mapper: mapper}})
+	server.Register("/validating-core-oam-dev-v1alpha2-scopedefinitions",
+		&webhook.Admission{Handler: &ValidatingHandler{Mapper: mapper}})
+	server.Register("/validating-core-oam-dev-v1alpha2-namespaceddefinitions",
+		&webhook.Admission{Handler: &ValidatingHandler{Mapper: mapper}})
+	return nil
+}
@@ -0,0 +1,300 @@
+package definition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/stretchr/testify/assert"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/mock"
+)
+
+var ctx = context.Background()
+
+func crdGetter(crd *crdv1.CustomResourceDefinition) test.MockGetFn {
+	return func(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+		if crd == nil || key.Name != crd.Name {
+			return kerrors.NewNotFound(schema.GroupResource{Resource: "customresourcedefinitions"}, key.Name)
+		}
+		crd.DeepCopyInto(obj.(*crdv1.CustomResourceDefinition))
+		return nil
+	}
+}
+
+func TestValidateTraitDefinition(t *testing.T) {
+	crd := &crdv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "foos.example.com"},
+		Spec: crdv1.CustomResourceDefinitionSpec{
+			Versions: []crdv1.CustomResourceDefinitionVersion{
+				{
+					Name:    "v1alpha1",
+					Served:  false,
+					Storage: false,
+				},
+				{
+					Name:    "v1beta1",
+					Served:  true,
+					Storage: true,
+					Schema:  &crdv1.CustomResourceValidation{OpenAPIV3Schema: testSchema},
+				},
+			},
+		},
+	}
+	noMatch := func(input schema.GroupVersionResource) ([]schema.GroupVersionKind, error) {
+		return nil, &meta.NoResourceMatchError{PartialResource: input}
+	}
+	fldPath := field.NewPath("spec")
+
+	cases := map[string]struct {
+		td       v1alpha2.TraitDefinitionSpec
+		kindsFor mock.KindsFor
+		want     field.ErrorList
+	}{
+		"Valid": {
+			td: v1alpha2.TraitDefinitionSpec{
+				Reference:       v1alpha2.DefinitionReference{Name: "foos.example.com"},
+				WorkloadRefPath: "spec.workloadRef",
+			},
+		},
+		"MissingName": {
+			td: v1alpha2.TraitDefinitionSpec{},
+			want: field.ErrorList{
+				field.Required(fldPath.Child("definitionRef", "name"), ""),
+			},
+		},
+		"NoSuchResource": {
+			td: v1alpha2.TraitDefinitionSpec{
+				Reference: v1alpha2.DefinitionReference{Name: "bars.example.com"},
+			},
+			kindsFor: noMatch,
+			want: field.ErrorList{
+				field.Invalid(fldPath.Child("definitionRef", "name"), "bars.example.com",
+					fmt.Sprintf(errFmtNoResource, "bars.example.com")),
+			},
+		},
+		"BuiltInResource": {
+			td: v1alpha2.TraitDefinitionSpec{
+				Reference:       v1alpha2.DefinitionReference{Name: "services"},
+				WorkloadRefPath: "spec.workloadRef",
+			},
+			kindsFor: mock.NewMockKindsFor("Service", "v1"),
+		},
+		"VersionNotServed": {
+			td: v1alpha2.TraitDefinitionSpec{
+				Reference: v1alpha2.DefinitionReference{Name: "foos.example.com", Version: "v1alpha1"},
+			},
+			want: field.ErrorList{
+				field.Invalid(fldPath.Child("definitionRef", "version"), "v1alpha1",
+					`version "v1alpha1" is not served by CustomResourceDefinition "foos.example.com"`),
+			},
+		},
+		"WorkloadRefPathNotInSchema": {
+			td: v1alpha2.TraitDefinitionSpec{
+				Reference:       v1alpha2.DefinitionReference{Name: "foos.example.com", Version: "v1beta1"},
+				WorkloadRefPath: "spec.workload",
+			},
+			want: field.ErrorList{
+				field.Invalid(fldPath.Child("workloadRefPath"), "spec.workload",
+					fmt.Sprintf(errFmtPathNotInField, "spec.workload", "foos.example.com")),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := &test.MockClient{MockGet: crdGetter(crd)}
+			dm := &mock.DiscoveryMapper{MockKindsFor: tc.kindsFor}
+			got := ValidateTraitDefinition(ctx, c, dm, &v1alpha2.TraitDefinition{Spec: tc.td})
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestValidateWorkloadAndScopeDefinition(t *testing.T) {
+	crd := &crdv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "foos.example.com"},
+		Spec: crdv1.CustomResourceDefinitionSpec{
+			Versions: []crdv1.CustomResourceDefinitionVersion{{
+				Name:    "v1",
+				Served:  true,
+				Storage: true,
+				Schema:  &crdv1.CustomResourceValidation{OpenAPIV3Schema: testSchema},
+			}},
+		},
+	}
+	c := &test.MockClient{MockGet: crdGetter(crd)}
+	dm := mock.NewMockDiscoveryMapper()
+	ref := v1alpha2.DefinitionReference{Name: "foos.example.com"}
+
+	wd := &v1alpha2.WorkloadDefinition{Spec: v1alpha2.WorkloadDefinitionSpec{Reference: ref, PodSpecPath: "spec.template.spec"}}
+	assert.Empty(t, ValidateWorkloadDefinition(ctx, c, dm, wd))
+	wd.Spec.PodSpecPath = "spec.podSpec"
+	assert.Equal(t, field.ErrorList{
+		field.Invalid(field.NewPath("spec", "podSpecPath"), "spec.podSpec",
+			fmt.Sprintf(errFmtPathNotInField, "spec.podSpec", "foos.example.com")),
+	}, ValidateWorkloadDefinition(ctx, c, dm, wd))
+
+	sd := &v1alpha2.ScopeDefinition{Spec: v1alpha2.ScopeDefinitionSpec{Reference: ref, WorkloadRefsPath: "spec.workloadRefs"}}
+	assert.Empty(t, ValidateScopeDefinition(ctx, c, dm, sd))
+	sd.Spec.WorkloadRefsPath = "spec.refs"
+	assert.Equal(t, field.ErrorList{
+		field.Invalid(field.NewPath("spec", "workloadRefsPath"), "spec.refs",
+			fmt.Sprintf(errFmtPathNotInField, "spec.refs", "foos.example.com")),
+	}, ValidateScopeDefinition(ctx, c, dm, sd))
+}
+
+func TestValidateDefinitionDeletion(t *testing.T) {
+	ref := func(apiVersion, kind string) runtimev1alpha1.TypedReference {
+		return runtimev1alpha1.TypedReference{APIVersion: apiVersion, Kind: kind, Name: "n"}
+	}
+	acs := []v1alpha2.ApplicationConfiguration{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "with-foo-trait"},
+			Status: v1alpha2.ApplicationConfigurationStatus{Workloads: []v1alpha2.WorkloadStatus{{
+				Reference: ref("example.com/v1", "Bar"),
+				Traits:    []v1alpha2.WorkloadTrait{{Reference: ref("example.com/v1", "Foo")}},
+			}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "with-foo-scope"},
+			Spec: v1alpha2.ApplicationConfigurationSpec{Components: []v1alpha2.ApplicationConfigurationComponent{{
+				Scopes: []v1alpha2.ComponentScope{{ScopeReference: ref("example.com/v1", "Foo")}},
+			}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "with-foo-workload"},
+			Status: v1alpha2.ApplicationConfigurationStatus{Workloads: []v1alpha2.WorkloadStatus{{
+				Reference: ref("example.com/v1", "Foo"),
+			}}},
+		},
+	}
+	c := &test.MockClient{
+		MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{Resource: "customresourcedefinitions"}, "")),
+		MockList: func(_ context.Context, obj runtime.Object, opts ...client.ListOption) error {
+			lo := &client.ListOptions{}
+			lo.ApplyOptions(opts)
+			l := obj.(*v1alpha2.ApplicationConfigurationList)
+			for _, ac := range acs {
+				if lo.Namespace == "" || lo.Namespace == ac.Namespace {
+					l.Items = append(l.Items, ac)
+				}
+			}
+			return nil
+		},
+	}
+	dm := mock.NewMockDiscoveryMapper()
+	dm.MockKindsFor = mock.NewMockKindsFor("Foo", "v1")
+	def := v1alpha2.DefinitionReference{Name: "foos.example.com"}
+	force := map[string]string{oam.AnnotationForceDelete: "true"}
+
+	cases := map[string]struct {
+		obj  DefinitionObject
+		want error
+	}{
+		"TraitInUse": {
+			obj: &v1alpha2.TraitDefinition{ObjectMeta: metav1.ObjectMeta{Name: "foos.example.com"},
+				Spec: v1alpha2.TraitDefinitionSpec{Reference: def}},
+			want: fmt.Errorf(errFmtDefinitionInUse, v1alpha2.TraitDefinitionKind, "foos.example.com",
+				"ns/with-foo-trait", oam.AnnotationForceDelete),
+		},
+		"ScopeInUse": {
+			obj: &v1alpha2.ScopeDefinition{ObjectMeta: metav1.ObjectMeta{Name: "foos.example.com"},
+				Spec: v1alpha2.ScopeDefinitionSpec{Reference: def}},
+			want: fmt.Errorf(errFmtDefinitionInUse, v1alpha2.ScopeDefinitionKind, "foos.example.com",
+				"ns/with-foo-scope", oam.AnnotationForceDelete),
+		},
+		"WorkloadInUse": {
+			obj: &v1alpha2.WorkloadDefinition{ObjectMeta: metav1.ObjectMeta{Name: "foos.example.com"},
+				Spec: v1alpha2.WorkloadDefinitionSpec{Reference: def}},
+			want: fmt.Errorf(errFmtDefinitionInUse, v1alpha2.WorkloadDefinitionKind, "foos.example.com",
+				"other/with-foo-workload", oam.AnnotationForceDelete),
+		},
+		"NamespacedTraitInUse": {
+			obj: &v1alpha2.NamespacedTraitDefinition{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foos.example.com"},
+				Spec: v1alpha2.TraitDefinitionSpec{Reference: def}},
+			want: fmt.Errorf(errFmtDefinitionInUse, v1alpha2.NamespacedTraitDefinitionKind, "foos.example.com",
+				"ns/with-foo-trait", oam.AnnotationForceDelete),
+		},
+		"NamespacedScopeInUse": {
+			obj: &v1alpha2.NamespacedScopeDefinition{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foos.example.com"},
+				Spec: v1alpha2.ScopeDefinitionSpec{Reference: def}},
+			want: fmt.Errorf(errFmtDefinitionInUse, v1alpha2.NamespacedScopeDefinitionKind, "foos.example.com",
+				"ns/with-foo-scope", oam.AnnotationForceDelete),
+		},
+		"NamespacedWorkloadNotInUse": {
+			obj: &v1alpha2.NamespacedWorkloadDefinition{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foos.example.com"},
+				Spec: v1alpha2.WorkloadDefinitionSpec{Reference: def}},
+		},
+		"Forced": {
+			obj: &v1alpha2.TraitDefinition{ObjectMeta: metav1.ObjectMeta{Name: "foos.example.com", Annotations: force},
+				Spec: v1alpha2.TraitDefinitionSpec{Reference: def}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ValidateDefinitionDeletion(ctx, c, dm, tc.obj))
+		})
+	}
+}
+
+func TestHandleNamespacedDefinitionDeletion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha2.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dm := mock.NewMockDiscoveryMapper()
+	dm.MockKindsFor = mock.NewMockKindsFor("Foo", "v1")
+	h := &ValidatingHandler{
+		Client: &test.MockClient{
+			MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{Resource: "customresourcedefinitions"}, "")),
+			MockList: func(_ context.Context, obj runtime.Object, _ ...client.ListOption) error {
+				obj.(*v1alpha2.ApplicationConfigurationList).Items = []v1alpha2.ApplicationConfiguration{{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "with-foo-workload"},
+					Status: v1alpha2.ApplicationConfigurationStatus{Workloads: []v1alpha2.WorkloadStatus{{
+						Reference: runtimev1alpha1.TypedReference{APIVersion: "example.com/v1", Kind: "Foo", Name: "n"},
+					}}},
+				}}
+				return nil
+			},
+		},
+		Mapper:  dm,
+		Decoder: decoder,
+	}
+	wd := &v1alpha2.NamespacedWorkloadDefinition{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foos.example.com"},
+		Spec:       v1alpha2.WorkloadDefinitionSpec{Reference: v1alpha2.DefinitionReference{Name: "foos.example.com"}},
+	}
+	raw, err := json.Marshal(wd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := h.Handle(ctx, admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+		Operation: admissionv1beta1.Delete,
+		Resource:  metav1.GroupVersionResource(namespacedWorkloadDefinitionResource),
+		Namespace: "ns",
+		Name:      wd.Name,
+		OldObject: runtime.RawExtension{Raw: raw},
+	}})
+	assert.False(t, resp.Allowed)
+	assert.Equal(t, fmt.Sprintf(errFmtDefinitionInUse, v1alpha2.NamespacedWorkloadDefinitionKind, wd.Name,
+		"ns/with-foo-workload", oam.AnnotationForceDelete), string(resp.Result.Reason))
+}
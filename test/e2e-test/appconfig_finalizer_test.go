@@ -155,7 +155,7 @@ var _ = Describe("Finalizer for HealthScope in ApplicationConfiguration", func()
 					AllowComponentOverlap: true,
 					WorkloadRefsPath:      "spec.workloadRefs",
 					Reference: v1alpha2.DefinitionReference{
-						Name: "healthscope.core.oam.dev",
+						Name: "healthscopes.core.oam.dev",
 					},
 				},
 			}
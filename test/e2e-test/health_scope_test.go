@@ -69,13 +69,13 @@ var _ = Describe("HealthScope", func() {
 		// create health scope definition
 		sd := v1alpha2.ScopeDefinition{
 			ObjectMeta: metav1.ObjectMeta{
-				Name: "healthscope.core.oam.dev",
+				Name: "healthscopes.core.oam.dev",
 			},
 			Spec: v1alpha2.ScopeDefinitionSpec{
 				AllowComponentOverlap: true,
 				WorkloadRefsPath:      "spec.workloadRefs",
 				Reference: v1alpha2.DefinitionReference{
-					Name: "healthscope.core.oam.dev",
+					Name: "healthscopes.core.oam.dev",
 				},
 			},
 		}